@@ -1,9 +1,13 @@
 package account
 
 import (
+	"database/sql/driver"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"strings"
+
+	"github.com/ConsenSys/quorum-go-utils/types"
 )
 
 const addressLength = 20
@@ -32,6 +36,19 @@ func NewAddressFromHexString(addr string) (Address, error) {
 	return NewAddress(byt)
 }
 
+// NewAddressFromChecksumHexString creates a new Address from the provided hex string-representation, as with NewAddressFromHexString.
+// If the hexAddr is mixed-case, it must also have the EIP-1191 checksum for the chainID, or the EIP-55 checksum if chainID is nil.
+func NewAddressFromChecksumHexString(addr string, chainID *big.Int) (Address, error) {
+	a, err := NewAddressFromHexString(addr)
+	if err != nil {
+		return Address{}, err
+	}
+	if _, err := types.ParseChecksumAddress(addr, chainID); err != nil {
+		return Address{}, err
+	}
+	return a, nil
+}
+
 // ToBytes returns the underlying bytes of the Address
 func (a Address) ToBytes() []byte {
 	return a[:]
@@ -41,3 +58,69 @@ func (a Address) ToBytes() []byte {
 func (a Address) ToHexString() string {
 	return hex.EncodeToString(a[:])
 }
+
+// ToChecksumHexString encodes the Address as a mixed-case hex string without the '0x' prefix, where the case is the EIP-1191
+// checksum for the chainID, or the EIP-55 checksum if chainID is nil
+func (a Address) ToChecksumHexString(chainID *big.Int) string {
+	addr := a.ToTypes()
+	return strings.TrimPrefix(addr.Checksum(chainID), "0x")
+}
+
+// NewAddressFromTypes creates a new Address from a types.Address, which must hold exactly 20 bytes of hex.
+func NewAddressFromTypes(addr types.Address) (Address, error) {
+	return NewAddressFromHexString(string(addr))
+}
+
+// ToTypes converts the Address to a types.Address
+func (a Address) ToTypes() types.Address {
+	return types.Address(a.ToHexString())
+}
+
+// MarshalText encodes the Address as a hex string with the '0x' prefix.  It is also used for JSON and TOML.
+func (a Address) MarshalText() ([]byte, error) {
+	return a.ToTypes().MarshalText()
+}
+
+// UnmarshalText parses the Address in the same way as types.Address, so the checksum of mixed-case input isn't checked,
+// see types.ChecksumAddress.  It is also used for JSON and TOML.
+func (a *Address) UnmarshalText(input []byte) error {
+	var addr types.Address
+	if err := addr.UnmarshalText(input); err != nil {
+		return err
+	}
+	parsed, err := NewAddressFromTypes(addr)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Scan reads the Address from a database column, which can hold its hex, with or without the '0x' prefix, or its
+// 20 bytes.  NULL gives the zero Address.
+func (a *Address) Scan(src interface{}) error {
+	var addr types.Address
+	if err := addr.Scan(src); err != nil {
+		return err
+	}
+	if addr == "" {
+		*a = Address{}
+		return nil
+	}
+	parsed, err := NewAddressFromTypes(addr)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value gives the Address to store in a database column, as a hex string with the '0x' prefix
+func (a Address) Value() (driver.Value, error) {
+	return a.ToTypes().Value()
+}
+
+// Format formats the Address for fmt in the same way as types.Address
+func (a Address) Format(s fmt.State, verb rune) {
+	a.ToTypes().Format(s, verb)
+}
@@ -1,9 +1,15 @@
 package account
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"math/rand"
 	"testing"
 
+	"github.com/ConsenSys/quorum-go-utils/types"
+	"github.com/naoina/toml"
 	"github.com/stretchr/testify/require"
 )
 
@@ -71,3 +77,121 @@ func TestAddress_ToHexString(t *testing.T) {
 	got := addr.ToHexString()
 	require.Equal(t, want, got)
 }
+
+func TestAddress_ToChecksumHexString(t *testing.T) {
+	addr, err := NewAddressFromHexString("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+	require.NoError(t, err)
+
+	require.Equal(t, "fB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", addr.ToChecksumHexString(nil))
+	require.Equal(t, "Fb6916095cA1Df60bb79ce92cE3EA74c37c5d359", addr.ToChecksumHexString(big.NewInt(30)))
+}
+
+func TestNewAddressFromChecksumHexString(t *testing.T) {
+	want, err := NewAddressFromHexString("fb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+	require.NoError(t, err)
+
+	got, err := NewAddressFromChecksumHexString("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", nil)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	got, err = NewAddressFromChecksumHexString("Fb6916095cA1Df60bb79ce92cE3EA74c37c5d359", big.NewInt(30))
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	got, err = NewAddressFromChecksumHexString("fb6916095ca1df60bb79ce92ce3ea74c37c5d359", big.NewInt(30))
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestNewAddressFromChecksumHexString_InvalidChecksum(t *testing.T) {
+	_, err := NewAddressFromChecksumHexString("0xFB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", nil)
+	require.EqualError(t, err, `invalid address "0xFB6916095ca1df60bB79Ce92cE3Ea74c37c5d359": checksum does not match, expected 0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359`)
+
+	_, err = NewAddressFromChecksumHexString("0xda71f0", nil)
+	require.EqualError(t, err, "account address must have length 20 bytes")
+}
+
+func TestAddress_Types(t *testing.T) {
+	addr, err := NewAddressFromHexString("0xda71f07446ed1eca304485dd00c4827ed0984998")
+	require.NoError(t, err)
+
+	converted := addr.ToTypes()
+	require.Equal(t, types.NewAddress("0xda71f07446ed1eca304485dd00c4827ed0984998"), converted)
+
+	got, err := NewAddressFromTypes(converted)
+	require.NoError(t, err)
+	require.Equal(t, addr, got)
+
+	_, err = NewAddressFromTypes(types.Address("da71f0"))
+	require.EqualError(t, err, "account address must have length 20 bytes")
+}
+
+func TestAddress_JSON(t *testing.T) {
+	type config struct {
+		From Address `json:"from"`
+		To   Address `json:"to"`
+	}
+	addr, err := NewAddressFromHexString("0xda71f07446ed1eca304485dd00c4827ed0984998")
+	require.NoError(t, err)
+
+	out, err := json.Marshal(config{From: addr})
+	require.NoError(t, err)
+	require.Equal(t, `{"from":"0xda71f07446ed1eca304485dd00c4827ed0984998","to":"0x0000000000000000000000000000000000000000"}`, string(out))
+
+	var got config
+	err = json.Unmarshal([]byte(`{"from":"0xda71f07446ed1eca304485dd00c4827ed0984998"}`), &got)
+	require.NoError(t, err)
+	require.Equal(t, config{From: addr}, got)
+
+	err = json.Unmarshal([]byte(`{"from":"0xda71f0"}`), &got)
+	require.EqualError(t, err, `invalid address "0xda71f0": expected 20 bytes but got 3`)
+}
+
+func TestAddress_TOML(t *testing.T) {
+	type config struct {
+		From Address `toml:"from"`
+	}
+	addr, err := NewAddressFromHexString("0xda71f07446ed1eca304485dd00c4827ed0984998")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = toml.NewEncoder(&buf).Encode(config{From: addr})
+	require.NoError(t, err)
+	require.Equal(t, "from = \"0xda71f07446ed1eca304485dd00c4827ed0984998\"\n", buf.String())
+
+	var got config
+	err = toml.NewDecoder(&buf).Decode(&got)
+	require.NoError(t, err)
+	require.Equal(t, addr, got.From)
+}
+
+func TestAddress_SQL(t *testing.T) {
+	addr, err := NewAddressFromHexString("0xda71f07446ed1eca304485dd00c4827ed0984998")
+	require.NoError(t, err)
+
+	value, err := addr.Value()
+	require.NoError(t, err)
+	require.Equal(t, "0xda71f07446ed1eca304485dd00c4827ed0984998", value)
+
+	var got Address
+	require.NoError(t, got.Scan(value))
+	require.Equal(t, addr, got)
+
+	require.NoError(t, got.Scan(addr.ToBytes()))
+	require.Equal(t, addr, got)
+
+	require.NoError(t, got.Scan(nil))
+	require.Equal(t, Address{}, got)
+
+	require.EqualError(t, got.Scan(1.5), "cannot scan float64 into an address")
+}
+
+func TestAddress_Format(t *testing.T) {
+	addr, err := NewAddressFromHexString("0xda71f07446ed1eca304485dd00c4827ed0984998")
+	require.NoError(t, err)
+
+	require.Equal(t, "0xda71f07446ed1eca304485dd00c4827ed0984998", fmt.Sprintf("%s", addr))
+	require.Equal(t, "0xda71f07446ed1eca304485dd00c4827ed0984998", fmt.Sprintf("%v", addr))
+	require.Equal(t, "da71f07446ed1eca304485dd00c4827ed0984998", fmt.Sprintf("%x", addr))
+	require.Equal(t, "DA71F07446ED1ECA304485DD00C4827ED0984998", fmt.Sprintf("%X", addr))
+}
@@ -23,8 +23,8 @@ type Transaction struct {
 	Nonce             types.HexNumber
 	From              Address
 	To                Address
-	Value             types.HexNumber
-	GasPrice          types.HexNumber
+	Value             types.HexBig
+	GasPrice          types.HexBig
 	Gas               types.HexNumber
 	GasUsed           types.HexNumber
 	CumulativeGasUsed types.HexNumber
@@ -119,7 +119,7 @@ func (qc *QuorumClient) rpcCall(timeout time.Duration, result interface{}, metho
 		}
 		log.Debug("rpc call response", "response", string(response.Result))
 		if response.Error != nil {
-			return response.Error
+			return response.Error.asError()
 		}
 		if err := json.Unmarshal(response.Result, &result); err != nil {
 			// if response.Result is not a JSON, assign to result directly
@@ -1,13 +1,16 @@
 package client
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/ConsenSys/quorum-go-utils/types"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
@@ -118,3 +121,93 @@ func TestStubQuorumClient(t *testing.T) {
 	err = c.RPCCall(&res, "rpc_nil")
 	assert.EqualError(t, err, "not found", "unexpected error message")
 }
+
+func TestQuorumClient_RPCCall_Reverted(t *testing.T) {
+	// Error(string) with the reason "insufficient balance"
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"696e73756666696369656e742062616c616e6365000000000000000000000000"
+
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			var request message
+			if err := c.ReadJSON(&request); err != nil {
+				break
+			}
+			response := message{
+				Version: "2.0",
+				ID:      request.ID,
+				Error:   &msgError{Code: 3, Message: "execution reverted: insufficient balance", Data: revertData},
+			}
+			if err := c.WriteJSON(response); err != nil {
+				break
+			}
+		}
+	}))
+	defer rpcServer.Close()
+	rpcurl := "ws" + strings.TrimPrefix(rpcServer.URL, "http")
+
+	c, err := NewQuorumClient(rpcurl)
+	assert.NoError(t, err)
+
+	var res types.HexData
+	err = c.RPCCall(&res, "eth_call", types.EIP165Call{}, "latest")
+
+	var rpcErr *RevertError
+	if assert.True(t, errors.As(err, &rpcErr), "expected a RevertError, got %v", err) {
+		assert.Equal(t, 3, rpcErr.Code)
+		assert.EqualError(t, err, "execution reverted: insufficient balance")
+	}
+	var revertErr *types.RevertError
+	if assert.True(t, errors.As(err, &revertErr), "expected a types.RevertError, got %v", err) {
+		assert.Equal(t, "insufficient balance", revertErr.Reason)
+		assert.Equal(t, "Error(string reason)", revertErr.Sig)
+	}
+}
+
+func TestMsgError_AsError(t *testing.T) {
+	plain := &msgError{Code: -32000, Message: "nonce too low"}
+	assert.Equal(t, plain, plain.asError())
+
+	notHex := &msgError{Code: 3, Message: "execution reverted", Data: "0xzz"}
+	assert.Equal(t, notHex, notHex.asError())
+
+	//hex data on an error that isn't a revert is left as it is
+	notReverted := &msgError{Code: -32000, Message: "invalid opcode", Data: "0x4e487b71"}
+	assert.Equal(t, notReverted, notReverted.asError())
+
+	panicked := &msgError{Code: 3, Message: "execution reverted", Data: "0x4e487b71" +
+		"0000000000000000000000000000000000000000000000000000000000000011"}
+	err := panicked.asError()
+	assert.EqualError(t, err, "execution reverted")
+	var revertErr *types.RevertError
+	if assert.True(t, errors.As(err, &revertErr)) {
+		assert.EqualError(t, revertErr, "execution reverted: panic 0x11 (arithmetic underflow or overflow)")
+	}
+
+	//older nodes don't give a code, only the message
+	uncoded := &msgError{Code: -32000, Message: "execution reverted", Data: "0x"}
+	assert.IsType(t, &RevertError{}, uncoded.asError())
+}
+
+func TestRevertError_DecodeWith(t *testing.T) {
+	structure, _ := types.NewABIStructureFromJSON(`[{"type":"error","name":"InsufficientBalance","inputs":[
+		{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`)
+	custom := &msgError{Code: 3, Message: "execution reverted", Data: "0xcf479181" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000002"}
+
+	rpcErr := custom.asError().(*RevertError)
+	assert.Equal(t, "", rpcErr.Revert.Sig)
+	assert.Equal(t, types.HexData("cf479181"), rpcErr.Revert.Selector)
+
+	revertErr := rpcErr.DecodeWith(structure.ToInternalABI())
+	assert.Equal(t, "InsufficientBalance(uint256 available,uint256 required)", revertErr.Sig)
+	assert.EqualValues(t, big.NewInt(2), revertErr.ParsedData["required"])
+}
@@ -0,0 +1,422 @@
+package client
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ConsenSys/quorum-go-utils/log"
+	"github.com/ConsenSys/quorum-go-utils/types"
+)
+
+// StorageReporter reports the historic storage of contracts, decoded with the storage layout of their template.
+// Storage is only dumped at the blocks where the storage root of a contract changed, and the decoded storage is
+// cached by its root, so that storage that is the same at many blocks, or in many contracts, is only fetched once.
+// The storage roots of each contract are also kept for the blocks that have been scanned, so that later pages of a
+// report only fetch the roots of blocks that haven't been scanned yet
+type StorageReporter struct {
+	client Client
+
+	mux       sync.Mutex
+	cacheSize int
+	cache     map[storageCacheKey]*list.Element
+	recent    *list.List
+
+	//scanMux guards the scans of each contract and the scans in progress. It is never held whilst fetching roots,
+	//so scans are replaced rather than changed, and concurrent requests for the same blocks wait for the same scan
+	scanMux  sync.Mutex
+	scans    map[types.Address]*rootScan
+	scanning map[scanKey]*scanCall
+}
+
+//storageCacheKey identifies decoded storage, which depends on the layout it was decoded with as well as the root
+type storageCacheKey struct {
+	root   types.Hash
+	layout string
+}
+
+//scanKey identifies a request for the storage changes of a contract over a range of blocks
+type scanKey struct {
+	address types.Address
+	begin   uint64
+	end     uint64
+}
+
+//scanCall is a request for storage changes that is in progress, whose result is set once done is closed
+type scanCall struct {
+	done    chan struct{}
+	changes []types.StorageResult
+	err     error
+}
+
+type storageCacheEntry struct {
+	key   storageCacheKey
+	items []*types.StorageItem
+}
+
+//rootScan is the storage roots of a contract over a range of blocks that has been scanned. It only keeps the blocks
+//where the root differs from the block before, so it stays small however long the range is. Blocks are final once
+//they are mined, so the roots never need to be scanned again. A scan isn't changed once it is stored, so that it can
+//be read without holding a lock
+type rootScan struct {
+	begin       uint64
+	end         uint64
+	transitions []types.StorageResult
+}
+
+//rootAt gives the storage root at a block in the scanned range, which is empty if the contract doesn't exist
+func (scan *rootScan) rootAt(blockNumber uint64) types.Hash {
+	i := sort.Search(len(scan.transitions), func(i int) bool { return scan.transitions[i].BlockNumber > blockNumber })
+	if i == 0 {
+		return ""
+	}
+	return scan.transitions[i-1].StorageRoot
+}
+
+// NewStorageReporter creates a reporter that caches the decoded storage of up to cacheSize storage roots,
+// removing the least recently used when it is full
+func NewStorageReporter(c Client, cacheSize int) *StorageReporter {
+	return &StorageReporter{
+		client:    c,
+		cacheSize: cacheSize,
+		cache:     make(map[storageCacheKey]*list.Element),
+		recent:    list.New(),
+		scans:     make(map[types.Address]*rootScan),
+		scanning:  make(map[scanKey]*scanCall),
+	}
+}
+
+/*
+HistoricStorage reports the storage of the contract at each block in the range of the options where its storage root
+changed, most recent first, including the first block of the range if the contract exists then. Blocks where the
+contract doesn't exist are left out. An end block of -1 is the current block.
+
+Only the page of results given by the options is decoded, whilst Total is the number of changes in the whole range.
+The decoded storage may be shared with other reports, so must not be modified.
+*/
+func (reporter *StorageReporter) HistoricStorage(address types.Address, template types.Template, options *types.PageOptions) (*types.ReportingResponseTemplate, error) {
+	opts := types.PageOptions{}
+	if options != nil {
+		opts = *options
+	}
+	opts.SetDefaults()
+	if opts.PageSize < 0 || opts.PageNumber < 0 {
+		return nil, errors.New("page size and number must not be negative")
+	}
+
+	layout, err := storageLayout(template)
+	if err != nil {
+		return nil, err
+	}
+
+	begin, end, err := reporter.blockRange(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := reporter.storageChanges(address, begin, end)
+	if err != nil {
+		return nil, err
+	}
+
+	//the page start is only computed when it is in range, so that it can't overflow
+	start := len(changes)
+	if len(changes) > 0 && opts.PageNumber <= (len(changes)-1)/opts.PageSize {
+		start = opts.PageNumber * opts.PageSize
+	}
+	historicState := []*types.ParsedState{}
+	for i := start; i < len(changes) && len(historicState) < opts.PageSize; i++ {
+		change := changes[len(changes)-1-i]
+		items, err := reporter.decodedStorage(address, change, layout, template.StorageLayout)
+		if err != nil {
+			return nil, err
+		}
+		historicState = append(historicState, &types.ParsedState{BlockNumber: change.BlockNumber, HistoricStorage: items})
+	}
+
+	return &types.ReportingResponseTemplate{
+		Address:       address,
+		HistoricState: historicState,
+		Total:         uint64(len(changes)),
+		Options:       &opts,
+	}, nil
+}
+
+/*
+StorageDiff gives the changes to the storage of the contract made by the block, by comparing its storage at the block
+before with its storage at the block, decoded with the storage layout of the template. A contract that doesn't exist
+at one of the blocks has no storage there, so all of its variables are changed from or to their zero values.
+
+Mapping entries are only compared if their keys are given, e.g. from the transactions of the block. Without keys,
+the decoded storage is cached in the same way as for HistoricStorage.
+*/
+func (reporter *StorageReporter) StorageDiff(address types.Address, template types.Template, blockNumber uint64, keys *types.MappingKeys) (*types.StorageDiff, error) {
+	if blockNumber == 0 {
+		return nil, errors.New("the genesis block has no parent to compare with")
+	}
+	layout, err := storageLayout(template)
+	if err != nil {
+		return nil, err
+	}
+
+	before := types.StorageResult{BlockNumber: blockNumber - 1}
+	if before.StorageRoot, err = reporter.storageRoot(address, before.BlockNumber); err != nil {
+		return nil, err
+	}
+	after := types.StorageResult{BlockNumber: blockNumber}
+	if after.StorageRoot, err = reporter.storageRoot(address, after.BlockNumber); err != nil {
+		return nil, err
+	}
+
+	diff := &types.StorageDiff{Address: address, BlockNumber: blockNumber, Changes: []*types.StorageChange{}}
+	if before.StorageRoot == after.StorageRoot {
+		return diff, nil
+	}
+
+	var decoded [2][]*types.StorageItem
+	for i, result := range []types.StorageResult{before, after} {
+		switch {
+		case result.StorageRoot.IsEmpty():
+			decoded[i], err = layout.DecodeStorageWithKeys(map[types.Hash]string{}, keys)
+		case keys == nil:
+			decoded[i], err = reporter.decodedStorage(address, result, layout, template.StorageLayout)
+		default:
+			decoded[i], err = reporter.decodeDump(address, result.BlockNumber, layout, keys)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff.Changes = types.DiffStorage(decoded[0], decoded[1])
+	return diff, nil
+}
+
+//storageLayout parses the storage layout of the template
+func storageLayout(template types.Template) (*types.SolidityStorageDocument, error) {
+	if template.StorageLayout == "" {
+		return nil, errors.New("template " + template.TemplateName + " has no storage layout")
+	}
+	var layout types.SolidityStorageDocument
+	if err := json.Unmarshal([]byte(template.StorageLayout), &layout); err != nil {
+		return nil, errors.New("invalid storage layout for template " + template.TemplateName + ": " + err.Error())
+	}
+	return &layout, nil
+}
+
+//blockRange gives the first and last blocks of the options, where an end of -1 is the current block
+func (reporter *StorageReporter) blockRange(opts *types.PageOptions) (uint64, uint64, error) {
+	if opts.BeginBlockNumber.Sign() < 0 || !opts.BeginBlockNumber.IsUint64() {
+		return 0, 0, fmt.Errorf("invalid begin block number %s", opts.BeginBlockNumber.String())
+	}
+	begin := opts.BeginBlockNumber.Uint64()
+
+	var end uint64
+	if opts.EndBlockNumber.IsInt64() && opts.EndBlockNumber.Int64() == -1 {
+		current, err := CurrentBlock(reporter.client)
+		if err != nil {
+			return 0, 0, err
+		}
+		end = current
+	} else if opts.EndBlockNumber.Sign() >= 0 && opts.EndBlockNumber.IsUint64() {
+		end = opts.EndBlockNumber.Uint64()
+	} else {
+		return 0, 0, fmt.Errorf("invalid end block number %s", opts.EndBlockNumber.String())
+	}
+
+	if begin > end {
+		return 0, 0, fmt.Errorf("begin block %d is after end block %d", begin, end)
+	}
+	return begin, end, nil
+}
+
+//storageChanges finds the blocks in the range where the storage root of the contract changed, in block order.
+//Concurrent requests for the same range share the same scan, whilst requests for other contracts aren't held up
+func (reporter *StorageReporter) storageChanges(address types.Address, begin uint64, end uint64) ([]types.StorageResult, error) {
+	key := scanKey{address: address, begin: begin, end: end}
+
+	reporter.scanMux.Lock()
+	if call, ok := reporter.scanning[key]; ok {
+		reporter.scanMux.Unlock()
+		<-call.done
+		return call.changes, call.err
+	}
+	call := &scanCall{done: make(chan struct{})}
+	reporter.scanning[key] = call
+	scan := reporter.scans[address]
+	reporter.scanMux.Unlock()
+
+	call.changes, call.err = reporter.scanChanges(address, scan, begin, end)
+
+	reporter.scanMux.Lock()
+	delete(reporter.scanning, key)
+	reporter.scanMux.Unlock()
+	close(call.done)
+	return call.changes, call.err
+}
+
+//scanChanges finds the storage changes in the range, starting from the given scan of the contract. Only the blocks
+//that haven't been scanned before are fetched, extending the scan if the range overlaps or is next to it, or
+//replacing it otherwise
+func (reporter *StorageReporter) scanChanges(address types.Address, scan *rootScan, begin uint64, end uint64) ([]types.StorageResult, error) {
+	var updated *rootScan
+	if scan == nil || (end < scan.begin && scan.begin-end > 1) || (begin > scan.end && begin-scan.end > 1) {
+		log.Debug("Finding storage changes", "account", address.String(), "begin", begin, "end", end)
+		transitions, err := reporter.scanRoots(address, begin, end, "")
+		if err != nil {
+			return nil, err
+		}
+		updated = &rootScan{begin: begin, end: end, transitions: transitions}
+	} else {
+		updated = &rootScan{begin: scan.begin, end: scan.end, transitions: scan.transitions}
+	}
+
+	if begin < updated.begin {
+		log.Debug("Finding storage changes", "account", address.String(), "begin", begin, "end", updated.begin-1)
+		before, err := reporter.scanRoots(address, begin, updated.begin-1, "")
+		if err != nil {
+			return nil, err
+		}
+		//the root at the old first block is only a transition if it differs from the block before it
+		previous := types.Hash("")
+		if len(before) > 0 {
+			previous = before[len(before)-1].StorageRoot
+		}
+		rest := updated.transitions
+		if len(rest) > 0 && rest[0].BlockNumber == updated.begin {
+			rest = rest[1:]
+		}
+		if root := updated.rootAt(updated.begin); root != previous {
+			before = append(before, types.StorageResult{StorageRoot: root, BlockNumber: updated.begin})
+		}
+		updated.transitions = append(before, rest...)
+		updated.begin = begin
+	}
+	if end > updated.end {
+		log.Debug("Finding storage changes", "account", address.String(), "begin", updated.end+1, "end", end)
+		after, err := reporter.scanRoots(address, updated.end+1, end, updated.rootAt(updated.end))
+		if err != nil {
+			return nil, err
+		}
+		//the transitions may be shared with the stored scan, so are copied rather than appended to
+		transitions := make([]types.StorageResult, 0, len(updated.transitions)+len(after))
+		updated.transitions = append(append(transitions, updated.transitions...), after...)
+		updated.end = end
+	}
+
+	//another request may have stored a scan in the meantime, which is kept if it covers more blocks
+	reporter.scanMux.Lock()
+	if current := reporter.scans[address]; current == scan || current == nil || current.end-current.begin <= updated.end-updated.begin {
+		reporter.scans[address] = updated
+	}
+	reporter.scanMux.Unlock()
+
+	//the first block of the range is always a change if the contract exists then
+	var changes []types.StorageResult
+	if root := updated.rootAt(begin); !root.IsEmpty() {
+		changes = append(changes, types.StorageResult{StorageRoot: root, BlockNumber: begin})
+	}
+	for _, transition := range updated.transitions {
+		if transition.BlockNumber > begin && transition.BlockNumber <= end && !transition.StorageRoot.IsEmpty() {
+			changes = append(changes, transition)
+		}
+	}
+	return changes, nil
+}
+
+//scanRoots fetches the storage root of the contract at each block in the range, and gives the blocks where it
+//differs from the block before, where previous is the root at the block before the range
+func (reporter *StorageReporter) scanRoots(address types.Address, begin uint64, end uint64, previous types.Hash) ([]types.StorageResult, error) {
+	var transitions []types.StorageResult
+	for blockNumber := begin; blockNumber <= end; blockNumber++ {
+		root, err := StorageRoot(reporter.client, address, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if root != previous {
+			transitions = append(transitions, types.StorageResult{StorageRoot: root, BlockNumber: blockNumber})
+		}
+		previous = root
+
+		//the end block may be the maximum block number
+		if blockNumber == end {
+			break
+		}
+	}
+	return transitions, nil
+}
+
+//storageRoot gives the storage root of the contract at the block, from its scan if the block has been scanned
+func (reporter *StorageReporter) storageRoot(address types.Address, blockNumber uint64) (types.Hash, error) {
+	reporter.scanMux.Lock()
+	scan := reporter.scans[address]
+	reporter.scanMux.Unlock()
+
+	if scan != nil && blockNumber >= scan.begin && blockNumber <= scan.end {
+		return scan.rootAt(blockNumber), nil
+	}
+	return StorageRoot(reporter.client, address, blockNumber)
+}
+
+//decodedStorage gives the decoded storage at the change, from the cache if its root has been decoded before
+func (reporter *StorageReporter) decodedStorage(address types.Address, change types.StorageResult, layout *types.SolidityStorageDocument, rawLayout string) ([]*types.StorageItem, error) {
+	key := storageCacheKey{root: change.StorageRoot, layout: rawLayout}
+	if items, ok := reporter.cached(key); ok {
+		return items, nil
+	}
+
+	items, err := reporter.decodeDump(address, change.BlockNumber, layout, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter.store(key, items)
+	return items, nil
+}
+
+//decodeDump dumps the storage of the contract at the block and decodes it, with the mapping keys if there are any
+func (reporter *StorageReporter) decodeDump(address types.Address, blockNumber uint64, layout *types.SolidityStorageDocument, keys *types.MappingKeys) ([]*types.StorageItem, error) {
+	dump, err := DumpAddress(reporter.client, address, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	items, err := layout.DecodeStorageWithKeys(dump.Storage, keys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode storage of %s at block %d: %s", address.String(), blockNumber, err.Error())
+	}
+	return items, nil
+}
+
+func (reporter *StorageReporter) cached(key storageCacheKey) ([]*types.StorageItem, bool) {
+	reporter.mux.Lock()
+	defer reporter.mux.Unlock()
+
+	element, ok := reporter.cache[key]
+	if !ok {
+		return nil, false
+	}
+	reporter.recent.MoveToFront(element)
+	return element.Value.(*storageCacheEntry).items, true
+}
+
+func (reporter *StorageReporter) store(key storageCacheKey, items []*types.StorageItem) {
+	reporter.mux.Lock()
+	defer reporter.mux.Unlock()
+
+	if reporter.cacheSize <= 0 {
+		return
+	}
+	if element, ok := reporter.cache[key]; ok {
+		reporter.recent.MoveToFront(element)
+		return
+	}
+	reporter.cache[key] = reporter.recent.PushFront(&storageCacheEntry{key: key, items: items})
+	for reporter.recent.Len() > reporter.cacheSize {
+		oldest := reporter.recent.Back()
+		reporter.recent.Remove(oldest)
+		delete(reporter.cache, oldest.Value.(*storageCacheEntry).key)
+	}
+}
@@ -0,0 +1,313 @@
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ConsenSys/quorum-go-utils/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const totalStorageLayout = `{
+	"storage": [{"label": "total", "offset": 0, "slot": "0", "type": "t_uint256"}],
+	"types": {"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"}}
+}`
+
+var (
+	reportAddress  = types.NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17")
+	reportTemplate = types.Template{TemplateName: "Total", StorageLayout: totalStorageLayout}
+)
+
+//reportClient is a contract that is deployed at block 2, and whose total is set at blocks 2, 4 and 6
+func reportClient() *StubQuorumClient {
+	roots := []string{"", "", "a", "a", "b", "b", "c"}
+	mockRPC := map[string]interface{}{}
+	for blockNumber, root := range roots {
+		mockRPC[fmt.Sprintf("eth_storageRoot%s0x%x", reportAddress.String(), blockNumber)] = types.NewHash(root)
+	}
+	for blockNumber, total := range map[int]string{2: "01", 4: "02", 6: "03"} {
+		mockRPC[fmt.Sprintf("debug_dumpAddress%s0x%x", reportAddress.String(), blockNumber)] = &types.RawAccountState{
+			Storage: map[string]string{"0000000000000000000000000000000000000000000000000000000000000000": total},
+		}
+	}
+	mockGraphQL := map[string]map[string]interface{}{
+		CurrentBlockQuery(): {"block": interface{}(map[string]interface{}{"number": "0x6"})},
+	}
+	return NewStubQuorumClient(mockGraphQL, mockRPC)
+}
+
+//reportedTotals gives the block number and total of each state in the report
+func reportedTotals(report *types.ReportingResponseTemplate) [][2]uint64 {
+	var totals [][2]uint64
+	for _, state := range report.HistoricState {
+		totals = append(totals, [2]uint64{state.BlockNumber, state.HistoricStorage[0].Value.(*big.Int).Uint64()})
+	}
+	return totals
+}
+
+func TestStorageReporter_HistoricStorage(t *testing.T) {
+	reporter := NewStorageReporter(reportClient(), 10)
+
+	testMatrix := []struct {
+		options        *types.PageOptions
+		expectedTotal  uint64
+		expectedTotals [][2]uint64
+	}{
+		{nil, 3, [][2]uint64{{6, 3}, {4, 2}, {2, 1}}},
+		{&types.PageOptions{PageSize: 2}, 3, [][2]uint64{{6, 3}, {4, 2}}},
+		{&types.PageOptions{PageSize: 2, PageNumber: 1}, 3, [][2]uint64{{2, 1}}},
+		{&types.PageOptions{PageSize: 2, PageNumber: 2}, 3, nil},
+		{&types.PageOptions{PageSize: 5, PageNumber: 1 << 61}, 3, nil},
+		//the state at the first block of the range is always reported
+		{&types.PageOptions{BeginBlockNumber: big.NewInt(3), EndBlockNumber: big.NewInt(5)}, 2, [][2]uint64{{4, 2}, {3, 1}}},
+		{&types.PageOptions{BeginBlockNumber: big.NewInt(1), EndBlockNumber: big.NewInt(1)}, 0, nil},
+	}
+
+	for idx, test := range testMatrix {
+		report, err := reporter.HistoricStorage(reportAddress, reportTemplate, test.options)
+
+		if assert.Nil(t, err, "Test index %d failed", idx) {
+			assert.Equal(t, reportAddress, report.Address, "Test index %d failed", idx)
+			assert.Equal(t, test.expectedTotal, report.Total, "Test index %d failed", idx)
+			assert.Equal(t, test.expectedTotals, reportedTotals(report), "Test index %d failed", idx)
+			assert.NotNil(t, report.HistoricState, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestStorageReporter_Cache(t *testing.T) {
+	stubClient := reportClient()
+	reporter := NewStorageReporter(stubClient, 2)
+
+	_, err := reporter.HistoricStorage(reportAddress, reportTemplate, nil)
+	assert.Nil(t, err)
+
+	//the two roots that were decoded last are cached, so they aren't dumped again
+	delete(stubClient.mockRPC, fmt.Sprintf("debug_dumpAddress%s0x2", reportAddress.String()))
+	delete(stubClient.mockRPC, fmt.Sprintf("debug_dumpAddress%s0x4", reportAddress.String()))
+	report, err := reporter.HistoricStorage(reportAddress, reportTemplate, &types.PageOptions{PageSize: 2, PageNumber: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]uint64{{2, 1}}, reportedTotals(report))
+
+	//decoding the root at block 6 pushes out the least recently used root, at block 4
+	report, err = reporter.HistoricStorage(reportAddress, reportTemplate, &types.PageOptions{PageSize: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]uint64{{6, 3}}, reportedTotals(report))
+
+	report, err = reporter.HistoricStorage(reportAddress, reportTemplate, &types.PageOptions{PageSize: 1, PageNumber: 1})
+	assert.EqualError(t, err, "not found")
+	assert.Nil(t, report)
+}
+
+//countingClient counts the RPC calls made to the stub client by method
+type countingClient struct {
+	*StubQuorumClient
+	calls map[string]int
+}
+
+func (c *countingClient) RPCCall(result interface{}, method string, args ...interface{}) error {
+	c.calls[method]++
+	return c.StubQuorumClient.RPCCall(result, method, args...)
+}
+
+func TestStorageReporter_ScanCache(t *testing.T) {
+	stubClient := &countingClient{StubQuorumClient: reportClient(), calls: map[string]int{}}
+	reporter := NewStorageReporter(stubClient, 10)
+
+	testMatrix := []struct {
+		options        *types.PageOptions
+		expectedCalls  int
+		expectedTotals [][2]uint64
+	}{
+		{&types.PageOptions{PageSize: 2}, 7, [][2]uint64{{6, 3}, {4, 2}}},
+		//the next page reuses the roots that were scanned for the first
+		{&types.PageOptions{PageSize: 2, PageNumber: 1}, 7, [][2]uint64{{2, 1}}},
+		{&types.PageOptions{BeginBlockNumber: big.NewInt(3), EndBlockNumber: big.NewInt(5)}, 7, [][2]uint64{{4, 2}, {3, 1}}},
+	}
+
+	for idx, test := range testMatrix {
+		report, err := reporter.HistoricStorage(reportAddress, reportTemplate, test.options)
+
+		if assert.Nil(t, err, "Test index %d failed", idx) {
+			assert.Equal(t, test.expectedTotals, reportedTotals(report), "Test index %d failed", idx)
+			assert.Equal(t, test.expectedCalls, stubClient.calls["eth_storageRoot"], "Test index %d failed", idx)
+		}
+	}
+
+	//a diff within the scanned blocks doesn't fetch the roots again
+	_, err := reporter.StorageDiff(reportAddress, reportTemplate, 4, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, stubClient.calls["eth_storageRoot"])
+}
+
+func TestStorageReporter_ScanCacheExtended(t *testing.T) {
+	stubClient := &countingClient{StubQuorumClient: reportClient(), calls: map[string]int{}}
+	reporter := NewStorageReporter(stubClient, 10)
+
+	report, err := reporter.HistoricStorage(reportAddress, reportTemplate, &types.PageOptions{BeginBlockNumber: big.NewInt(4), EndBlockNumber: big.NewInt(5)})
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]uint64{{4, 2}}, reportedTotals(report))
+	assert.Equal(t, 2, stubClient.calls["eth_storageRoot"])
+
+	//only the blocks either side of the scanned ones are fetched
+	report, err = reporter.HistoricStorage(reportAddress, reportTemplate, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]uint64{{6, 3}, {4, 2}, {2, 1}}, reportedTotals(report))
+	assert.Equal(t, 7, stubClient.calls["eth_storageRoot"])
+}
+
+//blockingClient holds up fetching the storage roots of one contract until it is released, and counts the roots
+//that are fetched
+type blockingClient struct {
+	*StubQuorumClient
+	blocked types.Address
+	release chan struct{}
+
+	mux   sync.Mutex
+	roots int
+}
+
+func (c *blockingClient) RPCCall(result interface{}, method string, args ...interface{}) error {
+	if method == "eth_storageRoot" {
+		c.mux.Lock()
+		c.roots++
+		c.mux.Unlock()
+		if args[0] == c.blocked.String() {
+			<-c.release
+		}
+	}
+	return c.StubQuorumClient.RPCCall(result, method, args...)
+}
+
+func TestStorageReporter_ConcurrentScans(t *testing.T) {
+	blocked := types.NewAddress("0x9d13c6d3afe1721beef56b55d303b09e021e27ab")
+	stubClient := reportClient()
+	for blockNumber := 0; blockNumber <= 6; blockNumber++ {
+		stubClient.mockRPC[fmt.Sprintf("eth_storageRoot%s0x%x", blocked.String(), blockNumber)] = types.NewHash("")
+	}
+	client := &blockingClient{StubQuorumClient: stubClient, blocked: blocked, release: make(chan struct{})}
+	reporter := NewStorageReporter(client, 10)
+
+	var wg sync.WaitGroup
+	blockedReports := make([]*types.ReportingResponseTemplate, 2)
+	for i := range blockedReports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			blockedReports[i], _ = reporter.HistoricStorage(blocked, reportTemplate, nil)
+		}(i)
+	}
+
+	//reports and diffs of other contracts aren't held up by the scan that is waiting
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		report, err := reporter.HistoricStorage(reportAddress, reportTemplate, nil)
+		if assert.Nil(t, err) {
+			assert.Equal(t, [][2]uint64{{6, 3}, {4, 2}, {2, 1}}, reportedTotals(report))
+		}
+		_, err = reporter.StorageDiff(reportAddress, reportTemplate, 4, nil)
+		assert.Nil(t, err)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("report of another contract was held up by a scan")
+	}
+
+	close(client.release)
+	wg.Wait()
+
+	//the two reports of the same range shared a single scan
+	for _, report := range blockedReports {
+		if assert.NotNil(t, report) {
+			assert.Equal(t, uint64(0), report.Total)
+		}
+	}
+	assert.Equal(t, 7+7, client.roots)
+}
+
+func TestStorageReporter_HistoricStorageErrors(t *testing.T) {
+	reporter := NewStorageReporter(reportClient(), 10)
+
+	testMatrix := []struct {
+		template      types.Template
+		options       *types.PageOptions
+		expectedError string
+	}{
+		{types.Template{TemplateName: "Empty"}, nil, "template Empty has no storage layout"},
+		{types.Template{TemplateName: "Broken", StorageLayout: "{"}, nil, "invalid storage layout for template Broken: unexpected end of JSON input"},
+		{reportTemplate, &types.PageOptions{PageSize: -1}, "page size and number must not be negative"},
+		{reportTemplate, &types.PageOptions{BeginBlockNumber: big.NewInt(-2)}, "invalid begin block number -2"},
+		{reportTemplate, &types.PageOptions{EndBlockNumber: big.NewInt(-2)}, "invalid end block number -2"},
+		{reportTemplate, &types.PageOptions{BeginBlockNumber: big.NewInt(5), EndBlockNumber: big.NewInt(4)}, "begin block 5 is after end block 4"},
+		//there is no storage root for block 7
+		{reportTemplate, &types.PageOptions{EndBlockNumber: big.NewInt(7)}, "not found"},
+	}
+
+	for idx, test := range testMatrix {
+		report, err := reporter.HistoricStorage(reportAddress, test.template, test.options)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		assert.Nil(t, report, "Test index %d failed", idx)
+	}
+}
+
+func TestStorageReporter_StorageDiff(t *testing.T) {
+	reporter := NewStorageReporter(reportClient(), 10)
+
+	testMatrix := []struct {
+		blockNumber     uint64
+		keys            *types.MappingKeys
+		expectedChanges []*types.StorageChange
+	}{
+		//the contract is deployed at block 2, so has no storage at block 1
+		{2, nil, []*types.StorageChange{{Kind: types.StorageModified, Path: "total", Type: "uint256", OldValue: big.NewInt(0), NewValue: big.NewInt(1)}}},
+		{3, nil, []*types.StorageChange{}},
+		{4, nil, []*types.StorageChange{{Kind: types.StorageModified, Path: "total", Type: "uint256", OldValue: big.NewInt(1), NewValue: big.NewInt(2)}}},
+		{2, types.NewMappingKeys(), []*types.StorageChange{{Kind: types.StorageModified, Path: "total", Type: "uint256", OldValue: big.NewInt(0), NewValue: big.NewInt(1)}}},
+	}
+
+	for idx, test := range testMatrix {
+		diff, err := reporter.StorageDiff(reportAddress, reportTemplate, test.blockNumber, test.keys)
+
+		if assert.Nil(t, err, "Test index %d failed", idx) {
+			assert.Equal(t, reportAddress, diff.Address, "Test index %d failed", idx)
+			assert.Equal(t, test.blockNumber, diff.BlockNumber, "Test index %d failed", idx)
+			assert.Equal(t, len(test.expectedChanges), len(diff.Changes), "Test index %d failed", idx)
+			for i, change := range diff.Changes {
+				expected := test.expectedChanges[i]
+				assert.Equal(t, expected.Kind, change.Kind, "Test index %d failed", idx)
+				assert.Equal(t, expected.Path, change.Path, "Test index %d failed", idx)
+				assert.Equal(t, expected.Type, change.Type, "Test index %d failed", idx)
+				assert.Equal(t, 0, expected.OldValue.(*big.Int).Cmp(change.OldValue.(*big.Int)), "Test index %d failed", idx)
+				assert.Equal(t, 0, expected.NewValue.(*big.Int).Cmp(change.NewValue.(*big.Int)), "Test index %d failed", idx)
+			}
+		}
+	}
+}
+
+func TestStorageReporter_StorageDiffErrors(t *testing.T) {
+	reporter := NewStorageReporter(reportClient(), 10)
+
+	testMatrix := []struct {
+		template      types.Template
+		blockNumber   uint64
+		expectedError string
+	}{
+		{reportTemplate, 0, "the genesis block has no parent to compare with"},
+		{types.Template{TemplateName: "Empty"}, 2, "template Empty has no storage layout"},
+		//there is no storage root for block 7
+		{reportTemplate, 7, "not found"},
+	}
+
+	for idx, test := range testMatrix {
+		diff, err := reporter.StorageDiff(reportAddress, test.template, test.blockNumber, nil)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		assert.Nil(t, diff, "Test index %d failed", idx)
+	}
+}
@@ -58,6 +58,17 @@ func TraceTransaction(c Client, txHash types.Hash) (types.RawOuterCall, error) {
 	return resp, nil
 }
 
+// TraceTransactionOutput traces the transaction and sets its Output to the data it returned, or to its revert data if
+// it failed, so that ParsedTransaction can decode the outputs of the function or the reason it reverted
+func TraceTransactionOutput(c Client, tx *types.Transaction) error {
+	trace, err := TraceTransaction(c, tx.Hash)
+	if err != nil {
+		return err
+	}
+	tx.Output = trace.Output
+	return nil
+}
+
 func GetCode(c Client, address types.Address, blockNumber uint64) (types.HexData, error) {
 	log.Debug("Querying account code", "account", address.String(), "block number", blockNumber)
 	var res types.HexData
@@ -103,13 +114,13 @@ func CallEIP165(c Client, address types.Address, interfaceId []byte, blockNum ui
 	copy(paddedInterface, interfaceId)
 	calldata := append(eip165Id, paddedInterface...)
 
-	msg := types.EIP165Call{
+	msg := types.CallMsg{
 		To:   address,
 		Data: types.HexData(hex.EncodeToString(calldata)),
 	}
 
 	var res types.HexData
-	err := c.RPCCall(&res, ethCall, msg, fmtBlockNum(blockNum))
+	err := c.RPCCall(&res, ethCall, types.EIP165Call(msg), fmtBlockNum(blockNum))
 	if err != nil {
 		return false, err
 	}
@@ -153,16 +164,37 @@ func CallBalanceOfERC20(c Client, contract types.Address, holder types.Address,
 	// "000000000000000000000000" + string(holder) is the token holders address, padded to 32 bytes
 
 	blockAsHex := fmtBlockNum(blockNum)
-	msg := types.EIP165Call{
+	msg := types.CallMsg{
 		To:   contract,
 		Data: types.NewHexData("0x70a08231" + "000000000000000000000000" + string(holder)),
 	}
 
 	var res types.HexData
-	err := c.RPCCall(&res, ethCall, msg, blockAsHex)
+	err := c.RPCCall(&res, ethCall, types.EIP165Call(msg), blockAsHex)
 	return res, err
 }
 
+// CallFunction calls a function of the contract at the given address using eth_call,
+// and decodes the returned data using the outputs of the function
+func CallFunction(c Client, contract types.Address, function types.ContractABIFunction, blockNum uint64, args ...interface{}) (map[string]interface{}, error) {
+	calldata, err := function.Pack(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := types.CallMsg{
+		To:   contract,
+		Data: types.HexData(hex.EncodeToString(calldata)),
+	}
+
+	log.Debug("Calling contract function", "contract", contract.String(), "function", function.String(), "block number", blockNum)
+	var res types.HexData
+	if err := c.RPCCall(&res, ethCall, types.EIP165Call(msg), fmtBlockNum(blockNum)); err != nil {
+		return nil, err
+	}
+	return function.ParseOutput(res.AsBytes())
+}
+
 func StorageRoot(c Client, account types.Address, blockNum uint64) (types.Hash, error) {
 	var res types.Hash
 	err := c.RPCCall(&res, ethStorageRoot, account.String(), fmt.Sprintf("0x%x", blockNum))
@@ -74,6 +74,25 @@ func TestTraceTransaction(t *testing.T) {
 	assert.Len(t, trace.Calls, 1)
 }
 
+func TestTraceTransactionOutput(t *testing.T) {
+	mockRPC := map[string]interface{}{
+		"debug_traceTransaction0x0000000000000000000000000000000000000000000000000000000000000001<*client.TraceConfig Value>": types.RawOuterCall{
+			Output: types.NewHexData("0x00000000000000000000000000000000000000000000000000000000000003e8"),
+		},
+	}
+	stubClient := NewStubQuorumClient(nil, mockRPC)
+
+	tx := &types.Transaction{Hash: types.NewHash("0x01")}
+	err := TraceTransactionOutput(stubClient, tx)
+	assert.Nil(t, err)
+	assert.Equal(t, types.NewHexData("0x00000000000000000000000000000000000000000000000000000000000003e8"), tx.Output)
+
+	tx = &types.Transaction{Hash: types.NewHash("0x02")}
+	err = TraceTransactionOutput(stubClient, tx)
+	assert.EqualError(t, err, "not found")
+	assert.Equal(t, types.HexData(""), tx.Output)
+}
+
 func TestDumpAddress_WithError(t *testing.T) {
 	mockRPC := map[string]interface{}{}
 	stubClient := NewStubQuorumClient(nil, mockRPC)
@@ -258,8 +277,8 @@ func TestTransactionWithReceipt(t *testing.T) {
 		Nonce:             types.HexNumber(1),
 		From:              Address{Address: "ed9d02e382b34818e88b88a309c7fe71e65f419d"},
 		To:                Address{},
-		Value:             types.HexNumber(0),
-		GasPrice:          types.HexNumber(0),
+		Value:             types.HexBig{},
+		GasPrice:          types.HexBig{},
 		Gas:               types.HexNumber(4700000),
 		GasUsed:           types.HexNumber(164007),
 		CumulativeGasUsed: types.HexNumber(164007),
@@ -337,3 +356,34 @@ func TestStorageRoot(t *testing.T) {
 	assert.Nil(t, err)
 	assert.EqualValues(t, "0000000000000000000000000000000000000000000000000000000000000001", result)
 }
+
+func TestCallFunction(t *testing.T) {
+	mockRPC := map[string]interface{}{
+		"eth_call<types.EIP165Call Value>0x1": types.NewHexData("0x" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"4142430000000000000000000000000000000000000000000000000000000000"),
+	}
+
+	stubClient := NewStubQuorumClient(nil, mockRPC)
+
+	symbol := types.ContractABIFunction{
+		Type:    "function",
+		Name:    "symbol",
+		Outputs: []types.ContractABIArgument{{Name: "", Type: "string"}},
+	}
+
+	result, err := CallFunction(stubClient, types.NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"), symbol, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"0": "ABC"}, result)
+}
+
+func TestCallFunction_WithError(t *testing.T) {
+	stubClient := NewStubQuorumClient(nil, nil)
+
+	symbol := types.ContractABIFunction{Type: "function", Name: "symbol"}
+
+	result, err := CallFunction(stubClient, types.NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"), symbol, 1)
+	assert.EqualError(t, err, "not found")
+	assert.Nil(t, result)
+}
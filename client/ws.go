@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -42,6 +43,56 @@ func (err *msgError) Error() string {
 	return err.Message
 }
 
+// asError gives a RevertError if the error is for a reverted call whose data holds the revert data,
+// or the error as it is otherwise
+func (err *msgError) asError() error {
+	if err.Code != revertedCode && !strings.HasPrefix(err.Message, "execution reverted") {
+		return err
+	}
+	data, ok := err.Data.(string)
+	if !ok || !strings.HasPrefix(data, "0x") {
+		return err
+	}
+	revertData, decodeErr := hex.DecodeString(data[2:])
+	if decodeErr != nil {
+		return err
+	}
+	return &RevertError{Code: err.Code, Message: err.Message, Revert: types.DecodeRevert(revertData)}
+}
+
+// revertedCode is the JSON-RPC error code of a call that reverted
+const revertedCode = 3
+
+/*
+RevertError is the error returned by a call that reverted, keeping the code and message from the node along with the
+revert data. The revert data is decoded as an Error(string) or a Panic(uint256), as the custom errors of the contract
+aren't known, so DecodeWith can be used to decode it with the ABI of the contract.
+
+It unwraps to the *types.RevertError, so errors.As can be used to get the decoded revert data.
+*/
+type RevertError struct {
+	Code    int
+	Message string
+	Revert  *types.RevertError
+}
+
+func (err *RevertError) Error() string {
+	if err.Message == "" {
+		return err.Revert.Error()
+	}
+	return err.Message
+}
+
+func (err *RevertError) Unwrap() error {
+	return err.Revert
+}
+
+// DecodeWith decodes the revert data using the ABI of the contract that reverted, so that its custom errors are
+// decoded. The ABI of a contract bound to a template can be found with types.ABIRegistry.TemplateFor
+func (err *RevertError) DecodeWith(abi *types.ContractABI) *types.RevertError {
+	return abi.DecodeRevert(err.Revert.Data.AsBytes())
+}
+
 type webSocketClient struct {
 	rawUrl                      string
 	conn                        *websocket.Conn
@@ -0,0 +1,644 @@
+package rlp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+var (
+	// EOL is returned when the end of the current list has been reached
+	EOL = errors.New("rlp: end of list")
+
+	ErrExpectedString   = errors.New("rlp: expected string or byte")
+	ErrExpectedList     = errors.New("rlp: expected list")
+	ErrCanonInt         = errors.New("rlp: non-canonical integer (leading zero bytes)")
+	ErrCanonSize        = errors.New("rlp: non-canonical size information")
+	ErrElemTooLarge     = errors.New("rlp: element is larger than containing list")
+	ErrValueTooLarge    = errors.New("rlp: value size exceeds available input length")
+	ErrMoreThanOneValue = errors.New("rlp: input contains more than one value")
+	ErrUintOverflow     = errors.New("rlp: uint overflow")
+	ErrNotAtEOL         = errors.New("rlp: list has more elements than expected")
+	ErrTooDeep          = errors.New("rlp: lists are nested too deeply")
+)
+
+// MaxListDepth is the maximum number of lists that can be nested within each other when decoding
+const MaxListDepth = 1024
+
+// Decoder is implemented by types that decode themselves from RLP. DecodeRLP must read exactly one value from the
+// stream
+type Decoder interface {
+	DecodeRLP(s *Stream) error
+}
+
+var decoderType = reflect.TypeOf((*Decoder)(nil)).Elem()
+
+// Kind is the kind of an RLP value
+type Kind int
+
+const (
+	// Byte is a single byte below 0x80, which is its own encoding
+	Byte Kind = iota
+	// String is a byte string
+	String
+	// List is a list of values
+	List
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Byte:
+		return "Byte"
+	case String:
+		return "String"
+	case List:
+		return "List"
+	}
+	return fmt.Sprintf("Unknown(%d)", int(k))
+}
+
+// DecodeError describes why RLP could not be decoded into a value, along with where the value is
+type DecodeError struct {
+	// Err is why the value couldn't be decoded, such as ErrCanonInt
+	Err error
+	// Path is the value that couldn't be decoded, e.g. "rlp.transaction.Nonce" or "[]uint64[2]"
+	Path string
+}
+
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("%s, decoding into %s", err.Err.Error(), err.Path)
+}
+
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+// Decode decodes a value from the reader into val, which must be a non-nil pointer
+func Decode(r io.Reader, val interface{}) error {
+	return NewStream(r, 0).Decode(val)
+}
+
+// DecodeBytes decodes the value in b into val, which must be a non-nil pointer. All of b must be the value
+func DecodeBytes(b []byte, val interface{}) error {
+	r := bytes.NewReader(b)
+	if err := NewStream(r, uint64(len(b))).Decode(val); err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return ErrMoreThanOneValue
+	}
+	return nil
+}
+
+/*
+Stream reads RLP values from a reader one at a time, so that large or many values can be decoded without reading the
+whole input first.
+
+Values are read with Bytes, Uint64, BigInt, Bool and Raw, or decoded into Go values with Decode. A list is read by
+calling List, reading its elements until EOL is returned, and then calling ListEnd.
+*/
+type Stream struct {
+	r         io.Reader
+	remaining uint64   //the bytes left in the input
+	limited   bool     //whether the input has a known length, rather than being read until EOF
+	lists     []uint64 //the bytes left in each open list, innermost last
+
+	//the kind and size of the next value, once its header has been read
+	kindSet bool
+	kind    Kind
+	size    uint64
+	kindErr error
+	byteval byte
+
+	buf [9]byte
+}
+
+// NewStream creates a stream that reads from r, which must not hold more than inputLimit bytes. If inputLimit is 0,
+// the length of a *bytes.Reader, *bytes.Buffer or *strings.Reader is used, and other readers are unlimited
+func NewStream(r io.Reader, inputLimit uint64) *Stream {
+	if inputLimit > 0 {
+		return &Stream{r: r, remaining: inputLimit, limited: true}
+	}
+	switch br := r.(type) {
+	case *bytes.Reader:
+		return &Stream{r: r, remaining: uint64(br.Len()), limited: true}
+	case *bytes.Buffer:
+		return &Stream{r: r, remaining: uint64(br.Len()), limited: true}
+	case *strings.Reader:
+		return &Stream{r: r, remaining: uint64(br.Len()), limited: true}
+	}
+	return &Stream{r: r, remaining: math.MaxUint64}
+}
+
+// Kind gives the kind of the next value and the size of its content, without reading it. It returns EOL at the end
+// of a list, and io.EOF at the end of the input
+func (s *Stream) Kind() (Kind, uint64, error) {
+	if s.kindSet {
+		return s.kind, s.size, s.kindErr
+	}
+	if len(s.lists) > 0 && s.lists[len(s.lists)-1] == 0 {
+		return 0, 0, EOL
+	}
+	if len(s.lists) == 0 && s.remaining == 0 {
+		return 0, 0, io.EOF
+	}
+
+	s.kind, s.size, s.kindErr = s.readKind()
+	if s.kindErr == nil {
+		if len(s.lists) > 0 && s.size > s.lists[len(s.lists)-1] {
+			s.kindErr = ErrElemTooLarge
+		} else if s.size > s.remaining {
+			s.kindErr = ErrValueTooLarge
+		}
+	}
+	s.kindSet = true
+	return s.kind, s.size, s.kindErr
+}
+
+// Bytes reads a byte string
+func (s *Stream) Bytes() ([]byte, error) {
+	kind, size, err := s.Kind()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case Byte:
+		s.kindSet = false
+		return []byte{s.byteval}, nil
+	case String:
+		s.kindSet = false
+		b, err := s.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		if size == 1 && b[0] < 0x80 {
+			return nil, ErrCanonSize
+		}
+		return b, nil
+	}
+	return nil, ErrExpectedString
+}
+
+// Uint64 reads an unsigned integer of up to 64 bits
+func (s *Stream) Uint64() (uint64, error) {
+	return s.uint(64)
+}
+
+// Bool reads a boolean, which must be the integer 0 or 1
+func (s *Stream) Bool() (bool, error) {
+	i, err := s.uint(8)
+	if err != nil {
+		return false, err
+	}
+	if i > 1 {
+		return false, fmt.Errorf("rlp: invalid boolean value %d", i)
+	}
+	return i == 1, nil
+}
+
+// BigInt reads a non-negative integer of any size
+func (s *Stream) BigInt() (*big.Int, error) {
+	b, err := s.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 && b[0] == 0 {
+		return nil, ErrCanonInt
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// List starts reading a list, giving the size of its content
+func (s *Stream) List() (uint64, error) {
+	kind, size, err := s.Kind()
+	if err != nil {
+		return 0, err
+	}
+	if kind != List {
+		return 0, ErrExpectedList
+	}
+	if len(s.lists) >= MaxListDepth {
+		return 0, ErrTooDeep
+	}
+	s.kindSet = false
+
+	//the content of the list is part of the list it is in
+	if len(s.lists) > 0 {
+		s.lists[len(s.lists)-1] -= size
+	}
+	s.lists = append(s.lists, size)
+	return size, nil
+}
+
+// ListEnd finishes reading the current list, which must have no more elements
+func (s *Stream) ListEnd() error {
+	if len(s.lists) == 0 {
+		return errors.New("rlp: ListEnd called outside of a list")
+	}
+	if s.lists[len(s.lists)-1] != 0 {
+		return ErrNotAtEOL
+	}
+	s.lists = s.lists[:len(s.lists)-1]
+	s.kindSet = false
+	return nil
+}
+
+// Raw reads the next value without decoding it, giving its encoding. The content of a list is checked to be canonical
+func (s *Stream) Raw() ([]byte, error) {
+	kind, size, err := s.Kind()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case Byte:
+		s.kindSet = false
+		return []byte{s.byteval}, nil
+	case String:
+		b, err := s.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		return append(appendHeader(nil, 0x80, size), b...), nil
+	}
+
+	if _, err := s.List(); err != nil {
+		return nil, err
+	}
+	raw := appendHeader(nil, emptyListByte, size)
+	for {
+		elem, err := s.Raw()
+		if err == EOL {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, elem...)
+	}
+	return raw, s.ListEnd()
+}
+
+// Decode decodes the next value into val, which must be a non-nil pointer, see the package documentation for how
+// values are decoded. EOL and io.EOF are returned as they are if there is no value to decode, but other errors are
+// a *DecodeError
+func (s *Stream) Decode(val interface{}) error {
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() || rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("rlp: Decode requires a non-nil pointer, not %T", val)
+	}
+	if _, _, err := s.Kind(); err == EOL || err == io.EOF {
+		return err
+	}
+
+	err := s.decode(rv.Elem())
+	if decodeErr, ok := err.(*DecodeError); ok {
+		decodeErr.Path = rv.Elem().Type().String() + decodeErr.Path
+	}
+	return err
+}
+
+func (s *Stream) decode(v reflect.Value) error {
+	typ := v.Type()
+	switch {
+	case typ == rawValueType:
+		raw, err := s.Raw()
+		if err != nil {
+			return decodeError(err)
+		}
+		v.SetBytes(raw)
+		return nil
+	case reflect.PtrTo(typ).Implements(decoderType):
+		return decodeError(v.Addr().Interface().(Decoder).DecodeRLP(s))
+	case typ == bigIntType:
+		i, err := s.BigInt()
+		if err != nil {
+			return decodeError(err)
+		}
+		v.Set(reflect.ValueOf(*i))
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(typ.Elem())
+		if err := s.decode(elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Bool:
+		b, err := s.Bool()
+		if err != nil {
+			return decodeError(err)
+		}
+		v.SetBool(b)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, err := s.uint(typ.Bits())
+		if err != nil {
+			return decodeError(err)
+		}
+		v.SetUint(i)
+	case reflect.String:
+		b, err := s.Bytes()
+		if err != nil {
+			return decodeError(err)
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			b, err := s.Bytes()
+			if err != nil {
+				return decodeError(err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		if _, err := s.List(); err != nil {
+			return decodeError(err)
+		}
+		elems, err := s.decodeElements(typ)
+		if err != nil {
+			return err
+		}
+		v.Set(elems)
+		return decodeError(s.ListEnd())
+	case reflect.Array:
+		return s.decodeArray(v)
+	case reflect.Struct:
+		return s.decodeStruct(v)
+	case reflect.Interface:
+		if typ.NumMethod() != 0 {
+			return decodeError(fmt.Errorf("rlp: type %v is not RLP-serializable", typ))
+		}
+		value, err := s.decodeInterface()
+		if err != nil {
+			return decodeError(err)
+		}
+		v.Set(reflect.ValueOf(value))
+	default:
+		return decodeError(fmt.Errorf("rlp: type %v is not RLP-serializable", typ))
+	}
+	return nil
+}
+
+//decodeElements decodes the rest of the elements of the current list into a slice of the type
+func (s *Stream) decodeElements(typ reflect.Type) (reflect.Value, error) {
+	elems := reflect.MakeSlice(typ, 0, 0)
+	for i := 0; ; i++ {
+		if _, _, err := s.Kind(); err == EOL {
+			return elems, nil
+		}
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := s.decode(elem); err != nil {
+			return elems, addPath(err, fmt.Sprintf("[%d]", i))
+		}
+		elems = reflect.Append(elems, elem)
+	}
+}
+
+func (s *Stream) decodeArray(v reflect.Value) error {
+	typ := v.Type()
+	if typ.Elem().Kind() == reflect.Uint8 {
+		b, err := s.Bytes()
+		if err != nil {
+			return decodeError(err)
+		}
+		if len(b) != typ.Len() {
+			return decodeError(fmt.Errorf("rlp: expected %d bytes but got %d", typ.Len(), len(b)))
+		}
+		reflect.Copy(v, reflect.ValueOf(b))
+		return nil
+	}
+
+	if _, err := s.List(); err != nil {
+		return decodeError(err)
+	}
+	for i := 0; i < typ.Len(); i++ {
+		if _, _, err := s.Kind(); err == EOL {
+			return decodeError(fmt.Errorf("rlp: expected %d elements but got %d", typ.Len(), i))
+		}
+		if err := s.decode(v.Index(i)); err != nil {
+			return addPath(err, fmt.Sprintf("[%d]", i))
+		}
+	}
+	if err := s.ListEnd(); err != nil {
+		return decodeError(fmt.Errorf("rlp: expected %d elements but got more", typ.Len()))
+	}
+	return nil
+}
+
+func (s *Stream) decodeStruct(v reflect.Value) error {
+	fields, err := structFields(v.Type())
+	if err != nil {
+		return decodeError(err)
+	}
+	if _, err := s.List(); err != nil {
+		return decodeError(err)
+	}
+
+	for i, field := range fields {
+		value := v.Field(field.index)
+		if field.tail {
+			elems, err := s.decodeElements(value.Type())
+			if err != nil {
+				return addPath(err, "."+field.name)
+			}
+			value.Set(elems)
+			continue
+		}
+
+		if _, _, err := s.Kind(); err == EOL {
+			if !field.optional {
+				return decodeError(fmt.Errorf("rlp: too few elements, missing %s", field.name))
+			}
+			//the rest of the fields are optional, so are left as zero
+			for _, missing := range fields[i:] {
+				v.Field(missing.index).Set(reflect.Zero(v.Field(missing.index).Type()))
+			}
+			break
+		}
+		if err := s.decode(value); err != nil {
+			return addPath(err, "."+field.name)
+		}
+	}
+
+	if err := s.ListEnd(); err != nil {
+		return decodeError(fmt.Errorf("rlp: too many elements for %v", v.Type()))
+	}
+	return nil
+}
+
+//decodeInterface decodes a value as a []byte or a []interface{}
+func (s *Stream) decodeInterface() (interface{}, error) {
+	kind, _, err := s.Kind()
+	if err != nil {
+		return nil, err
+	}
+	if kind != List {
+		return s.Bytes()
+	}
+
+	if _, err := s.List(); err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for {
+		if _, _, err := s.Kind(); err == EOL {
+			break
+		}
+		value, err := s.decodeInterface()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, s.ListEnd()
+}
+
+//uint reads an unsigned integer that must fit in the number of bits
+func (s *Stream) uint(maxBits int) (uint64, error) {
+	kind, size, err := s.Kind()
+	if err != nil {
+		return 0, err
+	}
+	if kind == String && size > uint64(maxBits+7)/8 {
+		return 0, ErrUintOverflow
+	}
+	b, err := s.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	if len(b) > 0 && b[0] == 0 {
+		return 0, ErrCanonInt
+	}
+
+	var i uint64
+	for _, c := range b {
+		i = i<<8 | uint64(c)
+	}
+	if maxBits < 64 && i >= 1<<uint(maxBits) {
+		return 0, ErrUintOverflow
+	}
+	return i, nil
+}
+
+//readKind reads the header of the next value
+func (s *Stream) readKind() (Kind, uint64, error) {
+	if err := s.consume(1); err != nil {
+		return 0, 0, err
+	}
+	if _, err := io.ReadFull(s.r, s.buf[:1]); err != nil {
+		//running out of input is only unexpected in a list, or when the input should have been longer
+		if err == io.EOF && (len(s.lists) > 0 || s.limited) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, 0, err
+	}
+
+	b := s.buf[0]
+	switch {
+	case b < 0x80:
+		s.byteval = b
+		return Byte, 0, nil
+	case b < 0xb8:
+		return String, uint64(b - 0x80), nil
+	case b < 0xc0:
+		size, err := s.readSize(b - 0xb7)
+		return String, size, err
+	case b < 0xf8:
+		return List, uint64(b - 0xc0), nil
+	default:
+		size, err := s.readSize(b - 0xf7)
+		return List, size, err
+	}
+}
+
+//readSize reads the size of a long string or list, which must have no leading zeros and be too large for the short
+//form
+func (s *Stream) readSize(length byte) (uint64, error) {
+	buf := s.buf[:length]
+	if err := s.readFull(buf); err != nil {
+		return 0, err
+	}
+	if buf[0] == 0 {
+		return 0, ErrCanonSize
+	}
+	var size uint64
+	for _, b := range buf {
+		size = size<<8 | uint64(b)
+	}
+	if size < 56 {
+		return 0, ErrCanonSize
+	}
+	return size, nil
+}
+
+//readBytes reads the content of a string or list. The size has been checked against the input limit, but the input
+//may be unlimited, so large values are only allocated as they are read
+func (s *Stream) readBytes(size uint64) ([]byte, error) {
+	if size <= 1<<16 {
+		b := make([]byte, size)
+		return b, s.readFull(b)
+	}
+	if err := s.consume(size); err != nil {
+		return nil, err
+	}
+	if size > math.MaxInt64 {
+		return nil, ErrValueTooLarge
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(s.r, int64(size)))
+	if err == nil && uint64(len(b)) < size {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+//readFull reads exactly enough bytes to fill buf
+func (s *Stream) readFull(buf []byte) error {
+	if err := s.consume(uint64(len(buf))); err != nil {
+		return err
+	}
+	n, err := io.ReadFull(s.r, buf)
+	if err == io.EOF || (err == nil && n < len(buf)) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+//consume accounts for bytes that are about to be read from the input and the current list
+func (s *Stream) consume(n uint64) error {
+	if n > s.remaining {
+		return io.ErrUnexpectedEOF
+	}
+	if len(s.lists) > 0 {
+		if n > s.lists[len(s.lists)-1] {
+			return ErrElemTooLarge
+		}
+		s.lists[len(s.lists)-1] -= n
+	}
+	s.remaining -= n
+	return nil
+}
+
+//decodeError gives the error as a *DecodeError, whose path is filled in as it is returned from the values it is in
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	return &DecodeError{Err: err}
+}
+
+//addPath adds the field or element that an error is in to the start of its path
+func addPath(err error, segment string) error {
+	decodeErr := decodeError(err).(*DecodeError)
+	decodeErr.Path = segment + decodeErr.Path
+	return decodeErr
+}
@@ -0,0 +1,239 @@
+package rlp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type selfDecoded struct {
+	value uint64
+}
+
+func (d *selfDecoded) DecodeRLP(s *Stream) error {
+	if _, err := s.List(); err != nil {
+		return err
+	}
+	value, err := s.Uint64()
+	if err != nil {
+		return err
+	}
+	d.value = value
+	return s.ListEnd()
+}
+
+func TestDecodeBytes(t *testing.T) {
+	bigNumber, _ := new(big.Int).SetString("10000000000000000", 16)
+
+	testMatrix := []struct {
+		input    string
+		ptr      interface{}
+		expected interface{}
+	}{
+		{"80", new(string), ""},
+		{"83646f67", new(string), "dog"},
+		{"c88363617483646f67", new([]string), []string{"cat", "dog"}},
+		{"c0", new([]string), []string{}},
+		{"80", new(uint64), uint64(0)},
+		{"0f", new(uint64), uint64(15)},
+		{"820400", new(uint64), uint64(1024)},
+		{"c7c0c1c0c3c0c1c0", new(interface{}), []interface{}{[]interface{}{}, []interface{}{[]interface{}{}}, []interface{}{[]interface{}{}, []interface{}{[]interface{}{}}}}},
+		{"b838" + strings.Repeat("61", 56), new([]byte), []byte(strings.Repeat("a", 56))},
+
+		{"00", new([]byte), []byte{0}},
+		{"01", new(bool), true},
+		{"80", new(bool), false},
+		{"8180", new(uint8), uint8(0x80)},
+		{"84ffffffff", new(uint32), uint32(0xffffffff)},
+		{"89010000000000000000", new(*big.Int), bigNumber},
+		{"820400", new(big.Int), *big.NewInt(1024)},
+		{"83010203", new([3]byte), [3]byte{1, 2, 3}},
+		{"c20102", new([2]uint64), [2]uint64{1, 2}},
+		{"c20102", new(RawValue), RawValue(unhex("c20102"))},
+		{"c201c0", new([]RawValue), []RawValue{unhex("01"), unhex("c0")}},
+		{"c3c20102", new([]RawValue), []RawValue{unhex("c20102")}},
+		{"c101", new(selfDecoded), selfDecoded{value: 1}},
+		{"c20102", new(*[]uint64), &[]uint64{1, 2}},
+		{"83646f67", new(interface{}), []byte("dog")},
+		{"c50183646f67", new(simpleStruct), simpleStruct{A: 1, B: "dog"}},
+		{"c101", new(optionalStruct), optionalStruct{A: 1}},
+		{"c20102", new(optionalStruct), optionalStruct{A: 1, B: 2}},
+		{"c3018003", new(optionalStruct), optionalStruct{A: 1, C: []byte{3}}},
+		{"c101", new(tailStruct), tailStruct{A: 1, Tail: []uint64{}}},
+		{"c3010203", new(tailStruct), tailStruct{A: 1, Tail: []uint64{2, 3}}},
+		{"c20103", new(ignoredStruct), ignoredStruct{A: 1, B: 3}},
+	}
+
+	for idx, test := range testMatrix {
+		err := DecodeBytes(unhex(test.input), test.ptr)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, reflect.ValueOf(test.ptr).Elem().Interface(), "Test index %d failed", idx)
+	}
+}
+
+func TestDecodeBytes_Errors(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		ptr           interface{}
+		expectedError string
+	}{
+		//non-canonical encodings
+		{"8100", new([]byte), "rlp: non-canonical size information, decoding into []uint8"},
+		{"817f", new(uint64), "rlp: non-canonical size information, decoding into uint64"},
+		{"820001", new(uint64), "rlp: non-canonical integer (leading zero bytes), decoding into uint64"},
+		{"00", new(uint64), "rlp: non-canonical integer (leading zero bytes), decoding into uint64"},
+		{"820001", new(big.Int), "rlp: non-canonical integer (leading zero bytes), decoding into big.Int"},
+		{"b803616263", new([]byte), "rlp: non-canonical size information, decoding into []uint8"},
+		{"b90038" + strings.Repeat("61", 56), new([]byte), "rlp: non-canonical size information, decoding into []uint8"},
+		{"f803010203", new([]uint64), "rlp: non-canonical size information, decoding into []uint64"},
+		{"c28100", new(RawValue), "rlp: non-canonical size information, decoding into rlp.RawValue"},
+
+		//sizes that don't match the input
+		{"83646f", new(string), "rlp: value size exceeds available input length, decoding into string"},
+		{"c2820400", new([]uint64), "rlp: element is larger than containing list, decoding into []uint64[0]"},
+		{"8180", new(uint64), ""},
+		{"83646f6767", new(string), "rlp: input contains more than one value"},
+		{"", new(string), "EOF"},
+
+		//values that don't fit the type
+		{"820100", new(uint8), "rlp: uint overflow, decoding into uint8"},
+		{"89010000000000000000", new(uint64), "rlp: uint overflow, decoding into uint64"},
+		{"02", new(bool), "rlp: invalid boolean value 2, decoding into bool"},
+		{"c0", new(string), "rlp: expected string or byte, decoding into string"},
+		{"80", new([]uint64), "rlp: expected list, decoding into []uint64"},
+		{"820102", new([3]byte), "rlp: expected 3 bytes but got 2, decoding into [3]uint8"},
+		{"c101", new([2]uint64), "rlp: expected 2 elements but got 1, decoding into [2]uint64"},
+		{"c3010203", new([2]uint64), "rlp: expected 2 elements but got more, decoding into [2]uint64"},
+		{"c20180", new([]string), ""},
+		{"c2c080", new([][]uint64), "rlp: expected list, decoding into [][]uint64[1]"},
+		{"c60183646f67c0", new(simpleStruct), "rlp: too many elements for rlp.simpleStruct, decoding into rlp.simpleStruct"},
+		{"c101", new(simpleStruct), "rlp: too few elements, missing B, decoding into rlp.simpleStruct"},
+		{"c201c0", new(simpleStruct), "rlp: expected string or byte, decoding into rlp.simpleStruct.B"},
+		{"c0", new(requiredAfterOptional), `rlp: field rlp.requiredAfterOptional.B must have tag "optional" as it follows an optional field, decoding into rlp.requiredAfterOptional`},
+		{"01", new(int), "rlp: type int is not RLP-serializable, decoding into int"},
+		{"c3820001", new(selfDecoded), "rlp: non-canonical integer (leading zero bytes), decoding into rlp.selfDecoded"},
+	}
+
+	for idx, test := range testMatrix {
+		err := DecodeBytes(unhex(test.input), test.ptr)
+
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestDecodeBytes_InvalidPointer(t *testing.T) {
+	var value uint64
+
+	assert.EqualError(t, DecodeBytes(unhex("01"), value), "rlp: Decode requires a non-nil pointer, not uint64")
+	assert.EqualError(t, DecodeBytes(unhex("01"), nil), "rlp: Decode requires a non-nil pointer, not <nil>")
+}
+
+func TestDecodeError_Unwrap(t *testing.T) {
+	var value uint64
+	err := DecodeBytes(unhex("820001"), &value)
+
+	assert.True(t, errors.Is(err, ErrCanonInt))
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	testMatrix := []interface{}{
+		&simpleStruct{A: 1<<64 - 1, B: strings.Repeat("dog", 100)},
+		&[][]uint64{{}, {1, 2}, {1 << 40}},
+		&tailStruct{A: 1, Tail: []uint64{2, 3, 4}},
+		&optionalStruct{A: 1, B: 0, C: []byte("cat")},
+	}
+
+	for idx, test := range testMatrix {
+		encoded, err := EncodeToBytes(test)
+		assert.Nil(t, err, "Test index %d failed", idx)
+
+		decoded := reflect.New(reflect.TypeOf(test).Elem())
+		err = DecodeBytes(encoded, decoded.Interface())
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test, decoded.Interface(), "Test index %d failed", idx)
+	}
+}
+
+func TestStream(t *testing.T) {
+	s := NewStream(bytes.NewReader(unhex("c88363617483646f6701")), 0)
+
+	kind, size, err := s.Kind()
+	assert.Nil(t, err)
+	assert.Equal(t, List, kind)
+	assert.Equal(t, uint64(8), size)
+
+	_, err = s.List()
+	assert.Nil(t, err)
+	var values []string
+	for {
+		b, err := s.Bytes()
+		if err == EOL {
+			break
+		}
+		assert.Nil(t, err)
+		values = append(values, string(b))
+	}
+	assert.Nil(t, s.ListEnd())
+	assert.Equal(t, []string{"cat", "dog"}, values)
+
+	i, err := s.Uint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), i)
+
+	_, _, err = s.Kind()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestStream_ListEnd(t *testing.T) {
+	s := NewStream(bytes.NewReader(unhex("c20102")), 0)
+
+	_, err := s.List()
+	assert.Nil(t, err)
+	_, err = s.Uint64()
+	assert.Nil(t, err)
+
+	assert.Equal(t, ErrNotAtEOL, s.ListEnd())
+}
+
+func TestStream_UnlimitedInput(t *testing.T) {
+	//a reader whose length isn't known
+	r := io.MultiReader(bytes.NewReader(unhex("0102")), bytes.NewReader(unhex("83646f")))
+	s := NewStream(r, 0)
+
+	var value uint64
+	assert.Nil(t, s.Decode(&value))
+	assert.Equal(t, uint64(1), value)
+	assert.Nil(t, s.Decode(&value))
+	assert.Equal(t, uint64(2), value)
+
+	var str string
+	assert.EqualError(t, s.Decode(&str), "unexpected EOF, decoding into string")
+}
+
+func TestStream_TooDeep(t *testing.T) {
+	w := NewWriter(nil)
+	for i := 0; i <= MaxListDepth; i++ {
+		w.List()
+	}
+	for i := 0; i <= MaxListDepth; i++ {
+		assert.Nil(t, w.ListEnd())
+	}
+	input := w.buf
+
+	var value interface{}
+	err := DecodeBytes(input, &value)
+
+	assert.True(t, errors.Is(err, ErrTooDeep))
+}
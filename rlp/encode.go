@@ -0,0 +1,314 @@
+/*
+Package rlp encodes and decodes the Recursive Length Prefix (RLP) serialization used by Ethereum for transactions,
+block headers and contract addresses, as defined in the yellow paper.
+
+RLP only has byte strings and lists, so Go values are mapped onto them as follows:
+
+	bool                   the integer 0 or 1
+	uint8 to uint64, uint  a big endian integer without leading zeros, where 0 is the empty string
+	*big.Int, big.Int      the same as an unsigned integer, negative integers can't be encoded
+	string, []byte         a byte string
+	[n]byte                a byte string of exactly n bytes
+	other slices, arrays   a list of their elements
+	structs                a list of their exported fields, in order
+	pointers               the value pointed to, where nil is an empty string or list depending on the type
+	interface{}            when decoding, a []byte for a string or []interface{} for a list
+	RawValue               an already encoded value, which is copied as it is
+
+Types can encode and decode themselves by implementing Encoder and Decoder, as types.Address, types.Hash,
+types.HexData and types.HexBig do. Signed integers have no encoding, so are rejected.
+
+The fields of a struct can be tagged to change how they are encoded:
+
+	rlp:"-"         the field is ignored
+	rlp:"optional"  the field can be missing from the end of the list, in which case it is left as its zero value,
+	                and it is left out when encoding if it and all of the fields after it are zero. Only optional
+	                fields can follow an optional field
+	rlp:"tail"      the field must be the last field and a slice, which holds the rest of the elements of the list
+	                rather than being a list of its own
+
+Decoding is strict, so input that isn't in canonical form, such as integers with leading zeros or sizes that could
+have used a shorter encoding, is rejected, as is a value that is larger than the list or input that holds it.
+*/
+package rlp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"reflect"
+)
+
+// Encoder is implemented by types that encode themselves as RLP. EncodeRLP must write exactly one value to w, which
+// is a *Writer when called by this package, so that its methods or Encode can be used to write the value
+type Encoder interface {
+	EncodeRLP(w io.Writer) error
+}
+
+// RawValue is a value that is already RLP encoded. It is written as it is when encoding, and holds the encoding of
+// the value when decoding
+type RawValue []byte
+
+var (
+	// EmptyString is the encoding of an empty byte string, which is also the encoding of zero
+	EmptyString = []byte{0x80}
+	// EmptyList is the encoding of an empty list
+	EmptyList = []byte{0xc0}
+)
+
+var (
+	encoderType   = reflect.TypeOf((*Encoder)(nil)).Elem()
+	rawValueType  = reflect.TypeOf(RawValue{})
+	bigIntType    = reflect.TypeOf(big.Int{})
+	emptyListByte = EmptyList[0]
+)
+
+// Encode writes the RLP encoding of the value to w
+func Encode(w io.Writer, val interface{}) error {
+	if writer, ok := w.(*Writer); ok {
+		return writer.Encode(val)
+	}
+	writer := NewWriter(w)
+	if err := writer.Encode(val); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// EncodeToBytes gives the RLP encoding of the value
+func EncodeToBytes(val interface{}) ([]byte, error) {
+	writer := NewWriter(nil)
+	if err := writer.Encode(val); err != nil {
+		return nil, err
+	}
+	return writer.buf, nil
+}
+
+/*
+Writer encodes a sequence of values, which are written to the underlying writer by Flush.
+
+Values are either written directly, with the Write methods, or encoded with Encode. A list is written by calling
+List, writing its elements, and then calling ListEnd. The size of a list is only known once it has ended, so values
+are buffered until Flush is called, which must be after all lists have been ended.
+*/
+type Writer struct {
+	out   io.Writer
+	buf   []byte
+	lists []int //the positions in buf where the elements of the open lists start
+}
+
+// NewWriter creates a Writer that writes to out when it is flushed
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{out: out}
+}
+
+// Write writes data that is already RLP encoded, so that an Encoder can write a value it has encoded itself
+func (w *Writer) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// WriteBytes writes a byte string
+func (w *Writer) WriteBytes(b []byte) {
+	if len(b) == 1 && b[0] < 0x80 {
+		w.buf = append(w.buf, b[0])
+		return
+	}
+	w.buf = appendHeader(w.buf, 0x80, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+// WriteUint64 writes an unsigned integer
+func (w *Writer) WriteUint64(i uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], i)
+	w.WriteBytes(b[bits.LeadingZeros64(i)/8:])
+}
+
+// WriteBigInt writes a non-negative integer, where nil is zero
+func (w *Writer) WriteBigInt(i *big.Int) error {
+	if i == nil {
+		w.WriteUint64(0)
+		return nil
+	}
+	if i.Sign() < 0 {
+		return fmt.Errorf("rlp: cannot encode negative integer %s", i.String())
+	}
+	w.WriteBytes(i.Bytes())
+	return nil
+}
+
+// WriteBool writes a boolean as the integer 0 or 1
+func (w *Writer) WriteBool(b bool) {
+	if b {
+		w.WriteUint64(1)
+	} else {
+		w.WriteUint64(0)
+	}
+}
+
+// List starts a list, which holds the values that are written until the matching call to ListEnd
+func (w *Writer) List() {
+	w.lists = append(w.lists, len(w.buf))
+}
+
+// ListEnd ends the list that was started most recently
+func (w *Writer) ListEnd() error {
+	if len(w.lists) == 0 {
+		return errors.New("rlp: ListEnd called without a matching List")
+	}
+	start := w.lists[len(w.lists)-1]
+	w.lists = w.lists[:len(w.lists)-1]
+
+	//the header goes before the elements, which are moved along to make room for it
+	header := appendHeader(nil, emptyListByte, uint64(len(w.buf)-start))
+	w.buf = append(w.buf, header...)
+	copy(w.buf[start+len(header):], w.buf[start:len(w.buf)-len(header)])
+	copy(w.buf[start:], header)
+	return nil
+}
+
+// Flush writes the values that have been written so far to the underlying writer
+func (w *Writer) Flush() error {
+	if len(w.lists) > 0 {
+		return errors.New("rlp: Flush called before all lists were ended")
+	}
+	_, err := w.out.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Encode writes the value, see the package documentation for how Go values are encoded. If the value can't be
+// encoded, nothing is written
+func (w *Writer) Encode(val interface{}) error {
+	size, lists := len(w.buf), len(w.lists)
+	if err := w.encode(reflect.ValueOf(val)); err != nil {
+		w.buf, w.lists = w.buf[:size], w.lists[:lists]
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) encode(v reflect.Value) error {
+	//a nil interface{}
+	if !v.IsValid() {
+		w.buf = append(w.buf, emptyListByte)
+		return nil
+	}
+
+	typ := v.Type()
+	switch {
+	case typ == rawValueType:
+		w.buf = append(w.buf, v.Bytes()...)
+		return nil
+	case typ.Implements(encoderType):
+		if typ.Kind() == reflect.Ptr && v.IsNil() {
+			w.encodeEmpty(typ.Elem())
+			return nil
+		}
+		return v.Interface().(Encoder).EncodeRLP(w)
+	case v.CanAddr() && reflect.PtrTo(typ).Implements(encoderType):
+		return v.Addr().Interface().(Encoder).EncodeRLP(w)
+	case typ == bigIntType:
+		i := v.Interface().(big.Int)
+		return w.WriteBigInt(&i)
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			w.buf = append(w.buf, emptyListByte)
+			return nil
+		}
+		return w.encode(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			w.encodeEmpty(typ.Elem())
+			return nil
+		}
+		return w.encode(v.Elem())
+	case reflect.Bool:
+		w.WriteBool(v.Bool())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.WriteUint64(v.Uint())
+	case reflect.String:
+		w.WriteBytes([]byte(v.String()))
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			w.WriteBytes(b)
+			return nil
+		}
+		w.List()
+		for i := 0; i < v.Len(); i++ {
+			if err := w.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return w.ListEnd()
+	case reflect.Struct:
+		return w.encodeStruct(v)
+	default:
+		return fmt.Errorf("rlp: type %v is not RLP-serializable", typ)
+	}
+	return nil
+}
+
+func (w *Writer) encodeStruct(v reflect.Value) error {
+	fields, err := structFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	//optional fields at the end are left out if they are zero
+	end := len(fields)
+	for end > 0 && fields[end-1].optional && v.Field(fields[end-1].index).IsZero() {
+		end--
+	}
+
+	w.List()
+	for _, field := range fields[:end] {
+		value := v.Field(field.index)
+		if !field.tail {
+			if err := w.encode(value); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := w.encode(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return w.ListEnd()
+}
+
+//encodeEmpty writes a nil pointer to the type, which is an empty list if the type is encoded as a list, and an
+//empty string otherwise
+func (w *Writer) encodeEmpty(typ reflect.Type) {
+	switch {
+	case typ.Kind() == reflect.Struct && typ != bigIntType,
+		(typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() != reflect.Uint8:
+		w.buf = append(w.buf, emptyListByte)
+	default:
+		w.buf = append(w.buf, EmptyString[0])
+	}
+}
+
+//appendHeader appends the header of a string, with an offset of 0x80, or list, with an offset of 0xc0, whose
+//content is the given size
+func appendHeader(buf []byte, offset byte, size uint64) []byte {
+	if size < 56 {
+		return append(buf, offset+byte(size))
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], size)
+	sizeBytes := b[bits.LeadingZeros64(size)/8:]
+	buf = append(buf, offset+55+byte(len(sizeBytes)))
+	return append(buf, sizeBytes...)
+}
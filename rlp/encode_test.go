@@ -0,0 +1,196 @@
+package rlp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type simpleStruct struct {
+	A uint64
+	B string
+}
+
+type optionalStruct struct {
+	A uint64
+	B uint64 `rlp:"optional"`
+	C []byte `rlp:"optional"`
+}
+
+type tailStruct struct {
+	A    uint64
+	Tail []uint64 `rlp:"tail"`
+}
+
+type ignoredStruct struct {
+	A       uint64
+	Ignored string `rlp:"-"`
+	private uint64
+	B       uint64
+}
+
+type tailNotLast struct {
+	A []uint64 `rlp:"tail"`
+	B uint64
+}
+
+type tailNotSlice struct {
+	A uint64 `rlp:"tail"`
+}
+
+type requiredAfterOptional struct {
+	A uint64 `rlp:"optional"`
+	B uint64
+}
+
+type unknownTag struct {
+	A uint64 `rlp:"nil"`
+}
+
+type selfEncoded struct{}
+
+func (selfEncoded) EncodeRLP(w io.Writer) error {
+	_, err := w.Write([]byte{0x82, 0x01, 0x02})
+	return err
+}
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestEncode(t *testing.T) {
+	bigNumber, _ := new(big.Int).SetString("10000000000000000", 16)
+	var nilStruct *simpleStruct
+	var nilBytes *[]byte
+	var nilInterface interface{}
+
+	testMatrix := []struct {
+		val      interface{}
+		expected string
+	}{
+		//the examples in the yellow paper and wiki
+		{"", "80"},
+		{"dog", "83646f67"},
+		{[]string{"cat", "dog"}, "c88363617483646f67"},
+		{[]string{}, "c0"},
+		{uint64(0), "80"},
+		{[]byte{0}, "00"},
+		{uint64(15), "0f"},
+		{uint64(1024), "820400"},
+		{[]interface{}{[]interface{}{}, []interface{}{[]interface{}{}}, []interface{}{[]interface{}{}, []interface{}{[]interface{}{}}}}, "c7c0c1c0c3c0c1c0"},
+		{"Lorem ipsum dolor sit amet, consectetur adipisicing elit", "b8384c6f72656d20697073756d20646f6c6f722073697420616d65742c20636f6e7365637465747572206164697069736963696e6720656c6974"},
+
+		{true, "01"},
+		{false, "80"},
+		{uint8(0x7f), "7f"},
+		{uint8(0x80), "8180"},
+		{uint(0xffffffff), "84ffffffff"},
+		{big.NewInt(0), "80"},
+		{big.NewInt(127), "7f"},
+		{*big.NewInt(1024), "820400"},
+		{bigNumber, "89010000000000000000"},
+		{[3]byte{1, 2, 3}, "83010203"},
+		{[2]uint64{1, 2}, "c20102"},
+		{[]byte(strings.Repeat("a", 55)), "b7" + strings.Repeat("61", 55)},
+		{[]string{strings.Repeat("a", 55)}, "f838b7" + strings.Repeat("61", 55)},
+		{RawValue(unhex("c20102")), "c20102"},
+		{[]RawValue{unhex("01"), unhex("c0")}, "c201c0"},
+		{selfEncoded{}, "820102"},
+		{&selfEncoded{}, "820102"},
+		{nilInterface, "c0"},
+		{nilStruct, "c0"},
+		{nilBytes, "80"},
+		{(*big.Int)(nil), "80"},
+		{&simpleStruct{A: 1, B: "dog"}, "c50183646f67"},
+		{optionalStruct{A: 1}, "c101"},
+		{optionalStruct{A: 1, B: 2}, "c20102"},
+		//an optional field that is zero is still written if a later one isn't
+		{optionalStruct{A: 1, C: []byte{3}}, "c3018003"},
+		{tailStruct{A: 1}, "c101"},
+		{tailStruct{A: 1, Tail: []uint64{2, 3}}, "c3010203"},
+		{ignoredStruct{A: 1, Ignored: "dog", private: 2, B: 3}, "c20103"},
+	}
+
+	for idx, test := range testMatrix {
+		result, err := EncodeToBytes(test.val)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, hex.EncodeToString(result), "Test index %d failed", idx)
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	testMatrix := []struct {
+		val           interface{}
+		expectedError string
+	}{
+		{int64(1), "rlp: type int64 is not RLP-serializable"},
+		{[]int{1}, "rlp: type int is not RLP-serializable"},
+		{map[string]string{}, "rlp: type map[string]string is not RLP-serializable"},
+		{big.NewInt(-1), "rlp: cannot encode negative integer -1"},
+		{tailNotLast{}, `rlp: field rlp.tailNotLast.A with tag "tail" must be the last field`},
+		{tailNotSlice{}, `rlp: field rlp.tailNotSlice.A with tag "tail" must be a slice`},
+		{requiredAfterOptional{}, `rlp: field rlp.requiredAfterOptional.B must have tag "optional" as it follows an optional field`},
+		{unknownTag{}, `rlp: unknown tag "nil" on field rlp.unknownTag.A`},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := EncodeToBytes(test.val)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestEncode_ToWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Encode(&buf, []uint64{1, 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, unhex("c20102"), buf.Bytes())
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.List()
+	w.WriteUint64(1024)
+	w.WriteBytes([]byte("dog"))
+	w.List()
+	w.WriteBool(true)
+	assert.Nil(t, w.WriteBigInt(big.NewInt(0)))
+	assert.Nil(t, w.ListEnd())
+	assert.Nil(t, w.Encode(simpleStruct{A: 1, B: "cat"}))
+	assert.NotNil(t, w.Encode(int8(1)))
+
+	assert.EqualError(t, w.Flush(), "rlp: Flush called before all lists were ended")
+	assert.Nil(t, w.ListEnd())
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, "d082040083646f67c20180c50183636174", hex.EncodeToString(buf.Bytes()))
+
+	assert.EqualError(t, w.ListEnd(), "rlp: ListEnd called without a matching List")
+}
+
+func TestWriter_LongList(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.List()
+	for i := 0; i < 60; i++ {
+		w.WriteUint64(1)
+	}
+	assert.Nil(t, w.ListEnd())
+	assert.Nil(t, w.Flush())
+
+	assert.Equal(t, "f83c"+strings.Repeat("01", 60), hex.EncodeToString(buf.Bytes()))
+}
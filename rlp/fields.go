@@ -0,0 +1,68 @@
+package rlp
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//structField is an exported field of a struct that is encoded, along with the options from its tag
+type structField struct {
+	index    int
+	name     string
+	optional bool
+	tail     bool
+}
+
+type cachedFields struct {
+	fields []structField
+	err    error
+}
+
+//fieldCache holds the fields of each struct type that has been encoded or decoded
+var fieldCache sync.Map
+
+func structFields(typ reflect.Type) ([]structField, error) {
+	if cached, ok := fieldCache.Load(typ); ok {
+		return cached.(cachedFields).fields, cached.(cachedFields).err
+	}
+	fields, err := parseFields(typ)
+	fieldCache.Store(typ, cachedFields{fields: fields, err: err})
+	return fields, err
+}
+
+//parseFields finds the fields of a struct type that are encoded, checking that their tags are valid
+func parseFields(typ reflect.Type) ([]structField, error) {
+	var fields []structField
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		field := structField{index: i, name: f.Name}
+		switch tag := f.Tag.Get("rlp"); tag {
+		case "":
+		case "-":
+			continue
+		case "optional":
+			field.optional = true
+		case "tail":
+			if i != typ.NumField()-1 {
+				return nil, fmt.Errorf(`rlp: field %v.%s with tag "tail" must be the last field`, typ, f.Name)
+			}
+			if f.Type.Kind() != reflect.Slice {
+				return nil, fmt.Errorf(`rlp: field %v.%s with tag "tail" must be a slice`, typ, f.Name)
+			}
+			field.tail = true
+		default:
+			return nil, fmt.Errorf("rlp: unknown tag %q on field %v.%s", tag, typ, f.Name)
+		}
+
+		if len(fields) > 0 && fields[len(fields)-1].optional && !field.optional {
+			return nil, fmt.Errorf(`rlp: field %v.%s must have tag "optional" as it follows an optional field`, typ, f.Name)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
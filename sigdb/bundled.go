@@ -0,0 +1,89 @@
+package sigdb
+
+//bundledSignatures are the signatures loaded by NewBundled, in the format described in the package documentation.
+//Events mark their indexed arguments where the standard defines them, so that they don't need to be guessed
+const bundledSignatures = `
+# ERC-20
+totalSupply()
+balanceOf(address)
+transfer(address,uint256)
+transferFrom(address,address,uint256)
+approve(address,uint256)
+allowance(address,address)
+name()
+symbol()
+decimals()
+increaseAllowance(address,uint256)
+decreaseAllowance(address,uint256)
+event Transfer(address indexed,address indexed,uint256)
+event Approval(address indexed,address indexed,uint256)
+
+# ERC-20 burnable and mintable extensions
+mint(address,uint256)
+burn(uint256)
+burnFrom(address,uint256)
+
+# ERC-2612
+permit(address,address,uint256,uint256,uint8,bytes32,bytes32)
+nonces(address)
+DOMAIN_SEPARATOR()
+
+# ERC-721
+ownerOf(uint256)
+safeTransferFrom(address,address,uint256)
+safeTransferFrom(address,address,uint256,bytes)
+setApprovalForAll(address,bool)
+getApproved(uint256)
+isApprovedForAll(address,address)
+tokenURI(uint256)
+supportsInterface(bytes4)
+event Transfer(address indexed,address indexed,uint256 indexed)
+event Approval(address indexed,address indexed,uint256 indexed)
+event ApprovalForAll(address indexed,address indexed,bool)
+
+# ERC-1155
+balanceOfBatch(address[],uint256[])
+safeTransferFrom(address,address,uint256,uint256,bytes)
+safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)
+uri(uint256)
+event TransferSingle(address indexed,address indexed,address indexed,uint256,uint256)
+event TransferBatch(address indexed,address indexed,address indexed,uint256[],uint256[])
+event URI(string,uint256 indexed)
+
+# WETH
+deposit()
+withdraw(uint256)
+event Deposit(address indexed,uint256)
+event Withdrawal(address indexed,uint256)
+
+# Ownable and access control
+owner()
+transferOwnership(address)
+renounceOwnership()
+hasRole(bytes32,address)
+grantRole(bytes32,address)
+revokeRole(bytes32,address)
+renounceRole(bytes32,address)
+getRoleAdmin(bytes32)
+event OwnershipTransferred(address indexed,address indexed)
+event RoleGranted(bytes32 indexed,address indexed,address indexed)
+event RoleRevoked(bytes32 indexed,address indexed,address indexed)
+
+# Pausable
+pause()
+unpause()
+paused()
+event Paused(address)
+event Unpaused(address)
+
+# Proxies
+upgradeTo(address)
+upgradeToAndCall(address,bytes)
+implementation()
+event Upgraded(address indexed)
+event AdminChanged(address,address)
+
+# Multicall
+multicall(bytes[])
+aggregate((address,bytes)[])
+`
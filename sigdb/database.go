@@ -0,0 +1,389 @@
+/*
+Package sigdb is an offline database of function and event signatures, for decoding transactions and logs from
+contracts whose ABI isn't known.
+
+Signatures are loaded from text with one signature per line, such as:
+
+	# ERC-20
+	transfer(address,uint256)
+	function approve(address spender, uint256 amount)
+	event Transfer(address indexed,address indexed,uint256)
+	event Approval(address,address,uint256)
+
+Lines without a keyword are functions, blank lines and lines starting with "#" are ignored, and argument names are
+optional. Selectors and topics are always calculated from the signature, so aliases such as "uint" are fine.
+
+A signature only identifies a function or event by its hash, so a selector or topic can have many candidates. Each
+candidate is tried in turn, and only those whose arguments are exactly encoded by the data are kept (see
+types.DecodeAllDataStrict). Since the topics of an event don't say which arguments are indexed, an event signature
+without any indexed arguments is tried with every choice of arguments that fits the number of topics, whereas one
+that marks its indexed arguments is only tried as given.
+*/
+package sigdb
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ConsenSys/quorum-go-utils/types"
+)
+
+// Database holds function signatures by selector and event signatures by topic. It is safe for concurrent use
+type Database struct {
+	mu        sync.RWMutex
+	known     map[string]bool
+	functions map[string][]types.ContractABIFunction
+	events    map[string][]types.ContractABIEvent
+}
+
+// New creates an empty database
+func New() *Database {
+	return &Database{
+		known:     make(map[string]bool),
+		functions: make(map[string][]types.ContractABIFunction),
+		events:    make(map[string][]types.ContractABIEvent),
+	}
+}
+
+// NewBundled creates a database holding the signatures bundled with this package, which cover
+// the common token standards and contracts
+func NewBundled() *Database {
+	db := New()
+	if err := db.Load(strings.NewReader(bundledSignatures)); err != nil {
+		panic("invalid bundled signatures: " + err.Error())
+	}
+	return db
+}
+
+// LoadFile adds the signatures from a file, see Load
+func (db *Database) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return db.Load(file)
+}
+
+// Load adds the signatures from the text, which has one signature per line. Nothing is added if any line is invalid
+func (db *Database) Load(r io.Reader) error {
+	var entries []types.ABIStructureEntry
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parseSignature(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", lineNumber, err.Error())
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, entry := range entries {
+		db.add(entry)
+	}
+	return nil
+}
+
+// Add adds a single function or event signature
+func (db *Database) Add(signature string) error {
+	entry, err := parseSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.add(entry)
+	return nil
+}
+
+//parseSignature parses a function or event signature, which must not have any outputs or modifiers
+func parseSignature(signature string) (types.ABIStructureEntry, error) {
+	entry, err := types.NewABIStructureEntryFromHumanReadable(signature)
+	if err != nil {
+		return entry, err
+	}
+	if entry.Type != "function" && entry.Type != "event" {
+		return entry, fmt.Errorf("invalid signature %q: only functions and events are supported", signature)
+	}
+	if entry.Anonymous {
+		return entry, fmt.Errorf("invalid signature %q: anonymous events have no signature topic", signature)
+	}
+	return entry, nil
+}
+
+//add adds the signature unless it is already known, ignoring any argument names since they don't change how
+//the data is decoded
+func (db *Database) add(entry types.ABIStructureEntry) {
+	for i := range entry.Inputs {
+		entry.Inputs[i].Name = ""
+	}
+
+	if entry.Type == "event" {
+		event := entry.AsEvent()
+		if key := eventSignature(event); !db.known[key] {
+			db.known[key] = true
+			db.events[event.Signature()] = append(db.events[event.Signature()], event)
+		}
+		return
+	}
+	function := entry.AsFunction()
+	if key := function.StringNoName(); !db.known[key] {
+		db.known[key] = true
+		db.functions[function.Signature()] = append(db.functions[function.Signature()], function)
+	}
+}
+
+// Functions gives the functions with the given 4 byte selector, as a hex string with or without "0x"
+func (db *Database) Functions(selector string) []types.ContractABIFunction {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]types.ContractABIFunction(nil), db.functions[normaliseHex(selector)]...)
+}
+
+// Events gives the events whose signature is the given topic
+func (db *Database) Events(topic types.Hash) []types.ContractABIEvent {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]types.ContractABIEvent(nil), db.events[normaliseHex(string(topic))]...)
+}
+
+// FunctionMatch is a function whose arguments are exactly encoded by some calldata
+type FunctionMatch struct {
+	Function types.ContractABIFunction
+	Values   types.DecodedValues
+}
+
+// DecodeCalldata decodes the calldata as each of the functions with its selector, keeping those whose
+// arguments are exactly encoded by the rest of the data
+func (db *Database) DecodeCalldata(calldata []byte) []*FunctionMatch {
+	if len(calldata) < 4 {
+		return nil
+	}
+
+	var matches []*FunctionMatch
+	for _, function := range db.Functions(hex.EncodeToString(calldata[:4])) {
+		values, err := types.DecodeAllDataStrict(function.Inputs, calldata[4:])
+		if err != nil {
+			continue
+		}
+		matches = append(matches, &FunctionMatch{Function: function, Values: values})
+	}
+	return matches
+}
+
+// EventMatch is an event whose arguments are exactly encoded by the topics and data of a log
+type EventMatch struct {
+	Event  types.ContractABIEvent
+	Values types.DecodedValues
+}
+
+// DecodeEvent decodes the log as each of the events with the signature given by its first topic, keeping those
+// whose arguments are exactly encoded by the other topics and the data. Anonymous events can't be looked up
+func (db *Database) DecodeEvent(topics []types.Hash, data []byte) []*EventMatch {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	var matches []*EventMatch
+	seen := make(map[string]bool)
+	for _, event := range db.Events(topics[0]) {
+		for _, candidate := range indexedChoices(event, len(topics)-1) {
+			//the same choice may come from a signature with its indexed arguments marked, and one without
+			if seen[eventSignature(candidate)] {
+				continue
+			}
+			values, err := decodeEventStrict(candidate, topics, data)
+			if err != nil {
+				continue
+			}
+			seen[eventSignature(candidate)] = true
+			matches = append(matches, &EventMatch{Event: candidate, Values: values})
+		}
+	}
+	return matches
+}
+
+//indexedChoices gives the ways the event could have been declared with the given number of indexed arguments.
+//An event that already has indexed arguments can only be declared as it is
+func indexedChoices(event types.ContractABIEvent, numberOfIndexed int) []types.ContractABIEvent {
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			return []types.ContractABIEvent{event}
+		}
+	}
+	if numberOfIndexed == 0 {
+		return []types.ContractABIEvent{event}
+	}
+
+	var choices []types.ContractABIEvent
+	var choose func(start int, remaining int, inputs []types.ContractABIEventArgument)
+	choose = func(start int, remaining int, inputs []types.ContractABIEventArgument) {
+		if remaining == 0 {
+			choice := event
+			choice.Inputs = append([]types.ContractABIEventArgument(nil), inputs...)
+			choices = append(choices, choice)
+			return
+		}
+		for i := start; i <= len(inputs)-remaining; i++ {
+			inputs[i].Indexed = true
+			choose(i+1, remaining-1, inputs)
+			inputs[i].Indexed = false
+		}
+	}
+	choose(0, numberOfIndexed, append([]types.ContractABIEventArgument(nil), event.Inputs...))
+	return choices
+}
+
+//decodeEventStrict decodes the log as the event, checking that the data is exactly the encoding of the arguments
+//that aren't indexed, and that the topics of indexed value types are exactly their encoding
+func decodeEventStrict(event types.ContractABIEvent, topics []types.Hash, data []byte) (types.DecodedValues, error) {
+	if !event.Matches(topics, data) {
+		return nil, fmt.Errorf("log does not match %s", event.StringNoName())
+	}
+
+	var nonIndexed []types.ContractABIArgument
+	topicIndex := 1
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, input.ContractABIArgument)
+			continue
+		}
+		topic := topics[topicIndex]
+		topicIndex++
+
+		//only the hash of anything other than a value type is in the topic, which could be anything
+		t, err := input.ParsedType()
+		if err != nil {
+			return nil, err
+		}
+		if t.IsDynamic() || t.Kind == types.ArrayKind || t.Kind == types.TupleKind {
+			continue
+		}
+		topicBytes, err := hex.DecodeString(normaliseHex(string(topic)))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := types.DecodeAllDataStrict([]types.ContractABIArgument{input.ContractABIArgument}, topicBytes); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := types.DecodeAllDataStrict(nonIndexed, data); err != nil {
+		return nil, err
+	}
+	return event.Decode(topics, data)
+}
+
+/*
+ParseTransaction fills in a transaction that couldn't be parsed with the ABI of its contract, which is one with an
+empty signature, using the functions from the database that decode its calldata exactly.
+
+A single match is given in Sig, ParsedData and DecodedData with ConfidenceSignature, where the arguments have no
+names so are keyed by position in ParsedData. Otherwise every match is given
+in Candidates with ConfidenceAmbiguous. Deployments and transactions that are already parsed are left as they are.
+*/
+func (db *Database) ParseTransaction(ptx *types.ParsedTransaction) error {
+	if ptx.RawTransaction == nil {
+		return fmt.Errorf("transaction is nil or invalid")
+	}
+	if ptx.Sig != "" || ptx.RawTransaction.To.IsEmpty() {
+		return nil
+	}
+
+	calldata := ptx.RawTransaction.Data.AsBytes()
+	if len(ptx.RawTransaction.PrivateData) > 0 {
+		calldata = ptx.RawTransaction.PrivateData.AsBytes()
+	}
+	if len(calldata) < 4 {
+		return nil
+	}
+	ptx.Func4Bytes = types.HexData(hex.EncodeToString(calldata[:4]))
+
+	matches := db.DecodeCalldata(calldata)
+	switch len(matches) {
+	case 0:
+		return nil
+	case 1:
+		ptx.Sig = matches[0].Function.StringNoName()
+		ptx.ParsedData = matches[0].Values.ToIndexedMap()
+		ptx.DecodedData = matches[0].Values
+		ptx.Confidence = types.ConfidenceSignature
+	default:
+		ptx.Candidates = nil
+		for _, match := range matches {
+			ptx.Candidates = append(ptx.Candidates, &types.ParsedTransactionCandidate{
+				Sig:         match.Function.StringNoName(),
+				ParsedData:  match.Values.ToIndexedMap(),
+				DecodedData: match.Values,
+			})
+		}
+		ptx.Confidence = types.ConfidenceAmbiguous
+	}
+	return nil
+}
+
+// ParseEvent fills in an event that couldn't be parsed with the ABI of its contract, in the same way as
+// ParseTransaction. The signature of an event is given with its indexed arguments marked, since these
+// may have been worked out from the topics
+func (db *Database) ParseEvent(pe *types.ParsedEvent) error {
+	if pe.RawEvent == nil {
+		return fmt.Errorf("event is nil or invalid")
+	}
+	if pe.Sig != "" || len(pe.Candidates) > 0 {
+		return nil
+	}
+
+	matches := db.DecodeEvent(pe.RawEvent.Topics, pe.RawEvent.Data.AsBytes())
+	switch len(matches) {
+	case 0:
+		return nil
+	case 1:
+		pe.Sig = eventSignature(matches[0].Event)
+		pe.ParsedData = matches[0].Values.ToIndexedMap()
+		pe.DecodedData = matches[0].Values
+		pe.Confidence = types.ConfidenceSignature
+	default:
+		for _, match := range matches {
+			pe.Candidates = append(pe.Candidates, &types.ParsedEventCandidate{
+				Sig:         eventSignature(match.Event),
+				ParsedData:  match.Values.ToIndexedMap(),
+				DecodedData: match.Values,
+			})
+		}
+		pe.Confidence = types.ConfidenceAmbiguous
+	}
+	return nil
+}
+
+//eventSignature gives the signature of the event with its indexed arguments marked,
+//e.g. "event Transfer(address indexed,address indexed,uint256)"
+func eventSignature(event types.ContractABIEvent) string {
+	inputs := make([]string, len(event.Inputs))
+	for i, input := range event.Inputs {
+		inputs[i] = input.StringNoName()
+		if input.Indexed {
+			inputs[i] += " indexed"
+		}
+	}
+	return fmt.Sprintf("event %s(%s)", event.Name, strings.Join(inputs, ","))
+}
+
+//normaliseHex gives the hex string in the form used as a key for selectors and topics
+func normaliseHex(hexString string) string {
+	return strings.ToLower(strings.TrimPrefix(hexString, "0x"))
+}
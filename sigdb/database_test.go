@@ -0,0 +1,223 @@
+package sigdb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ConsenSys/quorum-go-utils/types"
+	"github.com/stretchr/testify/assert"
+)
+
+//word left-pads the hex to a full 32 byte word
+func word(hexString string) string {
+	return fmt.Sprintf("%064s", hexString)
+}
+
+func calldata(hexString string) []byte {
+	b, _ := hex.DecodeString(hexString)
+	return b
+}
+
+func topic(t *testing.T, signature string) types.Hash {
+	entry, err := types.NewABIStructureEntryFromHumanReadable(signature)
+	assert.Nil(t, err)
+	return types.NewHash(entry.AsEvent().Signature())
+}
+
+func TestNewBundled(t *testing.T) {
+	db := NewBundled()
+
+	functions := db.Functions("0xa9059cbb")
+	if assert.Len(t, functions, 1) {
+		assert.Equal(t, "transfer(address,uint256)", functions[0].StringNoName())
+	}
+	assert.Len(t, db.Functions("23B872DD"), 1)
+	assert.Len(t, db.Events(topic(t, "event Transfer(address,address,uint256)")), 2)
+}
+
+func TestDatabase_Load(t *testing.T) {
+	db := New()
+
+	err := db.Load(strings.NewReader("# comment\n\ntransfer(address to, uint amount)\nfunction transfer(address,uint256)\nevent Transfer(address indexed from,address indexed to,uint256)\n"))
+
+	assert.Nil(t, err)
+	functions := db.Functions("a9059cbb")
+	if assert.Len(t, functions, 1) {
+		assert.Equal(t, "transfer(address,uint256)", functions[0].StringNoName())
+		assert.Equal(t, "", functions[0].Inputs[0].Name)
+	}
+	assert.Len(t, db.Events(topic(t, "event Transfer(address,address,uint256)")), 1)
+
+	testMatrix := []struct {
+		text          string
+		expectedError string
+	}{
+		{"transfer(address,uint256)\ntransfer(address,uint7)", "line 2: invalid human-readable ABI entry \"transfer(address,uint7)\": invalid function transfer: input 1: invalid integer type: uint7, the size must be a multiple of 8 from 8 to 256"},
+		{"constructor(address)", "line 1: invalid signature \"constructor(address)\": only functions and events are supported"},
+		{"event Log(string) anonymous", "line 1: invalid signature \"event Log(string) anonymous\": anonymous events have no signature topic"},
+	}
+	for idx, test := range testMatrix {
+		db := New()
+		assert.EqualError(t, db.Load(strings.NewReader(test.text)), test.expectedError, "Test index %d failed", idx)
+		assert.Len(t, db.Functions("a9059cbb"), 0, "Test index %d failed", idx)
+	}
+}
+
+func TestDatabase_LoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sigdb")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "signatures.txt")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("gasprice_bit_ether(int128)\n"), 0600))
+
+	db := NewBundled()
+	assert.Nil(t, db.LoadFile(path))
+	assert.Len(t, db.Functions("23b872dd"), 2)
+
+	assert.NotNil(t, db.LoadFile(filepath.Join(dir, "missing.txt")))
+}
+
+func TestDatabase_DecodeCalldata(t *testing.T) {
+	db := NewBundled()
+	//these share a selector with transferFrom(address,address,uint256) and burn(uint256)
+	assert.Nil(t, db.Add("gasprice_bit_ether(int128)"))
+	assert.Nil(t, db.Add("collate_propagate_storage(bytes16)"))
+
+	testMatrix := []struct {
+		calldata           string
+		expectedSignatures []string
+	}{
+		{"23b872dd" + word("1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("9d13c6d3afe1721beef56b55d303b09e021e27ab") + word("3e8"), []string{"transferFrom(address,address,uint256)"}},
+		{"23b872dd" + word("5"), []string{"gasprice_bit_ether(int128)"}},
+		//an extra word of data, or too little data, matches nothing
+		{"23b872dd" + word("5") + word("5"), nil},
+		{"a9059cbb" + word("1932c48b2bf8102ba33b4a6b545c32236e342f34"), nil},
+		//stray bits in the padding of an address or bool aren't a valid encoding
+		{"a9059cbb" + word("ff1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("3e8"), nil},
+		{"a22cb465" + word("1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("2"), nil},
+		{"a22cb465" + word("1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("1"), []string{"setApprovalForAll(address,bool)"}},
+		//a uint256 whose lower 16 bytes are zero is also a valid bytes16
+		{"42966c68" + word("5"), []string{"burn(uint256)"}},
+		{"42966c68" + "05" + strings.Repeat("00", 31), []string{"burn(uint256)", "collate_propagate_storage(bytes16)"}},
+		{"12345678", nil},
+		{"1234", nil},
+	}
+
+	for idx, test := range testMatrix {
+		matches := db.DecodeCalldata(calldata(test.calldata))
+
+		var signatures []string
+		for _, match := range matches {
+			signatures = append(signatures, match.Function.StringNoName())
+		}
+		assert.Equal(t, test.expectedSignatures, signatures, "Test index %d failed", idx)
+	}
+}
+
+func TestDatabase_DecodeEvent(t *testing.T) {
+	db := NewBundled()
+	assert.Nil(t, db.Add("event Flag(address,bool)"))
+	assert.Nil(t, db.Add("event Sent(address,uint256)"))
+
+	transferTopic := topic(t, "event Transfer(address,address,uint256)")
+	flagTopic := topic(t, "event Flag(address,bool)")
+	sentTopic := topic(t, "event Sent(address,uint256)")
+	from := types.NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34")
+	to := types.NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab")
+
+	testMatrix := []struct {
+		topics             []types.Hash
+		data               string
+		expectedSignatures []string
+	}{
+		{[]types.Hash{transferTopic, from, to}, word("3e8"), []string{"event Transfer(address indexed,address indexed,uint256)"}},
+		{[]types.Hash{transferTopic, from, to, types.NewHash("3e8")}, "", []string{"event Transfer(address indexed,address indexed,uint256 indexed)"}},
+		{[]types.Hash{transferTopic, from, to}, word("3e8") + word("1"), nil},
+		//which argument is indexed is worked out from the topics and data
+		{[]types.Hash{flagTopic, from}, word("1"), []string{"event Flag(address indexed,bool)"}},
+		{[]types.Hash{flagTopic, types.NewHash("1")}, word("1932c48b2bf8102ba33b4a6b545c32236e342f34"), []string{"event Flag(address,bool indexed)"}},
+		{[]types.Hash{flagTopic}, word("1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("1"), []string{"event Flag(address,bool)"}},
+		//but both ways around are valid for an address and a small integer
+		{[]types.Hash{sentTopic, from}, word("3e8"), []string{"event Sent(address indexed,uint256)", "event Sent(address,uint256 indexed)"}},
+		{nil, "", nil},
+	}
+
+	for idx, test := range testMatrix {
+		matches := db.DecodeEvent(test.topics, calldata(test.data))
+
+		var signatures []string
+		for _, match := range matches {
+			signatures = append(signatures, eventSignature(match.Event))
+		}
+		assert.Equal(t, test.expectedSignatures, signatures, "Test index %d failed", idx)
+	}
+}
+
+func TestDatabase_ParseTransaction(t *testing.T) {
+	db := NewBundled()
+	assert.Nil(t, db.Add("collate_propagate_storage(bytes16)"))
+	contract := types.NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17")
+
+	ptx := &types.ParsedTransaction{RawTransaction: &types.Transaction{
+		To:   contract,
+		Data: types.NewHexData("a9059cbb" + word("1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("3e8")),
+	}}
+	assert.Nil(t, ptx.ParseTransaction(`[]`))
+	assert.Equal(t, "", ptx.Sig)
+
+	assert.Nil(t, db.ParseTransaction(ptx))
+	assert.Equal(t, "transfer(address,uint256)", ptx.Sig)
+	assert.Equal(t, types.HexData("a9059cbb"), ptx.Func4Bytes)
+	assert.Equal(t, types.ConfidenceSignature, ptx.Confidence)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", ptx.ParsedData["0"])
+	assert.EqualValues(t, big.NewInt(1000), ptx.ParsedData["1"])
+	assert.Len(t, ptx.DecodedData, 2)
+	assert.Nil(t, ptx.Candidates)
+
+	ambiguous := &types.ParsedTransaction{RawTransaction: &types.Transaction{
+		To:   contract,
+		Data: types.NewHexData("42966c68" + "05" + strings.Repeat("00", 31)),
+	}}
+	assert.Nil(t, db.ParseTransaction(ambiguous))
+	assert.Equal(t, "", ambiguous.Sig)
+	assert.Equal(t, types.ConfidenceAmbiguous, ambiguous.Confidence)
+	if assert.Len(t, ambiguous.Candidates, 2) {
+		assert.Equal(t, "burn(uint256)", ambiguous.Candidates[0].Sig)
+		assert.Equal(t, "collate_propagate_storage(bytes16)", ambiguous.Candidates[1].Sig)
+		assert.Equal(t, "0x05000000000000000000000000000000", ambiguous.Candidates[1].ParsedData["0"])
+	}
+
+	//a transaction that was parsed with the ABI is left as it is
+	parsed := &types.ParsedTransaction{Sig: "transfer(address to,uint256 value)", Confidence: types.ConfidenceABI, RawTransaction: ptx.RawTransaction}
+	assert.Nil(t, db.ParseTransaction(parsed))
+	assert.Equal(t, "transfer(address to,uint256 value)", parsed.Sig)
+	assert.Equal(t, types.ConfidenceABI, parsed.Confidence)
+
+	assert.EqualError(t, db.ParseTransaction(&types.ParsedTransaction{}), "transaction is nil or invalid")
+}
+
+func TestDatabase_ParseEvent(t *testing.T) {
+	db := NewBundled()
+
+	pe := &types.ParsedEvent{RawEvent: &types.Event{
+		Topics: []types.Hash{
+			topic(t, "event Transfer(address,address,uint256)"),
+			types.NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+			types.NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab"),
+		},
+		Data: types.NewHexData(word("3e8")),
+	}}
+
+	assert.Nil(t, db.ParseEvent(pe))
+	assert.Equal(t, "event Transfer(address indexed,address indexed,uint256)", pe.Sig)
+	assert.Equal(t, types.ConfidenceSignature, pe.Confidence)
+	assert.Equal(t, "0x9d13c6d3afe1721beef56b55d303b09e021e27ab", pe.ParsedData["1"])
+	assert.EqualValues(t, big.NewInt(1000), pe.ParsedData["2"])
+}
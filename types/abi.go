@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -10,13 +11,63 @@ type ContractABI struct {
 	Constructor ContractABIFunction
 	Functions   []ContractABIFunction
 	Events      []ContractABIEvent
+	Errors      []ContractABIError
+
+	// Receive and Fallback are only set if the contract has them, in which case
+	// their Type is "receive" or "fallback" respectively
+	Receive  ContractABIFunction
+	Fallback ContractABIFunction
+}
+
+// HasReceive reports whether the contract has a receive function for plain ether transfers
+func (abi *ContractABI) HasReceive() bool {
+	return abi.Receive.Type == "receive"
+}
+
+// HasFallback reports whether the contract has a fallback function
+func (abi *ContractABI) HasFallback() bool {
+	return abi.Fallback.Type == "fallback"
+}
+
+// FunctionBySelector finds the function whose 4 byte selector is at the start of the given calldata
+func (abi *ContractABI) FunctionBySelector(calldata []byte) (ContractABIFunction, bool) {
+	if len(calldata) < 4 {
+		return ContractABIFunction{}, false
+	}
+	selector := hex.EncodeToString(calldata[:4])
+	for _, function := range abi.Functions {
+		if function.Signature() == selector {
+			return function, true
+		}
+	}
+	return ContractABIFunction{}, false
+}
+
+// ParseCallOutput decodes the output of a call, using the function that the call input was made to
+func (abi *ContractABI) ParseCallOutput(input []byte, output []byte) (map[string]interface{}, error) {
+	function, ok := abi.FunctionBySelector(input)
+	if !ok {
+		return nil, errors.New("no function found for call input")
+	}
+	return function.ParseOutput(output)
 }
 
 type ContractABIFunction struct {
-	Type    string
-	Name    string
-	Inputs  []ContractABIArgument
-	Outputs []ContractABIArgument
+	Type            string
+	Name            string
+	Inputs          []ContractABIArgument
+	Outputs         []ContractABIArgument
+	StateMutability string
+}
+
+// IsPayable reports whether the function accepts ether
+func (function ContractABIFunction) IsPayable() bool {
+	return function.StateMutability == "payable"
+}
+
+// IsConstant reports whether the function can't modify the state, i.e. it is a view or pure function
+func (function ContractABIFunction) IsConstant() bool {
+	return function.StateMutability == "view" || function.StateMutability == "pure"
 }
 
 func (function ContractABIFunction) String() string {
@@ -40,13 +91,97 @@ func (function ContractABIFunction) Signature() string {
 }
 
 func (function ContractABIFunction) Parse(data []byte) (map[string]interface{}, error) {
-	return ParseAllData(function.Inputs, data)
+	values, err := function.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return values.ToMap(), nil
+}
+
+// Decode decodes the function input in the same way as Parse, but keeps the arguments in order
+func (function ContractABIFunction) Decode(data []byte) (DecodedValues, error) {
+	return function.DecodeWithOptions(data, DecodeOptions{})
+}
+
+// DecodeWithOptions decodes the function input in the same way as Decode, with the given options
+func (function ContractABIFunction) DecodeWithOptions(data []byte, opts DecodeOptions) (DecodedValues, error) {
+	return newABIDecoder(data, opts).decodeArguments(function.Inputs, data, function.Name)
+}
+
+// Unpack decodes the function input into the value pointed to by into, see UnpackAllData for how values are stored
+func (function ContractABIFunction) Unpack(data []byte, into interface{}) error {
+	values, err := function.Decode(data)
+	if err != nil {
+		return err
+	}
+	return unpackValues(function.Inputs, values.ToIndexedMap(), into, function.Name)
+}
+
+// ParseOutput decodes the data returned by the function, e.g. the result of an eth_call or the output of a call trace.
+// Outputs are often unnamed, so these are keyed by their position, see DecodedValues.ToIndexedMap
+func (function ContractABIFunction) ParseOutput(data []byte) (map[string]interface{}, error) {
+	values, err := function.DecodeOutput(data)
+	if err != nil {
+		return nil, err
+	}
+	return values.ToIndexedMap(), nil
+}
+
+// DecodeOutput decodes the data returned by the function in the same way as ParseOutput, but keeps the outputs in order
+func (function ContractABIFunction) DecodeOutput(data []byte) (DecodedValues, error) {
+	return function.DecodeOutputWithOptions(data, DecodeOptions{})
+}
+
+// DecodeOutputWithOptions decodes the data returned by the function in the same way as DecodeOutput, with the
+// given options
+func (function ContractABIFunction) DecodeOutputWithOptions(data []byte, opts DecodeOptions) (DecodedValues, error) {
+	return newABIDecoder(data, opts).decodeArguments(function.Outputs, data, function.Name)
+}
+
+// UnpackOutput decodes the data returned by the function into the value pointed to by into,
+// see UnpackAllData for how values are stored
+func (function ContractABIFunction) UnpackOutput(data []byte, into interface{}) error {
+	values, err := function.DecodeOutput(data)
+	if err != nil {
+		return err
+	}
+	return unpackValues(function.Outputs, values.ToIndexedMap(), into, function.Name)
+}
+
+// Pack encodes the given arguments as the input to the function, prefixed
+// with the 4 byte function selector, ready to be used as calldata
+func (function ContractABIFunction) Pack(args ...interface{}) ([]byte, error) {
+	selector, err := hex.DecodeString(function.Signature())
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := EncodeAllData(function.Inputs, args)
+	if err != nil {
+		return nil, err
+	}
+	return append(selector, encoded...), nil
 }
 
 type ContractABIArgument struct {
 	Name       string
 	Type       string
 	Components []ContractABIArgument
+
+	// InternalType is the type used in the Solidity source, such as "struct Contract.Custom[]" or
+	// "contract IERC20". It is only given by newer compilers, and has no effect on the encoding
+	InternalType string
+}
+
+// StructName gives the name of the Solidity struct that a tuple argument was declared as, if known
+func (arg ContractABIArgument) StructName() string {
+	if !strings.HasPrefix(arg.InternalType, "struct ") {
+		return ""
+	}
+	name := strings.TrimPrefix(arg.InternalType, "struct ")
+	if end := strings.Index(name, "["); end >= 0 {
+		name = name[:end]
+	}
+	return name
 }
 
 func (arg ContractABIArgument) String() string {
@@ -57,7 +192,7 @@ func (arg ContractABIArgument) String() string {
 		}
 		return fmt.Sprintf("(%s)%s %s", strings.Join(componentSigs, ","), arg.Type[5:], arg.Name)
 	}
-	return fmt.Sprintf("%s %s", arg.Type, arg.Name)
+	return fmt.Sprintf("%s %s", canonicalType(arg.Type), arg.Name)
 }
 
 func (arg ContractABIArgument) StringNoName() string {
@@ -68,69 +203,27 @@ func (arg ContractABIArgument) StringNoName() string {
 		}
 		return fmt.Sprintf("(%s)%s", strings.Join(componentSigs, ","), arg.Type[5:])
 	}
-	return arg.Type
+	return canonicalType(arg.Type)
 }
 
-/*
-+-----------------+--------+---------+
-|      Type       | Static | Dynamic |
-+-----------------+--------+---------+
-| uint<x>         | ✔      |         |
-| int<x>          | ✔      |         |
-| address         | ✔      |         |
-| bool            | ✔      |         |
-| bytes<x>        | ✔      |         |
-| bytes           |        | ✔       |
-| string          |        | ✔       |
-| T[]             |        | ✔       |
-| T<static>[m]    | ✔      |         |
-| T<dynamic>[m]   |        | ✔       |
-| Tuple<static>   | ✔      |         |
-| Tuple<dynamic>  |        | ✔       |
-+-----------------+--------+---------+
-
-Note: a tuple is dynamic if at least one element is dynamic
-
-Note: fixed sized arrays are not explicitly handled because they only
-		depend on their type to determine if they're static or not
-*/
-func (arg ContractABIArgument) IsDynamic() bool {
-	if strings.HasSuffix(arg.Type, "[]") {
-		return true
-	}
-
-	if arg.Type == "bytes" || arg.Type == "string" {
-		return true
-	}
-	// the case for fixed size array (dynamic array would be handled above)
-	if strings.HasPrefix(arg.Type, "bytes[") || strings.HasPrefix(arg.Type, "string[") {
-		return true
+//canonicalType expands the aliases of elementary types that Solidity allows, which must not
+//be used when calculating signatures, e.g. "uint[2]" is "uint256[2]". Invalid types are left as they are
+func canonicalType(abiType string) string {
+	t, err := ParseABIType(abiType, nil)
+	if err != nil {
+		return abiType
 	}
+	return t.String()
+}
 
-	if strings.HasPrefix(arg.Type, "uint") {
-		return false
-	}
-	if strings.HasPrefix(arg.Type, "int") {
-		return false
-	}
-	if strings.HasPrefix(arg.Type, "bytes") {
-		return false
-	}
-	if strings.HasPrefix(arg.Type, "address") {
-		return false
-	}
-	if strings.HasPrefix(arg.Type, "bool") {
+// IsDynamic reports whether the argument is encoded in the tail of the data, see ABIType.IsDynamic.
+// An argument with an invalid type is never dynamic
+func (arg ContractABIArgument) IsDynamic() bool {
+	t, err := arg.ParsedType()
+	if err != nil {
 		return false
 	}
-
-	if strings.HasPrefix(arg.Type, "tuple") {
-		for _, comp := range arg.Components {
-			if comp.IsDynamic() {
-				return true
-			}
-		}
-	}
-	return false
+	return t.IsDynamic()
 }
 
 type ContractABIEvent struct {
@@ -160,14 +253,144 @@ func (event ContractABIEvent) Signature() string {
 	return hex.EncodeToString(hash(event.StringNoName()))
 }
 
-func (event ContractABIEvent) Parse(data []byte) (map[string]interface{}, error) {
-	var args []ContractABIArgument
+/*
+Parse decodes the arguments of the event, with the indexed arguments taken from the topics and the rest from the data.
+
+The topics are given as they appear in the log, so for an event that isn't anonymous the first topic is its signature.
+Only the keccak256 hash of an indexed argument that isn't a value type (string, bytes, arrays and tuples) is stored
+in its topic, so these are given as an IndexedHash rather than the original value.
+*/
+func (event ContractABIEvent) Parse(topics []Hash, data []byte) (map[string]interface{}, error) {
+	values, err := event.Decode(topics, data)
+	if err != nil {
+		return nil, err
+	}
+	return values.ToMap(), nil
+}
+
+// Decode decodes the event in the same way as Parse, but keeps the arguments in the order they are
+// declared in the event, whether they come from the topics or the data
+func (event ContractABIEvent) Decode(topics []Hash, data []byte) (DecodedValues, error) {
+	return event.DecodeWithOptions(topics, data, DecodeOptions{})
+}
+
+// DecodeWithOptions decodes the event in the same way as Decode, with the given options
+func (event ContractABIEvent) DecodeWithOptions(topics []Hash, data []byte, opts DecodeOptions) (DecodedValues, error) {
+	d := newABIDecoder(data, opts)
+	nonIndexed, err := d.decodeArguments(event.nonIndexedArguments(), data, event.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	indexedTopics := topics
+	if !event.Anonymous {
+		if len(topics) == 0 {
+			return nil, &DecodeError{Path: event.Name, Msg: "missing the event signature topic"}
+		}
+		indexedTopics = topics[1:]
+	}
+
+	result := make(DecodedValues, 0, len(event.Inputs))
+	topicIndex := 0
+	for i, arg := range event.Inputs {
+		if !arg.Indexed {
+			//the non-indexed arguments were renamed by their position, so give them back their own name
+			value := nonIndexed[len(result)-topicIndex]
+			value.Name = arg.Name
+			result = append(result, value)
+			continue
+		}
+		path := elementPath(event.Name, arg.Name, i, false)
+		if topicIndex >= len(indexedTopics) {
+			return nil, &DecodeError{Path: path, Msg: fmt.Sprintf("missing topic %d, the log only has %d topics", len(topics)-len(indexedTopics)+topicIndex, len(topics))}
+		}
+		value, err := d.decodeTopic(arg.ContractABIArgument, indexedTopics[topicIndex], path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		topicIndex++
+	}
+	return result, nil
+}
+
+// Matches reports whether a log with the given topics and data could have been emitted as this event.
+// The log must have the signature topic (unless the event is anonymous) and a topic for each indexed
+// argument, and the data must decode as the other arguments. If all of those are static, the data must
+// also be exactly the right length
+func (event ContractABIEvent) Matches(topics []Hash, data []byte) bool {
+	_, err := event.parseMatching(topics, data, DecodeOptions{})
+	return err == nil
+}
+
+//parseMatching parses the log if it matches the shape of the event, see Matches
+func (event ContractABIEvent) parseMatching(topics []Hash, data []byte, opts DecodeOptions) (DecodedValues, error) {
+	expectedTopics := 0
 	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			expectedTopics++
+		}
+	}
+	if !event.Anonymous {
+		expectedTopics++
+		if len(topics) == 0 || "0x"+event.Signature() != topics[0].String() {
+			return nil, errors.New("signature does not match the first topic")
+		}
+	}
+	if len(topics) != expectedTopics {
+		return nil, fmt.Errorf("expected %d topics, got %d", expectedTopics, len(topics))
+	}
+
+	result, err := event.DecodeWithOptions(topics, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dataType, err := tupleType(event.nonIndexedArguments())
+	if err != nil {
+		return nil, err
+	}
+	if size := dataType.staticSize(); !dataType.IsDynamic() && size != uint64(len(data)) {
+		return nil, &DecodeError{Path: event.Name, Offset: size, Msg: fmt.Sprintf("expected %d bytes of data, got %d", size, len(data))}
+	}
+	return result, nil
+}
+
+// Unpack decodes the event into the value pointed to by into, see UnpackAllData for how values are stored
+// and Parse for how indexed arguments are handled
+func (event ContractABIEvent) Unpack(topics []Hash, data []byte, into interface{}) error {
+	values, err := event.Decode(topics, data)
+	if err != nil {
+		return err
+	}
+	args := make([]ContractABIArgument, len(event.Inputs))
+	for i, arg := range event.Inputs {
+		args[i] = arg.ContractABIArgument
+	}
+	return unpackValues(args, values.ToIndexedMap(), into, event.Name)
+}
+
+//nonIndexedArguments gives the arguments that are stored in the event data. Unnamed arguments
+//are named by their position in the event, so that their results match up with the indexed arguments
+func (event ContractABIEvent) nonIndexedArguments() []ContractABIArgument {
+	var args []ContractABIArgument
+	for i, arg := range event.Inputs {
 		if !arg.Indexed {
-			args = append(args, ContractABIArgument{arg.Name, arg.Type, arg.Components})
+			args = append(args, ContractABIArgument{argumentKey(arg.ContractABIArgument, i), arg.Type, arg.Components, arg.InternalType})
 		}
 	}
-	return ParseAllData(args, data)
+	return args
+}
+
+// IndexedHash is the value of an indexed event argument that is stored as the keccak256 hash
+// of its encoding, so the original value can't be recovered from the log
+type IndexedHash struct {
+	Type string `json:"type"`
+	Hash Hash   `json:"hash"`
+}
+
+func (indexed IndexedHash) String() string {
+	return fmt.Sprintf("keccak256(%s)=%s", indexed.Type, indexed.Hash.String())
 }
 
 type ContractABIEventArgument struct {
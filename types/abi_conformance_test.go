@@ -0,0 +1,175 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The encodings below are the examples given in the Solidity ABI specification,
+// see https://docs.soliditylang.org/en/latest/abi-spec.html#examples
+var abiSpecVectors = []struct {
+	function ContractABIFunction
+	selector string
+	encoded  string
+	expected map[string]interface{}
+}{
+	{
+		ContractABIFunction{Name: "baz", Inputs: []ContractABIArgument{{Name: "x", Type: "uint32"}, {Name: "y", Type: "bool"}}},
+		"cdcd77c0",
+		"0000000000000000000000000000000000000000000000000000000000000045" +
+			"0000000000000000000000000000000000000000000000000000000000000001",
+		map[string]interface{}{"x": big.NewInt(69), "y": true},
+	},
+	{
+		ContractABIFunction{Name: "bar", Inputs: []ContractABIArgument{{Name: "x", Type: "bytes3[2]"}}},
+		"fce353f6",
+		"6162630000000000000000000000000000000000000000000000000000000000" +
+			"6465660000000000000000000000000000000000000000000000000000000000",
+		map[string]interface{}{"x": []interface{}{"0x616263", "0x646566"}},
+	},
+	{
+		ContractABIFunction{Name: "sam", Inputs: []ContractABIArgument{{Name: "a", Type: "bytes"}, {Name: "b", Type: "bool"}, {Name: "c", Type: "uint256[]"}}},
+		"a5643bf2",
+		"0000000000000000000000000000000000000000000000000000000000000060" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"00000000000000000000000000000000000000000000000000000000000000a0" +
+			"0000000000000000000000000000000000000000000000000000000000000004" +
+			"6461766500000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000003",
+		map[string]interface{}{"a": "0x64617665", "b": true, "c": []interface{}{big.NewInt(1), big.NewInt(2), big.NewInt(3)}},
+	},
+	{
+		ContractABIFunction{Name: "f", Inputs: []ContractABIArgument{{Name: "a", Type: "uint256"}, {Name: "b", Type: "uint32[]"}, {Name: "c", Type: "bytes10"}, {Name: "d", Type: "bytes"}}},
+		"8be65246",
+		"0000000000000000000000000000000000000000000000000000000000000123" +
+			"0000000000000000000000000000000000000000000000000000000000000080" +
+			"3132333435363738393000000000000000000000000000000000000000000000" +
+			"00000000000000000000000000000000000000000000000000000000000000e0" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000456" +
+			"0000000000000000000000000000000000000000000000000000000000000789" +
+			"000000000000000000000000000000000000000000000000000000000000000d" +
+			"48656c6c6f2c20776f726c642100000000000000000000000000000000000000",
+		map[string]interface{}{
+			"a": big.NewInt(0x123),
+			"b": []interface{}{big.NewInt(0x456), big.NewInt(0x789)},
+			"c": "0x31323334353637383930",
+			"d": "0x" + hex.EncodeToString([]byte("Hello, world!")),
+		},
+	},
+	{
+		ContractABIFunction{Name: "g", Inputs: []ContractABIArgument{{Name: "a", Type: "uint256[][]"}, {Name: "b", Type: "string[]"}}},
+		"2289b18c",
+		"0000000000000000000000000000000000000000000000000000000000000040" +
+			"0000000000000000000000000000000000000000000000000000000000000140" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000040" +
+			"00000000000000000000000000000000000000000000000000000000000000a0" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000002" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"0000000000000000000000000000000000000000000000000000000000000060" +
+			"00000000000000000000000000000000000000000000000000000000000000a0" +
+			"00000000000000000000000000000000000000000000000000000000000000e0" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"6f6e650000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"74776f0000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000005" +
+			"7468726565000000000000000000000000000000000000000000000000000000",
+		map[string]interface{}{
+			"a": []interface{}{
+				[]interface{}{big.NewInt(1), big.NewInt(2)},
+				[]interface{}{big.NewInt(3)},
+			},
+			"b": []interface{}{"one", "two", "three"},
+		},
+	},
+}
+
+func TestABISpecVectors_Parse(t *testing.T) {
+	for idx, test := range abiSpecVectors {
+		assert.Equal(t, test.selector, test.function.Signature(), "Test index %d failed", idx)
+
+		result, err := test.function.Parse(hexToBytes(test.encoded))
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, result, "Test index %d failed", idx)
+	}
+}
+
+func TestABISpecVectors_Encode(t *testing.T) {
+	for idx, test := range abiSpecVectors {
+		values := make([]interface{}, len(test.function.Inputs))
+		for i, input := range test.function.Inputs {
+			values[i] = test.expected[input.Name]
+		}
+
+		encoded, err := test.function.Pack(values...)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.selector+test.encoded, hex.EncodeToString(encoded), "Test index %d failed", idx)
+	}
+}
+
+func TestParseAllData_BytesLengths(t *testing.T) {
+	args := []ContractABIArgument{{Name: "value", Type: "bytes"}, {Name: "after", Type: "uint8"}}
+
+	for _, length := range []int{0, 1, 31, 32, 33, 64, 100} {
+		value := make([]byte, length)
+		for i := range value {
+			value[i] = byte(i + 1)
+		}
+		//the value is followed by its padding, the data ends with the value if it fills the last word
+		padded := hex.EncodeToString(value) + strings.Repeat("00", (32-length%32)%32)
+		data := hexToBytes(word("40") + word("7") + word(fmt.Sprintf("%x", length)) + padded)
+
+		result, err := ParseAllData(args, data)
+
+		assert.Nil(t, err, "Length %d failed", length)
+		assert.Equal(t, "0x"+hex.EncodeToString(value), result["value"], "Length %d failed", length)
+		assert.EqualValues(t, big.NewInt(7), result["after"], "Length %d failed", length)
+
+		var unpacked struct {
+			Value []byte
+			After uint8
+		}
+		assert.Nil(t, UnpackAllData(args, data, &unpacked), "Length %d failed", length)
+		assert.Equal(t, value, unpacked.Value, "Length %d failed", length)
+	}
+}
+
+func TestParseAllData_BytesAsSlice(t *testing.T) {
+	test := abiSpecVectors[3]
+	values, err := test.function.DecodeWithOptions(hexToBytes(test.encoded), DecodeOptions{BytesFormat: BytesAsSlice})
+
+	assert.Nil(t, err)
+	result := values.ToMap()
+	assert.Equal(t, []byte("1234567890"), result["c"])
+	assert.Equal(t, []byte("Hello, world!"), result["d"])
+
+	var unpacked struct {
+		A *big.Int
+		B []uint32
+		C [10]byte
+		D []byte
+	}
+	assert.Nil(t, test.function.Unpack(hexToBytes(test.encoded), &unpacked))
+	assert.Equal(t, "1234567890", string(unpacked.C[:]))
+	assert.Equal(t, "Hello, world!", string(unpacked.D))
+
+	encoded, err := test.function.Pack(result["a"], result["b"], result["c"], result["d"])
+	assert.Nil(t, err)
+	assert.Equal(t, test.selector+test.encoded, hex.EncodeToString(encoded))
+}
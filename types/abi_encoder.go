@@ -0,0 +1,383 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+Rules for encoding can be found at: https://solidity.readthedocs.io/en/develop/abi-spec.html#argument-encoding
+
+EncodeAllData is the inverse of ParseAllData, it encodes a set of values against their ABI arguments
+
+1) Encode every value on its own, which gives the full encoding of a static element, or the "tail" of a dynamic element
+2) Calculate the size of the "head" section, which is the length of each static element plus 32 bytes for each dynamic element
+3) Lay out the heads in order, where a dynamic element's head is the offset of its tail from the start of the encoding,
+	and append all the tails after the head section
+
+Go values are accepted in the same shapes that the parser produces, as well as native Go types:
+- uint<x>/int<x>: *big.Int, big.Int, any Go integer type or a decimal/"0x" prefixed hex string, with an optional "-"
+- address: a hex string (with or without "0x"), Address, []byte or [20]byte
+- bool: bool
+- bytes<x>/bytes: a hex string (with or without "0x"), HexData, []byte or [N]byte, which must be exactly x bytes for bytes<x>
+- fixed<M>x<N>/ufixed<M>x<N>: a decimal string such as "-1.25", *big.Rat, or any of the integer values above
+- function: a hex string (with or without "0x") or []byte of the 20 byte address followed by the 4 byte selector
+- string: string
+- T[k]/T[]: any Go slice or array with elements accepted by T
+- tuple: a map[string]interface{} keyed by component name, or any Go slice or array with one entry per component
+*/
+func EncodeAllData(inputs []ContractABIArgument, values []interface{}) ([]byte, error) {
+	t, err := tupleType(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return encodeAll(t.Components, values)
+}
+
+//EncodeValue encodes a single value according to its ABI argument. For a static type this
+//is the value as it appears in the head, and for a dynamic type it is the tail of the value
+func EncodeValue(arg ContractABIArgument, value interface{}) ([]byte, error) {
+	t, err := arg.ParsedType()
+	if err != nil {
+		return nil, err
+	}
+	return encodeValue(t, value)
+}
+
+//encodeAll encodes a list of values against their types, which are either the components of a tuple
+//or the elements of an array, laying out the heads followed by the tails
+func encodeAll(types []*ABIType, values []interface{}) ([]byte, error) {
+	if len(types) != len(values) {
+		return nil, fmt.Errorf("argument count mismatch: expected %d, got %d", len(types), len(values))
+	}
+
+	encodedValues := make([][]byte, len(types))
+	headSize := 0
+	for i, t := range types {
+		encoded, err := encodeValue(t, values[i])
+		if err != nil {
+			return nil, err
+		}
+		encodedValues[i] = encoded
+
+		//a dynamic element only takes up the 32 bytes of its offset in the head
+		if t.IsDynamic() {
+			headSize += 32
+		} else {
+			headSize += len(encoded)
+		}
+	}
+
+	var head, tail []byte
+	for i, t := range types {
+		if t.IsDynamic() {
+			head = append(head, encodeUint64(uint64(headSize+len(tail)))...)
+			tail = append(tail, encodedValues[i]...)
+			continue
+		}
+		head = append(head, encodedValues[i]...)
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t *ABIType, value interface{}) ([]byte, error) {
+	switch t.Kind {
+	//an array of either fixed or dynamic size
+	//the ABI spec says a fixed size array is encoded as a tuple of the same number of elements,
+	//and a dynamic array is the same with the number of elements prefixed
+	case ArrayKind, SliceKind:
+		elements, err := asList(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as %s: %s", value, t.TypeName(), err.Error())
+		}
+		if t.Kind == ArrayKind && uint64(len(elements)) != t.Length {
+			return nil, fmt.Errorf("cannot encode %s: expected %d elements, got %d", t.TypeName(), t.Length, len(elements))
+		}
+
+		encoded, err := encodeAll(t.Elem.repeated(uint64(len(elements))), elements)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == SliceKind {
+			return append(encodeUint64(uint64(len(elements))), encoded...), nil
+		}
+		return encoded, nil
+
+	//a tuple is encoded the same as if its components were listed individually
+	case TupleKind:
+		values, err := tupleValues(t, value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeAll(t.Components, values)
+
+	//a string is prefixed with its length, and the UTF-8 bytes are right-padded to the next multiple of 32
+	case StringKind:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot encode %T as string", value)
+		}
+		return encodeDynamicBytes([]byte(str)), nil
+
+	//a bytes array is encoded the same as a string
+	case BytesKind:
+		b, err := asBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as bytes: %s", value, err.Error())
+		}
+		return encodeDynamicBytes(b), nil
+
+	//a set of bytes, from bytes1 upto bytes32, right-padded to 32 bytes
+	case FixedBytesKind:
+		b, err := asBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as %s: %s", value, t.TypeName(), err.Error())
+		}
+		if uint(len(b)) != t.Size {
+			return nil, fmt.Errorf("cannot encode %d bytes as %s", len(b), t.TypeName())
+		}
+		return rightPad(b), nil
+
+	//a bool value, left-padded to 32 bytes
+	case BoolKind:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("cannot encode %T as bool", value)
+		}
+		if b {
+			return encodeUint64(1), nil
+		}
+		return encodeUint64(0), nil
+
+	// a signed int, in 2s complement and left-padded to 32 bytes. Handles int8 upto int256
+	// an unsigned int, left-padded to 32 bytes. Handles uint8 upto uint256
+	case IntKind, UintKind:
+		i, err := asBigInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as %s: %s", value, t.TypeName(), err.Error())
+		}
+		if !fitsInteger(i, t.Signed, t.Size) {
+			return nil, fmt.Errorf("value %s overflows %s", i.String(), t.TypeName())
+		}
+		return encodeInt(i), nil
+
+	// a fixed point number, encoded as an int or uint of the value * 10^N
+	case FixedPointKind:
+		i, err := asFixed(value, t.Decimals)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as %s: %s", value, t.TypeName(), err.Error())
+		}
+		if !fitsInteger(i, t.Signed, t.Size) {
+			return nil, fmt.Errorf("value %s overflows %s", FormatFixed(i, t.Decimals), t.TypeName())
+		}
+		return encodeInt(i), nil
+
+	// a function, which is a 20 byte address followed by a 4 byte selector, right-padded to 32 bytes
+	case FunctionKind:
+		b, err := asBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as function: %s", value, err.Error())
+		}
+		if len(b) != 24 {
+			return nil, fmt.Errorf("cannot encode %d bytes as function", len(b))
+		}
+		return rightPad(b), nil
+
+	// a 20 byte address, left-padded to 32 bytes
+	case AddressKind:
+		b, err := asBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %T as address: %s", value, err.Error())
+		}
+		if len(b) != 20 {
+			return nil, fmt.Errorf("cannot encode %d bytes as address", len(b))
+		}
+		return leftPad(b), nil
+	}
+
+	return nil, errors.New("unknown type: " + t.TypeName())
+}
+
+//fitsInteger reports whether the value fits in an int<bits> or uint<bits>
+func fitsInteger(i *big.Int, signed bool, bits uint) bool {
+	if !signed {
+		return i.Sign() >= 0 && i.BitLen() <= int(bits)
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	return i.Cmp(limit) < 0 && i.Cmp(new(big.Int).Neg(limit)) >= 0
+}
+
+//encodeInt encodes a signed integer as a 32 byte 2s complement value
+func encodeInt(i *big.Int) []byte {
+	if i.Sign() >= 0 {
+		return leftPad(i.Bytes())
+	}
+	// negative, so add 2^256 to get the 2s complement representation
+	twosComplement := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 256), i)
+	return leftPad(twosComplement.Bytes())
+}
+
+func encodeUint64(i uint64) []byte {
+	return leftPad(new(big.Int).SetUint64(i).Bytes())
+}
+
+func encodeDynamicBytes(b []byte) []byte {
+	encoded := encodeUint64(uint64(len(b)))
+	if len(b) == 0 {
+		return encoded
+	}
+	return append(encoded, rightPad(b)...)
+}
+
+//leftPad pads the bytes with leading zeroes to 32 bytes
+func leftPad(b []byte) []byte {
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
+
+//rightPad pads the bytes with trailing zeroes to the next multiple of 32 bytes
+func rightPad(b []byte) []byte {
+	padded := make([]byte, (len(b)+31)/32*32)
+	copy(padded, b)
+	return padded
+}
+
+func asBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, errors.New("nil value")
+		}
+		return v, nil
+	case big.Int:
+		return &v, nil
+	case string:
+		i, ok := parseIntegerString(v)
+		if !ok {
+			return nil, errors.New("invalid integer string " + strconv.Quote(v))
+		}
+		return i, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, errors.New("not an integer")
+}
+
+//parseIntegerString parses a decimal integer, or a hex integer with a "0x" prefix, either of which can be negative.
+//Other bases and the underscores that big.Int allows with base 0 are rejected
+func parseIntegerString(value string) (*big.Int, bool) {
+	digits, negative := value, false
+	if strings.HasPrefix(digits, "-") {
+		digits, negative = digits[1:], true
+	}
+	base := 10
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits, base = digits[2:], 16
+	}
+	//big.Int allows a sign of its own, which would accept two signs or a sign after the prefix
+	if digits == "" || digits[0] == '-' || digits[0] == '+' {
+		return nil, false
+	}
+	i, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, false
+	}
+	if negative {
+		i.Neg(i)
+	}
+	return i, true
+}
+
+//asFixed gives the integer value of a fixed point number with the given number of decimals, from either
+//a decimal string, a *big.Rat or an integer
+func asFixed(value interface{}, decimals uint) (*big.Int, error) {
+	switch v := value.(type) {
+	case string:
+		return ParseFixed(v, decimals)
+	case *big.Rat:
+		if v == nil {
+			return nil, errors.New("nil value")
+		}
+		return fixedFromRat(v, decimals)
+	}
+	i, err := asBigInt(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Mul(i, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)), nil
+}
+
+func asBytes(value interface{}) ([]byte, error) {
+	if b, ok := value.([]byte); ok {
+		return b, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return fromHex(rv.String())
+	case reflect.Array:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, errors.New("not a byte array")
+		}
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return b, nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, errors.New("not a byte slice")
+		}
+		return rv.Bytes(), nil
+	}
+	return nil, errors.New("not a byte array or hex string")
+}
+
+func asList(value interface{}) ([]interface{}, error) {
+	if list, ok := value.([]interface{}); ok {
+		return list, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, errors.New("not a slice or array")
+	}
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, nil
+}
+
+//tupleValues orders the values of a tuple to match its components. The values may be
+//a map keyed by component name, or a list in the same order as the components
+func tupleValues(t *ABIType, value interface{}) ([]interface{}, error) {
+	if asMap, ok := value.(map[string]interface{}); ok {
+		values := make([]interface{}, len(t.Components))
+		for i, name := range t.ComponentNames {
+			key := argumentKey(ContractABIArgument{Name: name}, i)
+			v, exists := asMap[key]
+			if !exists {
+				return nil, fmt.Errorf("cannot encode tuple: missing value for component %q", key)
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	values, err := asList(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %T as tuple: %s", value, err.Error())
+	}
+	return values, nil
+}
@@ -0,0 +1,252 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// abiParsingContractTx is the transaction that deployed ABIParsingContract.sol,
+// including all the events emitted by its constructor
+var abiParsingContractTx = `{"hash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","status":true,"blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","index":0,"nonce":0,"from":"0xed9d02e382b34818e88b88a309c7fe71e65f419d","to":"0x0000000000000000000000000000000000000000","value":0,"gas":4700000,"gasPrice":0,"gasUsed":1891109,"cumulativeGasUsed":1891109,"createdContract":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","data":"","privateData":"0x","isPrivate":false,"timestamp":1594301001,"events":[{"index":0,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x35d7282d232693a58e27f2460e4aacced5663fd2e86b4645690bbab931e4772d"],"data":"0x123456789012345678901234567890123456789012345678901234567890123474000000000000000000000000000000000000000000000000000000000000001200000000000000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":1,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x97db7e059ed2946cf7c8383948665db73baf23d369fc6b8eafb56fd7ca80b3cf"],"data":"0x00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":2,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0xc1865ec3e31fe00f17669214b0ac9bc55c1c34093a55b276a096eceb3598d8d1"],"data":"0xfffffffffffffffffffffffffffffffffffffffffffffffaa55ab2c71ad981160000000000000000000000000000000000000000000000000000000000003039ffffffffffffffffffffffffffffffffffffffffffffffffff642fe40c8aacfa000000000000000000000000000000000000000000000000ab54a98ceb1f0ad2","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":3,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x32021c01abdbd238344b0243e5256ab75d2e769dbea4b2b2fb56bd8738196c2c"],"data":"0x0000000000000000000000001932c48b2bf8102ba33b4a6b545c32236e342f340000000000000000000000009d13c6d3afe1721beef56b55d303b09e021e27ab","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":4,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x20cf3f101b104cb006fad7296551822b55d258a055008a81103d9ab3f233f644"],"data":"0x0000000000000000000000000000000000000000000000055aa54d38e5267eea0000000000000000000000000000000000000000000000000000000000003039000000000000000000000000000000000000000000000000009bd01bf3755306000000000000000000000000000000000000000000000000ab54a98ceb1f0ad2","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":5,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x256ec87dcd70c23fc01ff7a2f63fb7fb0073d152d0e0254ff578a46a631dd316"],"data":"0x000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000800000000000000000000000000000000000000000000000000000000000000005736d616c6c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000005a736f6d65207265616c6c79206c6172676520737472696e6720746861742077696c6c20676f206f76657220746865207468697274792d74776f2062797465206c696d697420666f7220612073696e676c65207661726961626c65000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":6,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0xe347e4329ff6519c831591d74f30a2748a4f38560924ee6970c881edc23ddd8d"],"data":"0x000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000e00000000000000000000000000000000000000000000000000000000000000064000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f60616263000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000064000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f6061626300000000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":7,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0xf62c419906de86aa15be7318a99c09b710c7e4d500fac892ce54ae145611728b"],"data":"0x0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000006400000000000000000000000000000000000000000000000000000000000000c8000000000000000000000000000000000000000000000000000000000000002c000000000000000000000000000000000000000000000000000000000000009000000000000000000000000000000000000000000000000000000000000000f4000000000000000000000000000000000000000000000000000000000000005800000000000000000000000000000000000000000000000000000000000000bc00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000084000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":8,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x9348b0e10a05565b31d4a3d3550bbd3c36fdbc9cb8eff07b960d7802910e6489"],"data":"0x000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000a0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000006400000000000000000000000000000000000000000000000000000000000000c8000000000000000000000000000000000000000000000000000000000000002c000000000000000000000000000000000000000000000000000000000000009000000000000000000000000000000000000000000000000000000000000000f4000000000000000000000000000000000000000000000000000000000000005800000000000000000000000000000000000000000000000000000000000000bc00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000084000000000000000000000000000000000000000000000000000000000000001400000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":9,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x06e13b4a8583b0c888cf9710d4e1e78378e52b39d0775c443acc85c8ccfe44ec"],"data":"0x000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000e00000000000000000000000000000000000000000000000000000000000000060123456789012345678901234567890123456789012345678901234567890123400000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000005666972737400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000604013487654674538507684738547847680974039786439857345674358096798000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000067365636f6e640000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":10,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0xe6f5c700a9879af36c5780605691c742a0bc6376e86f37727ffb72a27d0203fc"],"data":"0x00000000000000000000000000000000000000000000000000000000000000a043865789746478086504605430483574304038976310674530640434765847310000000000000000000000000000000000000000000000000000000000000d7f000000000000000000000000000000000000000000000000000000000000014000000000000000000000000000000000000000000000000000000000000001e00000000000000000000000000000000000000000000000000000000000000060123456789012345678901234567890123456789012345678901234567890123400000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000006717765727479000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000604013487654674538507684738547847680974039786439857345674358096798000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000096173646667686a6b6c0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000c737472696e672066696674680000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":11,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x5a5add0d273fac0599b39c4a0362e7f6c3aea02ddb3bec3a566aa057da3ffe4b"],"data":"0x0000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000000a0000000000000000000000000000000000000000000000000000000000000014000000000000000000000000000000000000000000000000000000000000001e00000000000000000000000000000000000000000000000000000000000000280000000000000000000000000000000000000000000000000000000000000032000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000006000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000601234567890123456789012345678901234567890123456789012345678901234000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000067177657274790000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000006012345678901234567890123456789012345678901234567890123456789012340000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000671776572747900000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000e00000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000601234567890123456789012345678901234567890123456789012345678901234000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000067177657274790000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":12,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x46f092a93f590e8af8ab57315116b41765e5fc7a88509f9aa66173774c0532a2"],"data":"0x0000000000000000000000000000000000000000000000000000000000003c3f0800000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000c126000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001},{"index":13,"address":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34","topics":["0x5ef9e93d32c1a54a219a2e1083104d924cea95596bb1ba7c1a70f963a8976b39"],"data":"0x0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003c3f080000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000003c3f080000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000003c3f080000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000003c3f08000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000003c3f080000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000003c3f08000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001","blockNumber":1,"blockHash":"0xc7fd1915b4b8ac6344e750e4eaeacf9114d4e185f9c10b6b3bc7049511a96998","transactionHash":"0xbc77a72b3409ba3e098cb45bac1b7727b59dae9a05f37a0dbc61007949c8cede","transactionIndex":0,"timestamp":1594301001}],"internalCalls":null}`
+
+// abiParsingContractABI is the compiled ABI of ABIParsingContract.sol
+var abiParsingContractABI = `[{"inputs":[],"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"address","name":"first","type":"address"},{"indexed":false,"internalType":"address","name":"second","type":"address"}],"name":"AddressFixed","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"uint256[]","name":"first","type":"uint256[]"},{"indexed":false,"internalType":"bool[]","name":"second","type":"bool[]"}],"name":"ArrayDynamicSize","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"uint256[10]","name":"first","type":"uint256[10]"},{"indexed":false,"internalType":"bool[6]","name":"second","type":"bool[6]"}],"name":"ArrayFixedSize","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bool","name":"first","type":"bool"},{"indexed":false,"internalType":"bool","name":"second","type":"bool"}],"name":"BoolFixed","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bytes","name":"first","type":"bytes"},{"indexed":false,"internalType":"bytes","name":"second","type":"bytes"}],"name":"BytesFixed","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bytes32","name":"first","type":"bytes32"},{"indexed":false,"internalType":"bytes1","name":"second","type":"bytes1"},{"indexed":false,"internalType":"bytes1","name":"third","type":"bytes1"}],"name":"BytesFixedSize","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"int256","name":"first","type":"int256"},{"indexed":false,"internalType":"int16","name":"second","type":"int16"},{"indexed":false,"internalType":"int64","name":"third","type":"int64"},{"indexed":false,"internalType":"int256","name":"fourth","type":"int256"}],"name":"IntFixed","type":"event"},{"anonymous":false,"inputs":[{"components":[{"internalType":"string","name":"first","type":"string"},{"internalType":"bytes32","name":"second","type":"bytes32"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.Custom","name":"first","type":"tuple"},{"indexed":false,"internalType":"bytes32","name":"second","type":"bytes32"},{"indexed":false,"internalType":"int16","name":"third","type":"int16"},{"components":[{"internalType":"string","name":"first","type":"string"},{"internalType":"bytes32","name":"second","type":"bytes32"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.Custom","name":"fourth","type":"tuple"},{"indexed":false,"internalType":"string","name":"fifth","type":"string"}],"name":"Mixed","type":"event"},{"anonymous":false,"inputs":[{"components":[{"internalType":"uint64","name":"first","type":"uint64"},{"internalType":"bytes1","name":"second","type":"bytes1"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.StaticTuple","name":"first","type":"tuple"},{"components":[{"internalType":"uint64","name":"first","type":"uint64"},{"internalType":"bytes1","name":"second","type":"bytes1"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.StaticTuple","name":"second","type":"tuple"}],"name":"StaticTupleEventOne","type":"event"},{"anonymous":false,"inputs":[{"components":[{"internalType":"uint64","name":"first","type":"uint64"},{"internalType":"bytes1","name":"second","type":"bytes1"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.StaticTuple[5]","name":"first","type":"tuple[5]"},{"components":[{"internalType":"uint64","name":"first","type":"uint64"},{"internalType":"bytes1","name":"second","type":"bytes1"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.StaticTuple[]","name":"second","type":"tuple[]"}],"name":"StaticTupleEventTwo","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"string","name":"first","type":"string"},{"indexed":false,"internalType":"string","name":"second","type":"string"}],"name":"StringFixed","type":"event"},{"anonymous":false,"inputs":[{"components":[{"internalType":"string","name":"first","type":"string"},{"internalType":"bytes32","name":"second","type":"bytes32"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.Custom[5]","name":"first","type":"tuple[5]"},{"components":[{"internalType":"string","name":"first","type":"string"},{"internalType":"bytes32","name":"second","type":"bytes32"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.Custom[]","name":"second","type":"tuple[]"}],"name":"StructArray","type":"event"},{"anonymous":false,"inputs":[{"components":[{"internalType":"string","name":"first","type":"string"},{"internalType":"bytes32","name":"second","type":"bytes32"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.Custom","name":"first","type":"tuple"},{"components":[{"internalType":"string","name":"first","type":"string"},{"internalType":"bytes32","name":"second","type":"bytes32"},{"internalType":"bool","name":"third","type":"bool"}],"indexed":false,"internalType":"struct ABIParsingContract.Custom","name":"second","type":"tuple"}],"name":"TupleDynamic","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"uint256","name":"first","type":"uint256"},{"indexed":false,"internalType":"uint16","name":"second","type":"uint16"},{"indexed":false,"internalType":"uint64","name":"third","type":"uint64"},{"indexed":false,"internalType":"uint256","name":"fourth","type":"uint256"}],"name":"UintFixed","type":"event"}]`
+
+func fixtureEvents(t testing.TB) (*ContractABI, []*Event) {
+	var tx Transaction
+	err := json.Unmarshal([]byte(abiParsingContractTx), &tx)
+	assert.Nil(t, err)
+
+	structure, err := NewABIStructureFromJSON(abiParsingContractABI)
+	assert.Nil(t, err)
+
+	return structure.ToInternalABI(), tx.Events
+}
+
+func eventArguments(event ContractABIEvent) []ContractABIArgument {
+	var args []ContractABIArgument
+	for _, arg := range event.Inputs {
+		args = append(args, arg.ContractABIArgument)
+	}
+	return args
+}
+
+func TestEncodeAllData_RoundTrip(t *testing.T) {
+	//Parses each event from ABIParsingContract.sol, encodes the parsed values
+	//and checks that parsing the encoded data gives back the same values
+	abi, events := fixtureEvents(t)
+
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() != c.Topics[0].String() {
+				continue
+			}
+			parsed, err := ev.Parse(c.Topics, c.Data.AsBytes())
+			assert.Nil(t, err)
+
+			args := eventArguments(ev)
+			values := make([]interface{}, len(args))
+			for i, arg := range args {
+				values[i] = parsed[arg.Name]
+			}
+
+			encoded, err := EncodeAllData(args, values)
+			assert.Nil(t, err, "event %s failed", ev.Name)
+
+			reparsed, err := ParseAllData(args, encoded)
+			assert.Nil(t, err, "event %s failed", ev.Name)
+
+			expected, _ := json.Marshal(parsed)
+			actual, _ := json.Marshal(reparsed)
+			assert.JSONEq(t, string(expected), string(actual), "event %s failed", ev.Name)
+
+			// solc pads an empty string read from storage to a full word, which is
+			// valid but not the canonical encoding, so the data can't be compared directly
+			if ev.Name != "StructArray" {
+				assert.Equal(t, c.Data.AsBytes(), encoded, "event %s failed", ev.Name)
+			}
+		}
+	}
+}
+
+func TestEncodeAllData_Bytes(t *testing.T) {
+	abi, events := fixtureEvents(t)
+
+	moreThan31 := make([]byte, 100)
+	for i := range moreThan31 {
+		moreThan31[i] = byte(i)
+	}
+
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() == c.Topics[0].String() && ev.Name == "BytesFixed" {
+				encoded, err := EncodeAllData(eventArguments(ev), []interface{}{moreThan31, "0x" + hex.EncodeToString(moreThan31)})
+				assert.Nil(t, err)
+				assert.Equal(t, c.Data.AsBytes(), encoded)
+				return
+			}
+		}
+	}
+	t.Fatal("BytesFixed event not found")
+}
+
+func TestEncodeAllData_NativeValues(t *testing.T) {
+	abi, events := fixtureEvents(t)
+
+	var second [32]byte
+	secondBytes, _ := hex.DecodeString("4386578974647808650460543048357430403897631067453064043476584731")
+	copy(second[:], secondBytes)
+
+	values := []interface{}{
+		[]interface{}{"qwerty", "0x1234567890123456789012345678901234567890123456789012345678901234", true},
+		second,
+		int16(3455),
+		map[string]interface{}{
+			"first":  "asdfghjkl",
+			"second": NewHexData("4013487654674538507684738547847680974039786439857345674358096798"),
+			"third":  false,
+		},
+		"string fifth",
+	}
+
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() == c.Topics[0].String() && ev.Name == "Mixed" {
+				encoded, err := EncodeAllData(eventArguments(ev), values)
+				assert.Nil(t, err)
+				assert.Equal(t, c.Data.AsBytes(), encoded)
+
+				parsed, err := ParseAllData(eventArguments(ev), encoded)
+				assert.Nil(t, err)
+				assert.EqualValues(t, big.NewInt(3455), parsed["third"])
+				assert.Equal(t, "string fifth", parsed["fifth"])
+				return
+			}
+		}
+	}
+	t.Fatal("Mixed event not found")
+}
+
+func TestEncodeValue_NegativeInt(t *testing.T) {
+	encoded, err := EncodeValue(ContractABIArgument{Type: "int256"}, "-98765432109876543210")
+	assert.Nil(t, err)
+	assert.Equal(t, "fffffffffffffffffffffffffffffffffffffffffffffffaa55ab2c71ad98116", hex.EncodeToString(encoded))
+
+	parsed, _, err := ParseStaticType(ContractABIArgument{Type: "int256"}, encoded, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "-98765432109876543210", parsed.(*big.Int).String())
+}
+
+func TestEncodeValue_IntegerStrings(t *testing.T) {
+	testMatrix := []struct {
+		value    string
+		expected int64
+	}{
+		{"1000", 1000},
+		{"0x3e8", 1000},
+		{"0X3E8", 1000},
+		{"-1000", -1000},
+		{"-0x3e8", -1000},
+		//a leading zero is still decimal, not octal
+		{"010", 10},
+	}
+
+	for idx, test := range testMatrix {
+		encoded, err := EncodeValue(ContractABIArgument{Type: "int256"}, test.value)
+		assert.Nil(t, err, "Test index %d failed", idx)
+
+		expected, _ := EncodeValue(ContractABIArgument{Type: "int256"}, test.expected)
+		assert.Equal(t, expected, encoded, "Test index %d failed", idx)
+	}
+}
+
+func TestEncodeValue_Errors(t *testing.T) {
+	testMatrix := []struct {
+		arg           ContractABIArgument
+		value         interface{}
+		expectedError string
+	}{
+		{ContractABIArgument{Type: "uint8"}, 256, "value 256 overflows uint8"},
+		{ContractABIArgument{Type: "uint256"}, -1, "value -1 overflows uint256"},
+		{ContractABIArgument{Type: "int8"}, 128, "value 128 overflows int8"},
+		{ContractABIArgument{Type: "int8"}, -129, "value -129 overflows int8"},
+		{ContractABIArgument{Type: "uint256"}, "abc", `cannot encode string as uint256: invalid integer string "abc"`},
+		{ContractABIArgument{Type: "bool"}, 1, "cannot encode int as bool"},
+		{ContractABIArgument{Type: "address"}, "0x1234", "cannot encode 2 bytes as address"},
+		{ContractABIArgument{Type: "bytes1"}, []byte{1, 2}, "cannot encode 2 bytes as bytes1"},
+		{ContractABIArgument{Type: "bytes4"}, "0x1234", "cannot encode 2 bytes as bytes4"},
+		{ContractABIArgument{Type: "bytes32"}, [20]byte{}, "cannot encode 20 bytes as bytes32"},
+		//only decimal and "0x" prefixed hex strings are integers
+		{ContractABIArgument{Type: "uint256"}, "1_000", `cannot encode string as uint256: invalid integer string "1_000"`},
+		{ContractABIArgument{Type: "uint256"}, "0b101", `cannot encode string as uint256: invalid integer string "0b101"`},
+		{ContractABIArgument{Type: "uint256"}, "0o17", `cannot encode string as uint256: invalid integer string "0o17"`},
+		{ContractABIArgument{Type: "uint256"}, "0x", `cannot encode string as uint256: invalid integer string "0x"`},
+		{ContractABIArgument{Type: "int256"}, "0x-1", `cannot encode string as int256: invalid integer string "0x-1"`},
+		{ContractABIArgument{Type: "int256"}, "--1", `cannot encode string as int256: invalid integer string "--1"`},
+		{ContractABIArgument{Type: "uint256[2]"}, []int{1}, "cannot encode uint256[2]: expected 2 elements, got 1"},
+		{ContractABIArgument{Type: "uint256[]"}, 1, "cannot encode int as uint256[]: not a slice or array"},
+		{ContractABIArgument{Type: "tuple", Components: []ContractABIArgument{{Name: "a", Type: "bool"}}}, map[string]interface{}{}, `cannot encode tuple: missing value for component "a"`},
+		{ContractABIArgument{Type: "tuple", Components: []ContractABIArgument{{Name: "a", Type: "bool"}}}, []interface{}{}, "argument count mismatch: expected 1, got 0"},
+		{ContractABIArgument{Type: "decimal"}, 1, "unknown type: decimal"},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := EncodeValue(test.arg, test.value)
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestContractABIFunction_Pack(t *testing.T) {
+	transfer := ContractABIFunction{
+		Type: "function",
+		Name: "transfer",
+		Inputs: []ContractABIArgument{
+			{Name: "to", Type: "address"},
+			{Name: "amount", Type: "uint256"},
+		},
+	}
+
+	calldata, err := transfer.Pack(NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"), big.NewInt(1000))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a9059cbb"+
+		"0000000000000000000000001932c48b2bf8102ba33b4a6b545c32236e342f34"+
+		"00000000000000000000000000000000000000000000000000000000000003e8", hex.EncodeToString(calldata))
+
+	parsed, err := transfer.Parse(calldata[4:])
+	assert.Nil(t, err)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", parsed["to"])
+	assert.EqualValues(t, big.NewInt(1000), parsed["amount"])
+}
+
+func TestContractABIFunction_Pack_DynamicTypes(t *testing.T) {
+	//Example taken from the Solidity ABI specification
+	function := ContractABIFunction{
+		Type: "function",
+		Name: "f",
+		Inputs: []ContractABIArgument{
+			{Type: "uint256"},
+			{Type: "uint32[]"},
+			{Type: "bytes10"},
+			{Type: "bytes"},
+		},
+	}
+
+	calldata, err := function.Pack(0x123, []uint32{0x456, 0x789}, []byte("1234567890"), []byte("Hello, world!"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "8be65246"+
+		"0000000000000000000000000000000000000000000000000000000000000123"+
+		"0000000000000000000000000000000000000000000000000000000000000080"+
+		"3132333435363738393000000000000000000000000000000000000000000000"+
+		"00000000000000000000000000000000000000000000000000000000000000e0"+
+		"0000000000000000000000000000000000000000000000000000000000000002"+
+		"0000000000000000000000000000000000000000000000000000000000000456"+
+		"0000000000000000000000000000000000000000000000000000000000000789"+
+		"000000000000000000000000000000000000000000000000000000000000000d"+
+		"48656c6c6f2c20776f726c642100000000000000000000000000000000000000", hex.EncodeToString(calldata))
+}
@@ -0,0 +1,163 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ConsenSys/quorum-go-utils/log"
+)
+
+// ContractABIError is a custom error, defined in Solidity >=0.8.4 using `error Name(...)`
+// and raised with `revert Name(...)`
+type ContractABIError struct {
+	Type   string
+	Name   string
+	Inputs []ContractABIArgument
+}
+
+func (abiErr ContractABIError) String() string {
+	var inputSigs []string
+	for _, input := range abiErr.Inputs {
+		inputSigs = append(inputSigs, input.String())
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(inputSigs, ","))
+}
+
+func (abiErr ContractABIError) StringNoName() string {
+	var inputSigs []string
+	for _, input := range abiErr.Inputs {
+		inputSigs = append(inputSigs, input.StringNoName())
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(inputSigs, ","))
+}
+
+// Signature gives the 4 byte selector that the revert data of the error starts with
+func (abiErr ContractABIError) Signature() string {
+	return hex.EncodeToString(hash(abiErr.StringNoName())[:4])
+}
+
+// Parse decodes the arguments of the error from the revert data, without the selector
+func (abiErr ContractABIError) Parse(data []byte) (map[string]interface{}, error) {
+	values, err := abiErr.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return values.ToMap(), nil
+}
+
+// Decode decodes the arguments of the error in the same way as Parse, but keeps them in order
+func (abiErr ContractABIError) Decode(data []byte) (DecodedValues, error) {
+	return abiErr.DecodeWithOptions(data, DecodeOptions{})
+}
+
+// DecodeWithOptions decodes the arguments of the error in the same way as Decode, with the given options
+func (abiErr ContractABIError) DecodeWithOptions(data []byte, opts DecodeOptions) (DecodedValues, error) {
+	return newABIDecoder(data, opts).decodeArguments(abiErr.Inputs, data, abiErr.Name)
+}
+
+var (
+	// RevertReasonError is the error used by `require` and `revert("reason")`
+	RevertReasonError = ContractABIError{"error", "Error", []ContractABIArgument{{Name: "reason", Type: "string"}}}
+	// PanicError is the error raised for failed assertions and runtime errors such as division by zero
+	PanicError = ContractABIError{"error", "Panic", []ContractABIArgument{{Name: "code", Type: "uint256"}}}
+)
+
+// PanicReasons describes the codes of a Panic(uint256) error
+// See https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var PanicReasons = map[uint64]string{
+	0x00: "generic compiler inserted panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "conversion to an invalid enum value",
+	0x22: "access to an incorrectly encoded storage byte array",
+	0x31: "pop on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "too much memory allocated",
+	0x51: "call to an uninitialised internal function",
+}
+
+// RevertError is the result of a reverted transaction or call, with the revert data decoded
+// where it matches Error(string), Panic(uint256) or one of the custom errors from the ABI.
+// Selector is the first 4 bytes of the data, which is kept even if the data couldn't be decoded
+type RevertError struct {
+	Data       HexData                `json:"data"`
+	Selector   HexData                `json:"selector,omitempty"`
+	Sig        string                 `json:"errorSig,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	PanicCode  *big.Int               `json:"panicCode,omitempty"`
+	ParsedData map[string]interface{} `json:"parsedData,omitempty"`
+}
+
+func (err *RevertError) Error() string {
+	switch {
+	case err.Reason != "":
+		return "execution reverted: " + err.Reason
+	case err.Sig != "":
+		return "execution reverted: " + err.Sig
+	case len(err.Data) > 0:
+		return "execution reverted: unknown error " + err.Data.String()
+	}
+	return "execution reverted"
+}
+
+// DecodeRevert decodes revert data that is either an Error(string) or a Panic(uint256), see ContractABI.DecodeRevert
+func DecodeRevert(data []byte) *RevertError {
+	return (&ContractABI{}).DecodeRevert(data)
+}
+
+// DecodeRevert decodes revert data, which is either an Error(string), a Panic(uint256), or one of the custom
+// errors in the ABI. Data that doesn't match any of them, or that has the selector of one of them but isn't
+// a valid encoding of its arguments, is kept as it is in the RevertError along with its selector, so decoding
+// never fails
+func (abi *ContractABI) DecodeRevert(data []byte) *RevertError {
+	return abi.DecodeRevertWithOptions(data, DecodeOptions{})
+}
+
+// DecodeRevertWithOptions decodes revert data in the same way as DecodeRevert, with the given options
+func (abi *ContractABI) DecodeRevertWithOptions(data []byte, opts DecodeOptions) *RevertError {
+	revertErr := &RevertError{Data: HexData(hex.EncodeToString(data))}
+	if len(data) < 4 {
+		return revertErr
+	}
+
+	selector := hex.EncodeToString(data[:4])
+	revertErr.Selector = HexData(selector)
+	for _, abiErr := range append([]ContractABIError{RevertReasonError, PanicError}, abi.Errors...) {
+		if abiErr.Signature() != selector {
+			continue
+		}
+		values, err := abiErr.DecodeWithOptions(data[4:], opts)
+		if err != nil {
+			log.Debug("Could not decode revert data", "error", abiErr.String(), "data", revertErr.Data.String(), "err", err)
+			continue
+		}
+
+		result := values.ToMap()
+		revertErr.Sig = abiErr.String()
+		revertErr.ParsedData = result
+		switch selector {
+		case RevertReasonError.Signature():
+			revertErr.Reason, _ = result["reason"].(string)
+		case PanicError.Signature():
+			revertErr.PanicCode, _ = result["code"].(*big.Int)
+			revertErr.Reason = panicReason(revertErr.PanicCode)
+		}
+		return revertErr
+	}
+	return revertErr
+}
+
+func panicReason(code *big.Int) string {
+	if code == nil {
+		return ""
+	}
+	if code.IsUint64() {
+		if description, ok := PanicReasons[code.Uint64()]; ok {
+			return fmt.Sprintf("panic 0x%x (%s)", code, description)
+		}
+	}
+	return fmt.Sprintf("panic 0x%x", code)
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const customErrorABI = `[
+	{"type":"function","name":"withdraw","inputs":[{"name":"amount","type":"uint256"}],"outputs":[]},
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+]`
+
+func TestABIStructure_ToInternalABI_Errors(t *testing.T) {
+	structure, err := NewABIStructureFromJSON(customErrorABI)
+	assert.Nil(t, err)
+
+	abi := structure.ToInternalABI()
+
+	assert.Len(t, abi.Functions, 1)
+	if assert.Len(t, abi.Errors, 1) {
+		assert.Equal(t, "InsufficientBalance(uint256 available,uint256 required)", abi.Errors[0].String())
+		assert.Equal(t, "cf479181", abi.Errors[0].Signature())
+	}
+}
+
+func TestContractABI_DecodeRevert(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(customErrorABI)
+	abi := structure.ToInternalABI()
+
+	testMatrix := []struct {
+		data           string
+		expectedSig    string
+		expectedReason string
+		expectedError  string
+	}{
+		{"08c379a0" + word("20") + word("4") + "6e6f7065" + word("")[8:], "Error(string reason)", "nope", "execution reverted: nope"},
+		{"4e487b71" + word("12"), "Panic(uint256 code)", "panic 0x12 (division or modulo by zero)", "execution reverted: panic 0x12 (division or modulo by zero)"},
+		{"4e487b71" + word("99"), "Panic(uint256 code)", "panic 0x99", "execution reverted: panic 0x99"},
+		{"cf479181" + word("1") + word("2"), "InsufficientBalance(uint256 available,uint256 required)", "", "execution reverted: InsufficientBalance(uint256 available,uint256 required)"},
+		{"12345678", "", "", "execution reverted: unknown error 0x12345678"},
+		{"", "", "", "execution reverted"},
+	}
+
+	for idx, test := range testMatrix {
+		revertErr := abi.DecodeRevert(hexToBytes(test.data))
+
+		assert.Equal(t, test.expectedSig, revertErr.Sig, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedReason, revertErr.Reason, "Test index %d failed", idx)
+		assert.EqualError(t, revertErr, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestContractABI_DecodeRevert_CustomErrorValues(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(customErrorABI)
+
+	revertErr := structure.ToInternalABI().DecodeRevert(hexToBytes("cf479181" + word("1") + word("2")))
+
+	assert.EqualValues(t, big.NewInt(1), revertErr.ParsedData["available"])
+	assert.EqualValues(t, big.NewInt(2), revertErr.ParsedData["required"])
+	assert.Nil(t, revertErr.PanicCode)
+}
+
+func TestDecodeRevert_Malformed(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(customErrorABI)
+	abi := structure.ToInternalABI()
+
+	testMatrix := []string{
+		"08c379a0" + word("20") + word("ff"),
+		"4e487b71" + "11",
+		"cf479181" + word("1"),
+	}
+
+	for idx, data := range testMatrix {
+		revertErr := abi.DecodeRevert(hexToBytes(data))
+
+		assert.Equal(t, HexData(data), revertErr.Data, "Test index %d failed", idx)
+		assert.Equal(t, HexData(data[:8]), revertErr.Selector, "Test index %d failed", idx)
+		assert.Equal(t, "", revertErr.Sig, "Test index %d failed", idx)
+		assert.Nil(t, revertErr.ParsedData, "Test index %d failed", idx)
+		assert.EqualError(t, revertErr, "execution reverted: unknown error 0x"+data, "Test index %d failed", idx)
+	}
+}
+
+func TestParsedTransaction_ParseTransaction_RevertedMalformed(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(customErrorABI)
+	input, _ := structure.ToInternalABI().Functions[0].Pack(3)
+
+	ptx := &ParsedTransaction{
+		RawTransaction: &Transaction{
+			Status: false,
+			To:     NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"),
+			Data:   HexData(hex.EncodeToString(input)),
+			Output: NewHexData("08c379a0" + word("20") + word("ff")),
+		},
+	}
+
+	err := ptx.ParseTransaction(customErrorABI)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, big.NewInt(3), ptx.ParsedData["amount"])
+	if assert.NotNil(t, ptx.Revert) {
+		assert.Equal(t, HexData("08c379a0"), ptx.Revert.Selector)
+		assert.Equal(t, NewHexData("08c379a0"+word("20")+word("ff")), ptx.Revert.Data)
+	}
+}
+
+func TestParsedTransaction_ParseTransaction_Reverted(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(customErrorABI)
+	input, _ := structure.ToInternalABI().Functions[0].Pack(3)
+
+	ptx := &ParsedTransaction{
+		RawTransaction: &Transaction{
+			Status: false,
+			To:     NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"),
+			Data:   HexData(hex.EncodeToString(input)),
+			Output: NewHexData("cf479181" + word("1") + word("3")),
+		},
+	}
+
+	err := ptx.ParseTransaction(customErrorABI)
+
+	assert.Nil(t, err)
+	assert.Nil(t, ptx.ParsedOutput)
+	if assert.NotNil(t, ptx.Revert) {
+		assert.Equal(t, "InsufficientBalance(uint256 available,uint256 required)", ptx.Revert.Sig)
+		assert.EqualValues(t, big.NewInt(3), ptx.Revert.ParsedData["required"])
+	}
+}
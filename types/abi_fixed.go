@@ -0,0 +1,74 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+/*
+Fixed point numbers are given as fixed<M>x<N> and ufixed<M>x<N>, where M is the number of bits (8 to 256, in multiples
+of 8) and N is the number of decimal places (1 to 80). The value v is encoded as the integer v * 10^N, in the same way
+as an int<M> or uint<M> would be. "fixed" and "ufixed" are aliases for fixed128x18 and ufixed128x18.
+
+Decoded values are given as exact decimal strings, such as "-1.5" or "100", since they can't always be represented as
+a float64 without losing precision.
+*/
+
+// FormatFixed renders the integer value of a fixed point number with the given number of decimals as an exact
+// decimal string, without any trailing zeroes, e.g. 1500 with 3 decimals is "1.5"
+func FormatFixed(value *big.Int, decimals uint) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	integer, fraction := new(big.Int).QuoRem(new(big.Int).Abs(value), scale, new(big.Int))
+
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+	}
+	if fraction.Sign() == 0 {
+		return sign + integer.String()
+	}
+	fractionDigits := fmt.Sprintf("%0*s", decimals, fraction.String())
+	return sign + integer.String() + "." + strings.TrimRight(fractionDigits, "0")
+}
+
+// ParseFixed parses a decimal string into the integer value of a fixed point number with the given number of decimals,
+// failing if the string has more decimal places than the type allows
+func ParseFixed(value string, decimals uint) (*big.Int, error) {
+	//at most one sign, so that "-+1" is rejected along with any other stray character
+	digits := value
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	integer, fraction := digits, ""
+	if dot := strings.Index(digits, "."); dot >= 0 {
+		integer, fraction = digits[:dot], digits[dot+1:]
+	}
+	if integer == "" && fraction == "" {
+		return nil, fmt.Errorf("invalid decimal %q", value)
+	}
+	for _, c := range integer + fraction {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid decimal %q", value)
+		}
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if uint(len(fraction)) > decimals {
+		return nil, fmt.Errorf("%s has more than %d decimal places", value, decimals)
+	}
+	result, _ := new(big.Int).SetString("0"+integer+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	if strings.HasPrefix(value, "-") {
+		result.Neg(result)
+	}
+	return result, nil
+}
+
+// fixedFromRat gives the integer value of a fixed point number from a rational, failing if it can't be represented exactly
+func fixedFromRat(value *big.Rat, decimals uint) (*big.Int, error) {
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("%s has more than %d decimal places", value.FloatString(int(decimals)+1), decimals)
+	}
+	return scaled.Num(), nil
+}
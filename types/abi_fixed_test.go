@@ -0,0 +1,167 @@
+package types
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFixed(t *testing.T) {
+	testMatrix := []struct {
+		value    int64
+		decimals uint
+		expected string
+	}{
+		{1500, 3, "1.5"},
+		{-1500, 3, "-1.5"},
+		{1, 18, "0.000000000000000001"},
+		{-1, 2, "-0.01"},
+		{3000, 3, "3"},
+		{0, 18, "0"},
+		{123456, 1, "12345.6"},
+	}
+
+	for idx, test := range testMatrix {
+		formatted := FormatFixed(big.NewInt(test.value), test.decimals)
+		assert.Equal(t, test.expected, formatted, "Test index %d failed", idx)
+
+		parsed, err := ParseFixed(formatted, test.decimals)
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.EqualValues(t, big.NewInt(test.value), parsed, "Test index %d failed", idx)
+	}
+}
+
+func TestParseFixed_Errors(t *testing.T) {
+	testMatrix := []struct {
+		value         string
+		decimals      uint
+		expectedError string
+	}{
+		{"1.234", 2, "1.234 has more than 2 decimal places"},
+		{"", 2, `invalid decimal ""`},
+		{".", 2, `invalid decimal "."`},
+		{"1e5", 2, `invalid decimal "1e5"`},
+		{"1.2.3", 2, `invalid decimal "1.2.3"`},
+		{"-+1", 2, `invalid decimal "-+1"`},
+		{"+-1", 2, `invalid decimal "+-1"`},
+		{"--1", 2, `invalid decimal "--1"`},
+		{"-", 2, `invalid decimal "-"`},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := ParseFixed(test.value, test.decimals)
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+
+	parsed, err := ParseFixed("1.2300", 2)
+	assert.Nil(t, err)
+	assert.EqualValues(t, big.NewInt(123), parsed)
+}
+
+func TestParseAllData_FixedPoint(t *testing.T) {
+	args := []ContractABIArgument{
+		{Name: "signed", Type: "fixed128x18"},
+		{Name: "unsigned", Type: "ufixed8x1"},
+		{Name: "alias", Type: "fixed"},
+	}
+	data := hexToBytes("ffffffffffffffffffffffffffffffffffffffffffffffffeb2eedf284ea0000" + word("ff") + word("de0b6b3a7640000"))
+
+	result, err := ParseAllData(args, data)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"signed": "-1.5", "unsigned": "25.5", "alias": "1"}, result)
+
+	encoded, err := EncodeAllData(args, []interface{}{"-1.5", big.NewRat(51, 2), 1})
+	assert.Nil(t, err)
+	assert.Equal(t, data, encoded)
+
+	var unpacked struct {
+		Signed   string
+		Unsigned *big.Rat
+		Alias    interface{}
+	}
+	assert.Nil(t, UnpackAllData(args, data, &unpacked))
+	assert.Equal(t, "-1.5", unpacked.Signed)
+	assert.Equal(t, big.NewRat(51, 2), unpacked.Unsigned)
+	assert.Equal(t, "1", unpacked.Alias)
+}
+
+func TestEncodeValue_FixedPointErrors(t *testing.T) {
+	testMatrix := []struct {
+		arg           ContractABIArgument
+		value         interface{}
+		expectedError string
+	}{
+		{ContractABIArgument{Type: "ufixed8x1"}, "25.6", "value 25.6 overflows ufixed8x1"},
+		{ContractABIArgument{Type: "ufixed8x1"}, "-0.1", "value -0.1 overflows ufixed8x1"},
+		{ContractABIArgument{Type: "fixed8x1"}, "12.8", "value 12.8 overflows fixed8x1"},
+		{ContractABIArgument{Type: "fixed8x1"}, "0.05", "cannot encode string as fixed8x1: 0.05 has more than 1 decimal places"},
+		{ContractABIArgument{Type: "fixed8x1"}, big.NewRat(1, 3), "cannot encode *big.Rat as fixed8x1: 0.33 has more than 1 decimal places"},
+		{ContractABIArgument{Type: "fixed8x0"}, "1", "invalid fixed point type: fixed8x0, the size must be a multiple of 8 from 8 to 256 and the decimals from 1 to 80"},
+		{ContractABIArgument{Type: "fixed7x1"}, "1", "invalid fixed point type: fixed7x1, the size must be a multiple of 8 from 8 to 256 and the decimals from 1 to 80"},
+		{ContractABIArgument{Type: "fixed8"}, "1", "invalid fixed point type: fixed8, the size must be a multiple of 8 from 8 to 256 and the decimals from 1 to 80"},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := EncodeValue(test.arg, test.value)
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestParseAllData_Function(t *testing.T) {
+	args := []ContractABIArgument{{Name: "callback", Type: "function"}, {Name: "callbacks", Type: "function[]"}}
+	callback := "1932c48b2bf8102ba33b4a6b545c32236e342f34a9059cbb"
+	data := hexToBytes(callback + "0000000000000000" + word("40") + word("1") + callback + "0000000000000000")
+
+	result, err := ParseAllData(args, data)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0x"+callback, result["callback"])
+	assert.Equal(t, []interface{}{"0x" + callback}, result["callbacks"])
+
+	encoded, err := EncodeAllData(args, []interface{}{result["callback"], result["callbacks"]})
+	assert.Nil(t, err)
+	assert.Equal(t, data, encoded)
+
+	var unpacked struct {
+		Callback  [24]byte
+		Callbacks [][]byte
+	}
+	assert.Nil(t, UnpackAllData(args, data, &unpacked))
+	assert.Equal(t, callback, hex.EncodeToString(unpacked.Callback[:]))
+	assert.Equal(t, callback, hex.EncodeToString(unpacked.Callbacks[0]))
+
+	_, err = EncodeValue(args[0], "0x1932c48b2bf8102ba33b4a6b545c32236e342f34")
+	assert.EqualError(t, err, "cannot encode 20 bytes as function")
+}
+
+func TestContractABIFunction_Signature_CanonicalTypes(t *testing.T) {
+	testMatrix := []struct {
+		function          ContractABIFunction
+		expectedSignature string
+		expectedString    string
+	}{
+		{
+			ContractABIFunction{Name: "transfer", Inputs: []ContractABIArgument{{Name: "to", Type: "address"}, {Name: "value", Type: "uint"}}},
+			"a9059cbb",
+			"transfer(address to,uint256 value)",
+		},
+		{
+			ContractABIFunction{Name: "f", Inputs: []ContractABIArgument{{Type: "int[2][]"}, {Type: "fixed"}, {Type: "ufixed[]"}}},
+			hex.EncodeToString(hash("f(int256[2][],fixed128x18,ufixed128x18[])")[:4]),
+			"f(int256[2][] ,fixed128x18 ,ufixed128x18[] )",
+		},
+		{
+			ContractABIFunction{Name: "g", Inputs: []ContractABIArgument{{Name: "t", Type: "tuple[]", Components: []ContractABIArgument{{Name: "a", Type: "uint"}}}}},
+			hex.EncodeToString(hash("g((uint256)[])")[:4]),
+			"g((uint256 a)[] t)",
+		},
+	}
+
+	for idx, test := range testMatrix {
+		assert.Equal(t, test.expectedSignature, test.function.Signature(), "Test index %d failed", idx)
+		assert.Equal(t, test.expectedString, test.function.String(), "Test index %d failed", idx)
+	}
+}
@@ -0,0 +1,330 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+The human-readable ABI describes each entry of an ABI as it would be declared in Solidity, such as:
+
+	function transfer(address to, uint256 amount) returns (bool)
+	function balanceOf(address owner) view returns (uint256)
+	function submit(tuple(address to, uint256[2] values)[] orders) payable
+	event Transfer(address indexed from, address indexed to, uint256 value)
+	error InsufficientBalance(uint256 available, uint256 required)
+	constructor(string name, string symbol)
+	receive() external payable
+	fallback()
+
+Tuples can be written either as "tuple(...)" or just "(...)". Visibility and data location keywords
+(external, public, memory, calldata, storage) are accepted but ignored, and "uint"/"int"/"fixed"/"ufixed" are
+read as "uint256"/"int256"/"fixed128x18"/"ufixed128x18" so that signatures are calculated correctly.
+*/
+
+// NewABIStructureFromHumanReadable parses an ABI given as a list of human-readable entries
+func NewABIStructureFromHumanReadable(entries []string) (ABIStructure, error) {
+	var structure ABIStructure
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parsed, err := NewABIStructureEntryFromHumanReadable(entry)
+		if err != nil {
+			return nil, err
+		}
+		structure = append(structure, parsed)
+	}
+	return structure, nil
+}
+
+// NewABIStructureEntryFromHumanReadable parses a single human-readable ABI entry.
+// An entry without a leading keyword is treated as a function
+func NewABIStructureEntryFromHumanReadable(entry string) (ABIStructureEntry, error) {
+	p := &humanReadableParser{input: entry}
+	parsed, err := p.parseEntry()
+	if err == nil {
+		err = parsed.Validate()
+	}
+	if err != nil {
+		return ABIStructureEntry{}, fmt.Errorf("invalid human-readable ABI entry %q: %s", entry, err.Error())
+	}
+	return parsed, nil
+}
+
+// ToHumanReadable gives the human-readable form of each entry in the ABI
+func (abi ABIStructure) ToHumanReadable() []string {
+	entries := make([]string, 0, len(abi))
+	for _, entry := range abi {
+		entries = append(entries, entry.HumanReadable())
+	}
+	return entries
+}
+
+// HumanReadable gives the entry as it would be declared in Solidity
+func (entry ABIStructureEntry) HumanReadable() string {
+	var sb strings.Builder
+
+	switch entry.Type {
+	case "constructor", "receive", "fallback":
+		sb.WriteString(entry.Type)
+	case "", "function":
+		sb.WriteString("function " + entry.Name)
+	default:
+		sb.WriteString(entry.Type + " " + entry.Name)
+	}
+	sb.WriteString(humanReadableArguments(entry.Inputs, entry.Type == "event"))
+
+	switch entry.Type {
+	case "event":
+		if entry.Anonymous {
+			sb.WriteString(" anonymous")
+		}
+	case "receive", "fallback":
+		sb.WriteString(" external")
+		fallthrough
+	case "", "function", "constructor":
+		if mutability := entry.Mutability(); mutability != "nonpayable" {
+			sb.WriteString(" " + mutability)
+		}
+	}
+
+	if len(entry.Outputs) > 0 {
+		sb.WriteString(" returns " + humanReadableArguments(entry.Outputs, false))
+	}
+	return sb.String()
+}
+
+func humanReadableArguments(args []ABIStructureArgument, isEvent bool) string {
+	var argSigs []string
+	for _, arg := range args {
+		argSigs = append(argSigs, arg.humanReadable(isEvent))
+	}
+	return "(" + strings.Join(argSigs, ", ") + ")"
+}
+
+func (arg ABIStructureArgument) humanReadable(isEvent bool) string {
+	argType := arg.Type
+	if strings.HasPrefix(argType, "tuple") {
+		argType = "tuple" + humanReadableArguments(arg.Components, false) + argType[5:]
+	}
+	if isEvent && arg.Indexed {
+		argType += " indexed"
+	}
+	if arg.Name != "" {
+		argType += " " + arg.Name
+	}
+	return argType
+}
+
+// humanReadableParser is a recursive descent parser over a single human-readable ABI entry
+type humanReadableParser struct {
+	input string
+	pos   int
+}
+
+func (p *humanReadableParser) parseEntry() (ABIStructureEntry, error) {
+	var entry ABIStructureEntry
+
+	start := p.pos
+	switch keyword := p.word(); keyword {
+	case "function", "event", "error":
+		entry.Type = keyword
+		entry.Name = p.word()
+	case "constructor", "receive", "fallback":
+		entry.Type = keyword
+	default:
+		//no keyword, so this is the name of a function
+		p.pos = start
+		entry.Type = "function"
+		entry.Name = p.word()
+	}
+	if entry.Type != "constructor" && entry.Type != "receive" && entry.Type != "fallback" && entry.Name == "" {
+		return entry, errors.New("missing name")
+	}
+
+	inputs, err := p.parseArguments(entry.Type == "event")
+	if err != nil {
+		return entry, err
+	}
+	entry.Inputs = inputs
+
+	//modifiers, which may be followed by the outputs of a function
+	for {
+		modifier := p.word()
+		switch {
+		case modifier == "":
+			if p.skipSpace(); p.pos != len(p.input) {
+				return entry, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+			}
+			if entry.StateMutability == "" {
+				entry.StateMutability = defaultStateMutability(entry.Type)
+			}
+			return entry, nil
+		case modifier == "anonymous" && entry.Type == "event":
+			entry.Anonymous = true
+		case modifier == "returns" && entry.Type == "function" && entry.Outputs == nil:
+			outputs, err := p.parseArguments(false)
+			if err != nil {
+				return entry, err
+			}
+			entry.Outputs = outputs
+			if entry.Outputs == nil {
+				entry.Outputs = []ABIStructureArgument{}
+			}
+		case (modifier == "external" || modifier == "public") && entry.Type != "event" && entry.Type != "error":
+		case isStateMutability(modifier) && entry.Type != "event" && entry.Type != "error":
+			if entry.StateMutability != "" {
+				return entry, fmt.Errorf("more than one state mutability given, %s and %s", entry.StateMutability, modifier)
+			}
+			entry.StateMutability = modifier
+			if modifier == "constant" {
+				entry.StateMutability = "view"
+			}
+		default:
+			return entry, fmt.Errorf("unexpected %q", modifier)
+		}
+	}
+}
+
+func defaultStateMutability(entryType string) string {
+	switch entryType {
+	case "function", "constructor", "fallback":
+		return "nonpayable"
+	case "receive":
+		return "payable"
+	}
+	return ""
+}
+
+func isStateMutability(word string) bool {
+	return word == "pure" || word == "view" || word == "constant" || word == "payable" || word == "nonpayable"
+}
+
+//parseArguments parses a bracketed, comma separated list of arguments
+func (p *humanReadableParser) parseArguments(isEvent bool) ([]ABIStructureArgument, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if p.peek() == ')' {
+		p.pos++
+		return nil, nil
+	}
+
+	var args []ABIStructureArgument
+	for {
+		arg, err := p.parseArgument(isEvent)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		p.skipSpace()
+		if p.pos < len(p.input) && p.input[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+//parseArgument parses a single argument, which is a type followed by any keywords and the name
+func (p *humanReadableParser) parseArgument(isEvent bool) (ABIStructureArgument, error) {
+	var arg ABIStructureArgument
+
+	switch baseType := p.word(); {
+	case (baseType == "tuple" || baseType == "") && p.peek() == '(':
+		components, err := p.parseArguments(false)
+		if err != nil {
+			return arg, err
+		}
+		arg.Type = "tuple"
+		arg.Components = components
+	case baseType == "" || baseType == "tuple":
+		return arg, fmt.Errorf("missing type at position %d", p.pos)
+	default:
+		arg.Type = canonicalType(baseType)
+	}
+
+	suffix, err := p.arraySuffix()
+	if err != nil {
+		return arg, err
+	}
+	arg.Type += suffix
+
+	for {
+		start := p.pos
+		switch word := p.word(); {
+		case word == "indexed" && isEvent:
+			arg.Indexed = true
+		case word == "memory" || word == "calldata" || word == "storage":
+		case word == "payable" && arg.Type == "address":
+		case word != "" && arg.Name == "":
+			arg.Name = word
+		default:
+			p.pos = start
+			return arg, nil
+		}
+	}
+}
+
+//arraySuffix reads any number of array dimensions following a type, e.g. "[2][]"
+func (p *humanReadableParser) arraySuffix() (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] == '[' {
+		end := strings.IndexByte(p.input[p.pos:], ']')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed array at position %d", p.pos)
+		}
+		for _, c := range p.input[p.pos+1 : p.pos+end] {
+			if c < '0' || c > '9' {
+				return "", fmt.Errorf("invalid array size %q", p.input[p.pos+1:p.pos+end])
+			}
+		}
+		p.pos += end + 1
+	}
+	return p.input[start:p.pos], nil
+}
+
+//word reads the next identifier, or returns an empty string if the next character doesn't start one
+func (p *humanReadableParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifierChar(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *humanReadableParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *humanReadableParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.pos >= len(p.input) {
+			return fmt.Errorf("expected %q, got the end of the entry", c)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", c, p.pos, p.input[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+func (p *humanReadableParser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n' || p.input[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func isIdentifierChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '$'
+}
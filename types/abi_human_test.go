@@ -0,0 +1,150 @@
+package types
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var humanReadableERC20 = []string{
+	"constructor(string name, string symbol)",
+	"function transfer(address to, uint amount) returns (bool)",
+	"function balanceOf(address owner) view returns (uint256)",
+	"event Transfer(address indexed from, address indexed to, uint256 value)",
+	"error InsufficientBalance(uint256 available, uint256 required)",
+}
+
+const jsonERC20 = `[
+	{"type":"constructor","inputs":[{"name":"name","type":"string"},{"name":"symbol","type":"string"}],"stateMutability":"nonpayable"},
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"},
+	{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}],"anonymous":false},
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+]`
+
+func TestNewABIStructureFromHumanReadable_MatchesJSON(t *testing.T) {
+	fromHuman, err := NewABIStructureFromHumanReadable(humanReadableERC20)
+	assert.Nil(t, err)
+
+	fromJSON, err := NewABIStructureFromJSON(jsonERC20)
+	assert.Nil(t, err)
+
+	assert.Equal(t, fromJSON, fromHuman)
+
+	abi := fromHuman.ToInternalABI()
+	assert.Equal(t, "a9059cbb", abi.Functions[0].Signature())
+	assert.Equal(t, "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", abi.Events[0].Signature())
+	assert.Equal(t, "cf479181", abi.Errors[0].Signature())
+}
+
+func TestNewABIStructureEntryFromHumanReadable(t *testing.T) {
+	testMatrix := []struct {
+		input    string
+		expected ABIStructureEntry
+	}{
+		{
+			"function submit(tuple(address to, uint256[2] values)[] calldata orders) external payable",
+			ABIStructureEntry{Type: "function", Name: "submit", StateMutability: "payable", Inputs: []ABIStructureArgument{
+				{Name: "orders", Type: "tuple[]", Components: []ABIStructureArgument{{Name: "to", Type: "address"}, {Name: "values", Type: "uint256[2]"}}},
+			}},
+		},
+		{
+			"function nested((uint8, (bool, string)[])[3][] memory) pure returns ((int, bytes32))",
+			ABIStructureEntry{Type: "function", Name: "nested", StateMutability: "pure",
+				Inputs: []ABIStructureArgument{{Type: "tuple[3][]", Components: []ABIStructureArgument{
+					{Type: "uint8"},
+					{Type: "tuple[]", Components: []ABIStructureArgument{{Type: "bool"}, {Type: "string"}}},
+				}}},
+				Outputs: []ABIStructureArgument{{Type: "tuple", Components: []ABIStructureArgument{{Type: "int256"}, {Type: "bytes32"}}}},
+			},
+		},
+		{
+			"totalSupply() constant returns (uint)",
+			ABIStructureEntry{Type: "function", Name: "totalSupply", StateMutability: "view", Outputs: []ABIStructureArgument{{Type: "uint256"}}},
+		},
+		{
+			"function pay(address payable to)",
+			ABIStructureEntry{Type: "function", Name: "pay", StateMutability: "nonpayable", Inputs: []ABIStructureArgument{{Name: "to", Type: "address"}}},
+		},
+		{
+			"event Log(string indexed, bytes data) anonymous",
+			ABIStructureEntry{Type: "event", Name: "Log", Anonymous: true, Inputs: []ABIStructureArgument{{Type: "string", Indexed: true}, {Name: "data", Type: "bytes"}}},
+		},
+		{
+			"receive() external payable",
+			ABIStructureEntry{Type: "receive", StateMutability: "payable"},
+		},
+		{
+			"fallback() external",
+			ABIStructureEntry{Type: "fallback", StateMutability: "nonpayable"},
+		},
+		{
+			"constructor() payable",
+			ABIStructureEntry{Type: "constructor", StateMutability: "payable"},
+		},
+	}
+
+	for idx, test := range testMatrix {
+		entry, err := NewABIStructureEntryFromHumanReadable(test.input)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, entry, "Test index %d failed", idx)
+	}
+}
+
+func TestNewABIStructureEntryFromHumanReadable_Errors(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		expectedError string
+	}{
+		{"function (uint256)", "missing name"},
+		{"function f(uint256", `expected ')', got the end of the entry`},
+		{"function f(uint256[x])", `invalid array size "x"`},
+		{"function f(uint256[2)", "unclosed array at position 18"},
+		{"function f(, uint256)", "missing type at position 11"},
+		{"function f() view pure", "more than one state mutability given, view and pure"},
+		{"event E(uint256) view", `unexpected "view"`},
+		{"function f(uint256 indexed a)", `expected ')' at position 27, got 'a'`},
+		{"function f() returns (bool) returns (bool)", `unexpected "returns"`},
+		{"function f();", `unexpected ';' at position 12`},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := NewABIStructureEntryFromHumanReadable(test.input)
+
+		assert.EqualError(t, err, "invalid human-readable ABI entry "+strconv.Quote(test.input)+": "+test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestContractABI_ToHumanReadable(t *testing.T) {
+	structure, _ := NewABIStructureFromHumanReadable(append(humanReadableERC20,
+		"function submit((address to, uint256[2] values)[] orders) external payable",
+		"event Log(string indexed, bytes data) anonymous",
+	))
+
+	assert.Equal(t, []string{
+		"constructor(string name, string symbol)",
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function submit(tuple(address to, uint256[2] values)[] orders) payable",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Log(string indexed, bytes data) anonymous",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	}, structure.ToInternalABI().ToHumanReadable())
+}
+
+func TestABIStructure_ToHumanReadableLegacyFlags(t *testing.T) {
+	structure, err := NewABIStructureFromJSON(`[
+		{"type":"function","name":"f","constant":true,"inputs":[],"outputs":[]},
+		{"type":"function","name":"g","payable":true,"inputs":[],"outputs":[]},
+		{"type":"function","name":"h","constant":false,"payable":false,"inputs":[],"outputs":[]}
+	]`)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"function f() view",
+		"function g() payable",
+		"function h()",
+	}, structure.ToHumanReadable())
+}
@@ -1,15 +1,40 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Defined according to https://solidity.readthedocs.io/en/develop/abi-spec.html#json
 
 type ABIStructure []ABIStructureEntry
 
+// NewABIStructureFromJSON parses a JSON ABI, rejecting any entry with an argument of an invalid type
 func NewABIStructureFromJSON(abi string) (ABIStructure, error) {
 	var structure ABIStructure
-	err := json.Unmarshal([]byte(abi), &structure)
-	return structure, err
+	if err := json.Unmarshal([]byte(abi), &structure); err != nil {
+		return nil, err
+	}
+	if err := structure.Validate(); err != nil {
+		return nil, err
+	}
+	return structure, nil
+}
+
+// Validate checks that the type of every argument in the ABI is valid, see ParseABIType
+func (abi ABIStructure) Validate() error {
+	for _, entry := range abi {
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToJSON gives the JSON form of the ABI
+func (abi ABIStructure) ToJSON() (string, error) {
+	out, err := json.Marshal(abi)
+	return string(out), err
 }
 
 func (abi ABIStructure) ToInternalABI() *ContractABI {
@@ -17,15 +42,20 @@ func (abi ABIStructure) ToInternalABI() *ContractABI {
 
 	for _, entry := range abi {
 		switch entry.Type {
-		case "receive", "fallback":
-		//Nothing to do as they have no impact for the ABI
-		//Only here to show the have been intentionally left out
+		case "receive":
+			contractAbi.Receive = entry.AsFunction()
+			contractAbi.Receive.Type = "receive"
+		case "fallback":
+			contractAbi.Fallback = entry.AsFunction()
+			contractAbi.Fallback.Type = "fallback"
 		case "constructor":
 			contractAbi.Constructor = entry.AsConstructor()
 		case "", "function":
 			contractAbi.Functions = append(contractAbi.Functions, entry.AsFunction())
 		case "event":
 			contractAbi.Events = append(contractAbi.Events, entry.AsEvent())
+		case "error":
+			contractAbi.Errors = append(contractAbi.Errors, entry.AsError())
 		}
 	}
 
@@ -33,15 +63,95 @@ func (abi ABIStructure) ToInternalABI() *ContractABI {
 }
 
 type ABIStructureEntry struct {
-	Type      string                 `json:"type"`
-	Name      string                 `json:"name"`
-	Inputs    []ABIStructureArgument `json:"inputs"`
-	Outputs   []ABIStructureArgument `json:"outputs"`
-	Anonymous bool                   `json:"anonymous"`
+	Type            string                 `json:"type"`
+	Name            string                 `json:"name,omitempty"`
+	Inputs          []ABIStructureArgument `json:"inputs"`
+	Outputs         []ABIStructureArgument `json:"outputs"`
+	Anonymous       bool                   `json:"anonymous,omitempty"`
+	StateMutability string                 `json:"stateMutability,omitempty"`
+
+	// Payable and Constant are only given in ABIs from before Solidity 0.5.0,
+	// and have been replaced by StateMutability
+	Payable  *bool `json:"payable,omitempty"`
+	Constant *bool `json:"constant,omitempty"`
+}
+
+//abiStructureEntryJSON has the fields of an entry without its MarshalJSON method
+type abiStructureEntryJSON ABIStructureEntry
+
+// MarshalJSON marshals the entry in the same form as solc, with empty inputs and outputs as [] rather than null,
+// and outputs only given for functions, and inputs not given for the receive and fallback functions
+func (entry ABIStructureEntry) MarshalJSON() ([]byte, error) {
+	out := struct {
+		abiStructureEntryJSON
+		Inputs  *[]ABIStructureArgument `json:"inputs,omitempty"`
+		Outputs *[]ABIStructureArgument `json:"outputs,omitempty"`
+	}{abiStructureEntryJSON: abiStructureEntryJSON(entry)}
+
+	if entry.Type != "receive" && entry.Type != "fallback" {
+		inputs := nonNilArguments(entry.Inputs)
+		out.Inputs = &inputs
+	}
+	if entry.Type == "" || entry.Type == "function" {
+		outputs := nonNilArguments(entry.Outputs)
+		out.Outputs = &outputs
+	}
+	return json.Marshal(out)
+}
+
+func nonNilArguments(args []ABIStructureArgument) []ABIStructureArgument {
+	if args == nil {
+		return []ABIStructureArgument{}
+	}
+	return args
+}
+
+// Mutability gives the state mutability of the entry, using the legacy payable and constant
+// fields if it isn't given explicitly
+func (entry ABIStructureEntry) Mutability() string {
+	if entry.StateMutability != "" {
+		return entry.StateMutability
+	}
+	switch {
+	case entry.Type == "event" || entry.Type == "error":
+		return ""
+	case entry.Type == "receive", entry.Payable != nil && *entry.Payable:
+		return "payable"
+	case entry.Constant != nil && *entry.Constant:
+		return "view"
+	}
+	return "nonpayable"
+}
+
+// Validate checks that the type of every input and output of the entry is valid, see ParseABIType
+func (entry ABIStructureEntry) Validate() error {
+	for _, list := range []struct {
+		kind string
+		args []ABIStructureArgument
+	}{{"input", entry.Inputs}, {"output", entry.Outputs}} {
+		for i, arg := range list.args {
+			if _, err := arg.AsArgument().ParsedType(); err != nil {
+				return fmt.Errorf("invalid %s: %s %s: %s", entry.description(), list.kind, argumentKey(arg.AsArgument(), i), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+//description names the entry in errors, e.g. "function transfer" or "constructor"
+func (entry ABIStructureEntry) description() string {
+	entryType := entry.Type
+	if entryType == "" {
+		entryType = "function"
+	}
+	if entry.Name == "" {
+		return entryType
+	}
+	return entryType + " " + entry.Name
 }
 
 func (entry ABIStructureEntry) AsConstructor() ContractABIFunction {
-	return ContractABIFunction{"constructor", "", entry.AsFunction().Inputs, nil}
+	return ContractABIFunction{"constructor", "", entry.AsFunction().Inputs, nil, entry.Mutability()}
 }
 
 func (entry ABIStructureEntry) AsFunction() ContractABIFunction {
@@ -53,7 +163,7 @@ func (entry ABIStructureEntry) AsFunction() ContractABIFunction {
 	for _, output := range entry.Outputs {
 		outputs = append(outputs, output.AsArgument())
 	}
-	return ContractABIFunction{"function", entry.Name, inputs, outputs}
+	return ContractABIFunction{"function", entry.Name, inputs, outputs, entry.Mutability()}
 }
 
 func (entry ABIStructureEntry) AsEvent() ContractABIEvent {
@@ -64,11 +174,16 @@ func (entry ABIStructureEntry) AsEvent() ContractABIEvent {
 	return ContractABIEvent{"event", entry.Name, inputs, entry.Anonymous}
 }
 
+func (entry ABIStructureEntry) AsError() ContractABIError {
+	return ContractABIError{"error", entry.Name, entry.AsFunction().Inputs}
+}
+
 type ABIStructureArgument struct {
-	Name       string                 `json:"name"`
-	Type       string                 `json:"type"`
-	Components []ABIStructureArgument `json:"components"`
-	Indexed    bool                   `json:"indexed"`
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"`
+	InternalType string                 `json:"internalType,omitempty"`
+	Components   []ABIStructureArgument `json:"components,omitempty"`
+	Indexed      bool                   `json:"indexed,omitempty"`
 }
 
 func (arg ABIStructureArgument) AsArgument() ContractABIArgument {
@@ -76,9 +191,93 @@ func (arg ABIStructureArgument) AsArgument() ContractABIArgument {
 	for _, component := range arg.Components {
 		components = append(components, component.AsArgument())
 	}
-	return ContractABIArgument{arg.Name, arg.Type, components}
+	return ContractABIArgument{arg.Name, arg.Type, components, arg.InternalType}
 }
 
 func (arg ABIStructureArgument) AsIndexedArgument() ContractABIEventArgument {
 	return ContractABIEventArgument{arg.AsArgument(), arg.Indexed}
 }
+
+// ToABIStructure converts the ABI back into the structure of the JSON ABI
+func (abi *ContractABI) ToABIStructure() ABIStructure {
+	var structure ABIStructure
+	if abi.Constructor.Type == "constructor" {
+		payable, _ := legacyFlags(abi.Constructor.StateMutability)
+		structure = append(structure, ABIStructureEntry{
+			Type:            "constructor",
+			Inputs:          toStructureArguments(abi.Constructor.Inputs),
+			StateMutability: abi.Constructor.StateMutability,
+			Payable:         payable,
+		})
+	}
+	for _, special := range []ContractABIFunction{abi.Receive, abi.Fallback} {
+		if special.Type != "" {
+			entry := ABIStructureEntry{
+				Type:            special.Type,
+				Inputs:          toStructureArguments(special.Inputs),
+				Outputs:         toStructureArguments(special.Outputs),
+				StateMutability: special.StateMutability,
+			}
+			//receive functions were added after the legacy flags were replaced
+			if special.Type == "fallback" {
+				entry.Payable, _ = legacyFlags(special.StateMutability)
+			}
+			structure = append(structure, entry)
+		}
+	}
+	for _, function := range abi.Functions {
+		payable, constant := legacyFlags(function.StateMutability)
+		structure = append(structure, ABIStructureEntry{
+			Type:            "function",
+			Name:            function.Name,
+			Inputs:          toStructureArguments(function.Inputs),
+			Outputs:         toStructureArguments(function.Outputs),
+			StateMutability: function.StateMutability,
+			Payable:         payable,
+			Constant:        constant,
+		})
+	}
+	for _, event := range abi.Events {
+		var inputs []ABIStructureArgument
+		for _, input := range event.Inputs {
+			arg := toStructureArgument(input.ContractABIArgument)
+			arg.Indexed = input.Indexed
+			inputs = append(inputs, arg)
+		}
+		structure = append(structure, ABIStructureEntry{Type: "event", Name: event.Name, Inputs: inputs, Anonymous: event.Anonymous})
+	}
+	for _, abiErr := range abi.Errors {
+		structure = append(structure, ABIStructureEntry{Type: "error", Name: abiErr.Name, Inputs: toStructureArguments(abiErr.Inputs)})
+	}
+	return structure
+}
+
+// ToJSON gives the JSON form of the ABI
+func (abi *ContractABI) ToJSON() (string, error) {
+	return abi.ToABIStructure().ToJSON()
+}
+
+// ToHumanReadable gives the human-readable form of each entry in the ABI
+func (abi *ContractABI) ToHumanReadable() []string {
+	return abi.ToABIStructure().ToHumanReadable()
+}
+
+//legacyFlags gives the payable and constant fields for the state mutability, so that the ABI can still be read by
+//tools from before Solidity 0.5.0
+func legacyFlags(mutability string) (*bool, *bool) {
+	payable := mutability == "payable"
+	constant := mutability == "view" || mutability == "pure"
+	return &payable, &constant
+}
+
+func toStructureArguments(args []ContractABIArgument) []ABIStructureArgument {
+	var converted []ABIStructureArgument
+	for _, arg := range args {
+		converted = append(converted, toStructureArgument(arg))
+	}
+	return converted
+}
+
+func toStructureArgument(arg ContractABIArgument) ABIStructureArgument {
+	return ABIStructureArgument{Name: arg.Name, Type: arg.Type, InternalType: arg.InternalType, Components: toStructureArguments(arg.Components)}
+}
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyABI = `[
+	{"type":"function","name":"deposit","inputs":[],"outputs":[],"payable":true,"constant":false},
+	{"type":"function","name":"totalSupply","inputs":[],"outputs":[{"name":"","type":"uint256"}],"payable":false,"constant":true},
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"payable":false,"constant":false},
+	{"type":"fallback","payable":true}
+]`
+
+const modernABI = `[
+	{"type":"function","name":"get","inputs":[],"outputs":[{"name":"","type":"tuple","internalType":"struct Store.Item","components":[{"name":"id","type":"uint256","internalType":"uint256"}]}],"stateMutability":"view"},
+	{"type":"function","name":"hash","inputs":[{"name":"data","type":"bytes","internalType":"bytes"}],"outputs":[{"name":"","type":"bytes32","internalType":"bytes32"}],"stateMutability":"pure"},
+	{"type":"receive","stateMutability":"payable"},
+	{"type":"fallback","stateMutability":"nonpayable"}
+]`
+
+func TestABIStructure_ToInternalABI_LegacyMutability(t *testing.T) {
+	structure, err := NewABIStructureFromJSON(legacyABI)
+	assert.Nil(t, err)
+
+	abi := structure.ToInternalABI()
+
+	testMatrix := []struct {
+		expectedMutability string
+		expectedPayable    bool
+		expectedConstant   bool
+	}{
+		{"payable", true, false},
+		{"view", false, true},
+		{"nonpayable", false, false},
+	}
+	for idx, test := range testMatrix {
+		assert.Equal(t, test.expectedMutability, abi.Functions[idx].StateMutability, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedPayable, abi.Functions[idx].IsPayable(), "Test index %d failed", idx)
+		assert.Equal(t, test.expectedConstant, abi.Functions[idx].IsConstant(), "Test index %d failed", idx)
+	}
+
+	assert.False(t, abi.HasReceive())
+	assert.True(t, abi.HasFallback())
+	assert.True(t, abi.Fallback.IsPayable())
+}
+
+func TestABIStructure_ToInternalABI_ReceiveAndFallback(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(modernABI)
+
+	abi := structure.ToInternalABI()
+
+	assert.Len(t, abi.Functions, 2)
+	assert.True(t, abi.HasReceive())
+	assert.True(t, abi.Receive.IsPayable())
+	assert.True(t, abi.HasFallback())
+	assert.False(t, abi.Fallback.IsPayable())
+	assert.True(t, abi.Functions[0].IsConstant())
+	assert.Equal(t, "pure", abi.Functions[1].StateMutability)
+
+	assert.Equal(t, []string{
+		"receive() external payable",
+		"fallback() external",
+		"function get() view returns (tuple(uint256 id))",
+		"function hash(bytes data) pure returns (bytes32)",
+	}, abi.ToHumanReadable())
+
+	structure, _ = NewABIStructureFromJSON(`[{"type":"function","name":"f","inputs":[],"outputs":[]}]`)
+	abi = structure.ToInternalABI()
+	assert.False(t, abi.HasReceive())
+	assert.False(t, abi.HasFallback())
+}
+
+func TestContractABIArgument_StructName(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(abiParsingContractABI)
+	abi := structure.ToInternalABI()
+
+	names := make(map[string]string)
+	for _, event := range abi.Events {
+		for _, input := range event.Inputs {
+			if input.Type == "tuple" || input.Type == "tuple[]" {
+				names[event.Name+"."+input.Name] = input.StructName()
+			}
+		}
+	}
+
+	assert.Equal(t, "ABIParsingContract.Custom", names["Mixed.first"])
+	assert.Equal(t, "ABIParsingContract.Custom", names["StructArray.second"])
+	assert.Equal(t, "ABIParsingContract.StaticTuple", names["StaticTupleEventOne.first"])
+	assert.Equal(t, "", ContractABIArgument{Type: "uint256", InternalType: "uint256"}.StructName())
+}
+
+func TestContractABI_ToJSON(t *testing.T) {
+	testMatrix := []struct {
+		rawABI       string
+		expectedJSON string
+	}{
+		{legacyABI, `[
+			{"type":"fallback","stateMutability":"payable","payable":true},
+			{"type":"function","name":"deposit","stateMutability":"payable","payable":true,"constant":false,"inputs":[],"outputs":[]},
+			{"type":"function","name":"totalSupply","stateMutability":"view","payable":false,"constant":true,"inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+			{"type":"function","name":"transfer","stateMutability":"nonpayable","payable":false,"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+		]`},
+		{modernABI, `[
+			{"type":"receive","stateMutability":"payable"},
+			{"type":"fallback","stateMutability":"nonpayable","payable":false},
+			{"type":"function","name":"get","stateMutability":"view","payable":false,"constant":true,"inputs":[],"outputs":[{"name":"","type":"tuple","internalType":"struct Store.Item","components":[{"name":"id","type":"uint256","internalType":"uint256"}]}]},
+			{"type":"function","name":"hash","stateMutability":"pure","payable":false,"constant":true,"inputs":[{"name":"data","type":"bytes","internalType":"bytes"}],"outputs":[{"name":"","type":"bytes32","internalType":"bytes32"}]}
+		]`},
+		{`[
+			{"type":"constructor","inputs":[{"name":"a","type":"uint256"}],"stateMutability":"payable"},
+			{"type":"event","name":"E","inputs":[{"name":"a","type":"uint256","indexed":true},{"name":"b","type":"string","indexed":false}],"anonymous":false},
+			{"type":"error","name":"Err","inputs":[]}
+		]`, `[
+			{"type":"constructor","stateMutability":"payable","payable":true,"inputs":[{"name":"a","type":"uint256"}]},
+			{"type":"event","name":"E","inputs":[{"name":"a","type":"uint256","indexed":true},{"name":"b","type":"string"}]},
+			{"type":"error","name":"Err","inputs":[]}
+		]`},
+	}
+
+	for idx, test := range testMatrix {
+		structure, err := NewABIStructureFromJSON(test.rawABI)
+		assert.Nil(t, err, "Test index %d failed", idx)
+
+		asJSON, err := structure.ToInternalABI().ToJSON()
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.JSONEq(t, test.expectedJSON, asJSON, "Test index %d failed", idx)
+	}
+}
+
+func TestContractABI_ToJSON_RoundTrip(t *testing.T) {
+	for idx, rawABI := range []string{legacyABI, modernABI, abiParsingContractABI} {
+		structure, err := NewABIStructureFromJSON(rawABI)
+		assert.Nil(t, err, "Test index %d failed", idx)
+		abi := structure.ToInternalABI()
+
+		asJSON, err := abi.ToJSON()
+		assert.Nil(t, err, "Test index %d failed", idx)
+		reparsed, err := NewABIStructureFromJSON(asJSON)
+		assert.Nil(t, err, "Test index %d failed", idx)
+
+		reparsedJSON, err := reparsed.ToInternalABI().ToJSON()
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.JSONEq(t, asJSON, reparsedJSON, "Test index %d failed", idx)
+		assert.NotContains(t, asJSON, "null", "Test index %d failed", idx)
+
+		// the human-readable form has no internal types, so only compare what it can express
+		fromHuman, err := NewABIStructureFromHumanReadable(abi.ToHumanReadable())
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, abi.ToHumanReadable(), fromHuman.ToInternalABI().ToHumanReadable(), "Test index %d failed", idx)
+	}
+}
+
+func TestABIStructure_ToJSON_KeepsLegacyFields(t *testing.T) {
+	structure, _ := NewABIStructureFromJSON(legacyABI)
+
+	asJSON, err := structure.ToJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"type":"function","name":"deposit","inputs":[],"outputs":[],"payable":true,"constant":false},
+		{"type":"function","name":"totalSupply","inputs":[],"outputs":[{"name":"","type":"uint256"}],"payable":false,"constant":true},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"payable":false,"constant":false},
+		{"type":"fallback","payable":true}
+	]`, asJSON)
+
+	reparsed, _ := NewABIStructureFromJSON(asJSON)
+	assert.Equal(t, structure, reparsed)
+	if assert.NotNil(t, reparsed[1].Constant) {
+		assert.True(t, *reparsed[1].Constant)
+	}
+	assert.Equal(t, "", reparsed[1].StateMutability)
+}
@@ -2,13 +2,10 @@ package types
 
 import (
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"golang.org/x/crypto/sha3"
 	"math/big"
-	"sort"
 	"strconv"
-	"strings"
 )
 
 type HeadItem struct {
@@ -18,22 +15,14 @@ type HeadItem struct {
 }
 
 func ParseInt(bytes []byte) *big.Int {
-	//2s complement, so negative is Most Significant Bit is set
-	isPositive := bytes[0] < 128
-
-	if isPositive {
-		return ParseUint(bytes)
-	}
+	i := ParseUint(bytes)
 
-	// negative, so invert all the bits, add 1 and flip the sign
-	for i := 0; i < len(bytes); i++ {
-		bytes[i] = ^bytes[i]
+	//2s complement, so negative if the Most Significant Bit is set
+	//subtract 2^(number of bits) to get the negative value
+	if len(bytes) > 0 && bytes[0] >= 128 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(len(bytes)*8)))
 	}
 
-	i := ParseUint(bytes)
-	i.Add(i, new(big.Int).SetUint64(1))
-	i.Neg(i)
-
 	return i
 }
 
@@ -56,8 +45,7 @@ ParseAllData parses a set of elements from the ABI, assuming they fill the provi
 2) Check if the element is statically typed
 2a) if statically typed, the "head" is the element itself, so it is parsed immediately, and the current pointer for where we are within the data
 	array is updated. Note there is no "tail" for a static element.
-2b) if it is dynamically typed, make a note of its starting position (data[pointer:pointer+32] interpreted as a uint) and update the last dynamic
-	element with its ending position (which is the same value))
+2b) if it is dynamically typed, make a note of its starting position (data[pointer:pointer+32] interpreted as a uint)
 3) Once all the elements have been parsed, start processing the dynamic element tails, which may recursively call ParseData
 4) Concatenate all the results into a map against their variable names and return
 
@@ -70,59 +58,206 @@ Head(X1) + Head(X2) + Head(X3) + Head(X4) + Tail(X1) + Tail(X2) + Tail(X3) + Tai
     - Head(Xn) is 32 bytes, representing a uint256 of the starting position of Tail(Xn) within the data array
     - Tail(Xn) is the actual encoding  of the element
 
-Because we only get the starting position of dynamic elements, we have to infer the end position by taking the start
-position of the next dynamic element, leading to 2b) that we update the last dynamic element with the end point (as we
-now know this value)
+We only get the starting position of dynamic elements, but every tail either starts with its own length or is made up
+of elements with known sizes, so each tail is parsed from its starting position up to the end of the data. This means
+the offsets don't need to be in order, and a malformed offset can't cause one element to be cut short.
+
+Every offset, length and element count read from the data is checked against the size of the data before it is used,
+and a *DecodeError is returned if the data is too short or a value is out of bounds. Tails can't overlap in a valid
+encoding, so the bytes and strings decoded can't add up to more than the size of the data either.
 */
 func ParseAllData(inputs []ContractABIArgument, data []byte) (map[string]interface{}, error) {
+	values, err := DecodeAllData(inputs, data)
+	if err != nil {
+		return nil, err
+	}
+	return values.ToMap(), nil
+}
+
+//DecodeAllData decodes a set of elements from the ABI in the same way as ParseAllData, but keeps them in order,
+//along with their names and types
+func DecodeAllData(inputs []ContractABIArgument, data []byte) (DecodedValues, error) {
+	return DecodeAllDataWithOptions(inputs, data, DecodeOptions{})
+}
+
+//DecodeAllDataWithOptions decodes a set of elements in the same way as DecodeAllData, with the given options
+func DecodeAllDataWithOptions(inputs []ContractABIArgument, data []byte, opts DecodeOptions) (DecodedValues, error) {
+	return newABIDecoder(data, opts).decodeArguments(inputs, data, "")
+}
+
+//DecodeAllDataStrict decodes a set of elements in the same way as DecodeAllData, but also checks that the data is
+//exactly the encoding of the values, so that every byte is used, offsets point where the encoder would put them, and
+//there are no stray bits in padding. This tells data for the arguments apart from data that only happens to decode
+func DecodeAllDataStrict(inputs []ContractABIArgument, data []byte) (DecodedValues, error) {
+	values, err := DecodeAllData(inputs, data)
+	if err != nil {
+		return nil, err
+	}
+
+	legacyValues := make([]interface{}, len(values))
+	for i, value := range values {
+		legacyValues[i] = value.legacyValue(true)
+	}
+	encoded, err := EncodeAllData(inputs, legacyValues)
+	if err != nil {
+		return nil, &DecodeError{Msg: "data is not a valid encoding of the arguments: " + err.Error()}
+	}
+
+	for i := 0; i < len(encoded) && i < len(data); i++ {
+		if encoded[i] != data[i] {
+			return nil, &DecodeError{Offset: uint64(i), Msg: "data is not the exact encoding of the arguments"}
+		}
+	}
+	if len(encoded) != len(data) {
+		return nil, &DecodeError{Offset: uint64(len(encoded)), Msg: fmt.Sprintf("expected %d bytes of data, got %d", len(encoded), len(data))}
+	}
+	return values, nil
+}
+
+//ParseDynamicType parses a single dynamically typed element, where the tail of the element starts at the beginning of the data
+func ParseDynamicType(arg ContractABIArgument, data []byte) (interface{}, error) {
+	t, err := arg.ParsedType()
+	if err != nil {
+		return nil, &DecodeError{Path: arg.Name, Msg: err.Error()}
+	}
+	value, err := newABIDecoder(data, DecodeOptions{}).decodeDynamicType(t, arg.Name, data, 0, arg.Name)
+	if err != nil {
+		return nil, err
+	}
+	return value.LegacyValue(), nil
+}
+
+//ParseStaticType will attempt to parse all the possible static types defined by the
+//ABI encoding spec. It returns the result of parsing, as well as the next offset from which to parse
+//the next element - i.e. the starting offset + how many bytes it read to parse this element
+func ParseStaticType(arg ContractABIArgument, data []byte, startingPosition uint64) (interface{}, uint64, error) {
+	t, err := arg.ParsedType()
+	if err != nil {
+		return nil, 0, &DecodeError{Path: arg.Name, Offset: startingPosition, Msg: err.Error()}
+	}
+	value, nextOffset, err := newABIDecoder(data, DecodeOptions{}).decodeStaticType(t, arg.Name, data, startingPosition, 0, arg.Name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value.LegacyValue(), nextOffset, nil
+}
+
+// DefaultMaxDecodedElements is the maximum number of values that will be decoded from a single set of ABI
+// encoded data, unless DecodeOptions give another limit. Dynamic arrays can point to the same data many
+// times over, so a small but hostile input could otherwise expand into an enormous result.
+const DefaultMaxDecodedElements uint64 = 1 << 20
+
+// BytesFormat is the format that the values of bytes and bytes<x> types are decoded into
+type BytesFormat int
+
+const (
+	// BytesAsHex gives the value as a "0x" prefixed hex string, e.g. "0x1234"
+	BytesAsHex BytesFormat = iota
+	// BytesAsSlice gives the value as a []byte
+	BytesAsSlice
+)
+
+// DecodeOptions change how ABI encoded data is decoded. They are given with each call rather than set for the
+// whole process, so that data can be decoded with different options at the same time. The zero value gives the
+// defaults
+type DecodeOptions struct {
+	// MaxElements is the maximum number of values that will be decoded, or DefaultMaxDecodedElements if it is 0
+	MaxElements uint64
+	// BytesFormat is the format that the values of bytes and bytes<x> types are decoded into
+	BytesFormat BytesFormat
+}
+
+// DecodeError describes why ABI encoded data could not be decoded, along with
+// the argument that was being decoded and where in the data it failed
+type DecodeError struct {
+	// Path is the argument being decoded, e.g. "Mixed.fourth.first" or "StructArray.second[1].first"
+	Path string
+	// Offset is the position in the data, in bytes, where decoding failed
+	Offset uint64
+	Msg    string
+}
+
+func (err *DecodeError) Error() string {
+	if err.Path == "" {
+		return fmt.Sprintf("abi: cannot decode data at offset %d: %s", err.Offset, err.Msg)
+	}
+	return fmt.Sprintf("abi: cannot decode %s at offset %d: %s", err.Path, err.Offset, err.Msg)
+}
+
+// abiDecoder keeps track of the state of decoding a single set of ABI encoded data
+//
+// Each function is given the data starting at the element being decoded, along
+// with the base offset of that data within the full input so that errors point
+// to the right location
+type abiDecoder struct {
+	maxElements       uint64
+	remainingElements uint64
+	//the bytes and strings decoded can't add up to more than the data, as the tails of an encoding don't overlap,
+	//so that many heads pointing at the same long tail can't make it be copied over and over again
+	remainingBytes uint64
+	bytesFormat    BytesFormat
+}
+
+func newABIDecoder(data []byte, opts DecodeOptions) *abiDecoder {
+	maxElements := opts.MaxElements
+	if maxElements == 0 {
+		maxElements = DefaultMaxDecodedElements
+	}
+	return &abiDecoder{maxElements: maxElements, remainingElements: maxElements, remainingBytes: uint64(len(data)), bytesFormat: opts.BytesFormat}
+}
+
+//decodeArguments decodes a list of arguments, such as the inputs of a function, which are encoded as a tuple
+func (d *abiDecoder) decodeArguments(inputs []ContractABIArgument, data []byte, path string) (DecodedValues, error) {
+	t, err := tupleType(inputs)
+	if err != nil {
+		return nil, d.error(path, 0, err.Error())
+	}
+	return d.decodeAll(t.Components, t.ComponentNames, data, 0, path, false)
+}
+
+//decodeAll decodes a list of elements that fill the data, which are either the components of a tuple or the elements
+//of an array. Array elements have no names, and are given as a nil list of names
+func (d *abiDecoder) decodeAll(types []*ABIType, names []string, data []byte, base uint64, path string, isArray bool) (DecodedValues, error) {
 	currentOffset := uint64(0)
-	tailOffsets := make([]*HeadItem, 0)
-	allResults := make(map[string]interface{})
+	tailOffsets := make([]uint64, 0)
+	tailIndexes := make([]int, 0)
+	allResults := make(DecodedValues, len(types))
+
+	name := func(i int) string {
+		if isArray {
+			return ""
+		}
+		return names[i]
+	}
 
 	//handle all the heads, then handle all the tails
-	for _, input := range inputs {
-		if input.IsDynamic() {
-			elementStartOffsetBytes := data[currentOffset : currentOffset+32]
-			elementStartOffset := ParseUint(elementStartOffsetBytes).Uint64()
-
-			if len(tailOffsets) == 0 {
-				tailOffsets = append(tailOffsets, &HeadItem{
-					StartOffset: elementStartOffset,
-					Arg:         input,
-				})
-			} else {
-				maxIndex := len(tailOffsets) - 1
-				tailOffsets[maxIndex].EndOffset = elementStartOffset
-				tailOffsets = append(tailOffsets, &HeadItem{
-					StartOffset: elementStartOffset,
-					Arg:         input,
-				})
+	for i, t := range types {
+		if t.IsDynamic() {
+			elementStartOffset, err := d.readOffset(data, currentOffset, base, elementPath(path, name(i), i, isArray))
+			if err != nil {
+				return nil, err
 			}
+			tailOffsets = append(tailOffsets, elementStartOffset)
+			tailIndexes = append(tailIndexes, i)
 			//parse it later
 			currentOffset += 32
 			continue
 		}
 
-		result, newOffset, err := ParseStaticType(input, data, currentOffset)
+		result, newOffset, err := d.decodeStaticType(t, name(i), data, currentOffset, base, elementPath(path, name(i), i, isArray))
 		if err != nil {
 			return nil, err
 		}
 
-		allResults[input.Name] = result
+		allResults[i] = result
 		currentOffset = newOffset
 	}
 
-	//set the end of the last dynamic element, if there are any
-	if len(tailOffsets) != 0 {
-		maxIndex := len(tailOffsets) - 1
-		tailOffsets[maxIndex].EndOffset = uint64(len(data))
-	}
-
-	for _, headItem := range tailOffsets {
-		dynamicTypeData := data[headItem.StartOffset:headItem.EndOffset]
+	for j, startOffset := range tailOffsets {
+		i := tailIndexes[j]
 
 		var err error
-		allResults[headItem.Arg.Name], err = ParseDynamicType(headItem.Arg, dynamicTypeData)
+		allResults[i], err = d.decodeDynamicType(types[i], name(i), data[startOffset:], base+startOffset, elementPath(path, name(i), i, isArray))
 		if err != nil {
 			return nil, err
 		}
@@ -131,193 +266,299 @@ func ParseAllData(inputs []ContractABIArgument, data []byte) (map[string]interfa
 	return allResults, nil
 }
 
-//TODO: add bounds check with errors on data array
+func (d *abiDecoder) decodeDynamicType(t *ABIType, name string, data []byte, base uint64, path string) (*DecodedValue, error) {
+	if err := d.countElement(path, base); err != nil {
+		return nil, err
+	}
 
-func ParseDynamicType(arg ContractABIArgument, data []byte) (interface{}, error) {
+	switch t.Kind {
 	//A dynamically sized array of either a static or dynamic type
 	//Extract the array size from the first 32 bytes, and then treat it
 	//as a fixed size array of the extracted size
-	//This means we can cycle it through the parser again, stripping it of the first
-	//32 bytes, where it will get picked up by the fixed-sized array parser
-	if strings.HasSuffix(arg.Type, "[]") {
-		//this is a dynamic array
-
+	case SliceKind:
 		//read the number of elements from the first 32 bytes
-		numberOfElements := ParseUint(data[:32]).Uint64()
-		//cut of the ending [] and add [numberOfElements]
-		typeName := fmt.Sprintf("%s[%d]", arg.Type[:len(arg.Type)-2], numberOfElements)
-
-		///construct a new type that turns T[] -> T[numberOfElements]
-		fixedSizeType := ContractABIArgument{
-			Name:       arg.Name,
-			Type:       typeName,
-			Components: arg.Components,
+		numberOfElements, err := d.readLength(data, base, path)
+		if err != nil {
+			return nil, err
 		}
 
-		arrayData := data[32:] // remove the size from the start of the data, as we've parsed that already
-		return ParseDynamicType(fixedSizeType, arrayData)
-	}
+		// remove the size from the start of the data, as we've parsed that already
+		elements, err := d.decodeArrayElements(t, numberOfElements, data[32:], base+32, path)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedValue{Name: name, Type: t.TypeName(), Children: elements}, nil
 
 	//A fixed size array of a dynamic type
 	//This is the same as listing all the elements individually and parsing, so do that
-	//NOTE: this may actually be a static type is the "real" type was a dynamic array
-	//		this doesn't really matter, since it will get interpreted correctly when
-	//		doing a top-level parse again
-	if strings.Contains(arg.Type, "[") && strings.Contains(arg.Type, "]") {
-		start := strings.LastIndex(arg.Type, "[")
-		end := strings.LastIndex(arg.Type, "]")
-
-		numberOfElements, err := strconv.ParseUint(arg.Type[start+1:end], 10, 0)
+	case ArrayKind:
+		elements, err := d.decodeArrayElements(t, t.Length, data, base, path)
 		if err != nil {
-			return nil, errors.New("error parsing fixed sized, dynamically typed array size: " + err.Error())
-		}
-
-		//ABI spec says we can treat a fixed size array as a tuple with same number of elements
-		var arrayAsTuple []ContractABIArgument
-		for i := uint64(0); i < numberOfElements; i++ {
-			repeatedElement := ContractABIArgument{
-				Name:       strconv.FormatUint(i, 10),
-				Type:       arg.Type[:start],
-				Components: arg.Components,
-			}
-			arrayAsTuple = append(arrayAsTuple, repeatedElement)
+			return nil, err
 		}
+		return &DecodedValue{Name: name, Type: t.TypeName(), Children: elements}, nil
 
-		result, err := ParseAllData(arrayAsTuple, data)
+	//a bytes array is prefixed with its length,
+	//the data is right-padded to the next multiple of 32, but we can just ignore this extra data
+	case BytesKind:
+		numberOfBytes, err := d.readLength(data, base, path)
 		if err != nil {
 			return nil, err
 		}
-
-		// make sure the results get sorted in order
-		keys := make([]uint64, 0)
-		for key := range result {
-			asUint, _ := strconv.ParseUint(key, 10, 0)
-			keys = append(keys, asUint)
+		if numberOfBytes > uint64(len(data))-32 {
+			return nil, d.error(path, base+32, fmt.Sprintf("bytes of length %d exceed the remaining %d bytes of data", numberOfBytes, len(data)-32))
 		}
-		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
-		var resultAsList []interface{}
-		for _, k := range keys {
-			resultAsList = append(resultAsList, result[strconv.FormatUint(k, 10)])
-		}
-		return resultAsList, nil
-	}
-
-	//a bytes array is prefixed with its length,
-	//the data is right-padded to the next multiple of 32, but we can just ignore this extra data
-	//each byte is treated individually in the result
-	if arg.Type == "bytes" {
-		numberOfBytes := ParseUint(data[:32]).Uint64()
-		allBytes := data[33 : numberOfBytes+33]
-		var converted []string
-		for _, b := range allBytes {
-			converted = append(converted, "0x"+hex.EncodeToString([]byte{b}))
+		if err := d.countBytes(numberOfBytes, path, base); err != nil {
+			return nil, err
 		}
-		return converted, nil
-	}
+		return &DecodedValue{Name: name, Type: t.TypeName(), Value: d.bytesValue(data[32 : numberOfBytes+32])}, nil
 
 	//string parsing is the same as bytes, but just interpreting
-	//the result as a string instead of individual bytes
-	if arg.Type == "string" {
-		numberOfBytes := ParseUint(data[:32]).Uint64()
-		return string(data[32 : numberOfBytes+32]), nil
-	}
+	//the result as a string instead
+	case StringKind:
+		numberOfBytes, err := d.readLength(data, base, path)
+		if err != nil {
+			return nil, err
+		}
+		if numberOfBytes > uint64(len(data))-32 {
+			return nil, d.error(path, base+32, fmt.Sprintf("string of length %d exceeds the remaining %d bytes of data", numberOfBytes, len(data)-32))
+		}
+		if err := d.countBytes(numberOfBytes, path, base); err != nil {
+			return nil, err
+		}
+		return &DecodedValue{Name: name, Type: t.TypeName(), Value: string(data[32 : numberOfBytes+32])}, nil
 
 	//a dynamic tuple may contain a mix of dynamic and static elements
 	//but contains at least one dynamic element
 	//parse it as though its components were indivudally listed, since the
 	//data array is only made up of this tuple
-	if arg.Type == "tuple" {
-		return ParseAllData(arg.Components, data)
+	case TupleKind:
+		components, err := d.decodeAll(t.Components, t.ComponentNames, data, base, path, false)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedValue{Name: name, Type: t.TypeName(), Children: components}, nil
 	}
 
-	return nil, errors.New("unknown type: " + arg.Type)
+	return nil, d.error(path, base, t.TypeName()+" is not a dynamic type")
 }
 
-//TODO: add bounds check with errors on data array
+//decodeArrayElements decodes the elements of an array of a dynamic type, or of a dynamically sized array.
+//The ABI spec says we can treat the elements as a tuple with the same number of elements
+func (d *abiDecoder) decodeArrayElements(t *ABIType, numberOfElements uint64, data []byte, base uint64, path string) (DecodedValues, error) {
+	//every element has a 32 byte head, so make sure there is enough data before creating them
+	if numberOfElements > uint64(len(data))/32 {
+		return nil, d.error(path, base, fmt.Sprintf("array of %d elements needs at least %d bytes, have %d", numberOfElements, numberOfElements*32, len(data)))
+	}
+	return d.decodeAll(t.Elem.repeated(numberOfElements), nil, data, base, path, true)
+}
 
-//ParseStaticType will attempt to parse all the possible static types defined by the
-//ABI encoding spec. It returns the result of parsing, as well as the next offset from which to parse
-//the next element - i.e. the starting offset + how many bytes it read to parse this element
-func ParseStaticType(arg ContractABIArgument, data []byte, startingPosition uint64) (interface{}, uint64, error) {
+func (d *abiDecoder) decodeStaticType(t *ABIType, name string, data []byte, startingPosition uint64, base uint64, path string) (*DecodedValue, uint64, error) {
+	if err := d.countElement(path, base+startingPosition); err != nil {
+		return nil, 0, err
+	}
 
+	switch t.Kind {
 	//a fixed size array of a static type
 	//treat it as though it is X number of individually defined elements
-	if strings.Contains(arg.Type, "[") && strings.Contains(arg.Type, "]") {
-		start := strings.LastIndex(arg.Type, "[")
-		end := strings.LastIndex(arg.Type, "]")
+	case ArrayKind:
+		//every element takes at least 32 bytes, so make sure there is enough data before reading them
+		if startingPosition > uint64(len(data)) || t.Length > (uint64(len(data))-startingPosition)/32 {
+			return nil, 0, d.error(path, base+startingPosition, fmt.Sprintf("array of %d elements needs at least %d bytes, have %d", t.Length, t.Length*32, uint64(len(data))-startingPosition))
+		}
 
-		numberOfElements, err := strconv.ParseUint(arg.Type[start+1:end], 10, 0)
-		if err != nil {
-			return nil, 0, errors.New("error parsing static array size: " + err.Error())
+		results := make(DecodedValues, 0, t.Length)
+		nextOffset := startingPosition
+		for i := uint64(0); i < t.Length; i++ {
+			nextResult, updatedOffset, err := d.decodeStaticType(t.Elem, "", data, nextOffset, base, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, 0, err
+			}
+			results = append(results, nextResult)
+			nextOffset = updatedOffset
 		}
+		return &DecodedValue{Name: name, Type: t.TypeName(), Children: results}, nextOffset, nil
 
-		results := make([]interface{}, 0)
+	// this is a static tuple, we can treat this as though the elements were
+	// individually named (instead of being grouped in the tuple), parsing one at a time inline
+	case TupleKind:
+		results := make(DecodedValues, 0, len(t.Components))
 		nextOffset := startingPosition
-		for i := uint64(0); i < numberOfElements; i++ {
-			nextResult, updatedOffset, err := ParseStaticType(ContractABIArgument{Type: arg.Type[:start], Components: arg.Components}, data, nextOffset)
+		for i, comp := range t.Components {
+			compName := t.ComponentNames[i]
+			nextResult, updatedOffset, err := d.decodeStaticType(comp, compName, data, nextOffset, base, elementPath(path, compName, i, false))
 			if err != nil {
 				return nil, 0, err
 			}
 			results = append(results, nextResult)
 			nextOffset = updatedOffset
 		}
-		return results, nextOffset, nil
+		return &DecodedValue{Name: name, Type: t.TypeName(), Children: results}, nextOffset, nil
 	}
 
-	//a set of bytes, from bytes1 upto bytes32
-	//the number of bytes is extracted from the type as to truncate the
-	//left-padded zeroes, returning the hex encoded value of the bytes
-	if strings.HasPrefix(arg.Type, "bytes") {
-		numberOfBytes, _ := strconv.ParseUint(arg.Type[5:], 10, 0)
-		nextChunk := data[startingPosition : startingPosition+32]
-		b := nextChunk[:numberOfBytes]
-		return "0x" + hex.EncodeToString(b), startingPosition + 32, nil
+	//all other static types take up exactly 32 bytes
+	nextChunk, err := d.readWord(data, startingPosition, base, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := d.decodeWord(t, nextChunk, base+startingPosition, path)
+	if err != nil {
+		return nil, 0, err
 	}
+	return &DecodedValue{Name: name, Type: t.TypeName(), Value: value}, startingPosition + 32, nil
+}
+
+//decodeWord decodes one of the elementary types that are encoded in a single 32 byte word
+func (d *abiDecoder) decodeWord(t *ABIType, word []byte, offset uint64, path string) (interface{}, error) {
+	switch t.Kind {
+	//a set of bytes, from bytes1 upto bytes32
+	//the number of bytes is given by the type, as to truncate the
+	//right-padded zeroes, returning the value in the same format as bytes
+	case FixedBytesKind:
+		return d.bytesValue(word[:t.Size]), nil
 
 	//a bool value, left-padded to 32 bytes
-	if arg.Type == "bool" {
-		nextChunk := data[startingPosition : startingPosition+32]
-		b := nextChunk[31]
-		val := b != 0
-		return val, startingPosition + 32, nil
-	}
+	case BoolKind:
+		return word[31] != 0, nil
 
 	// a fixed 32 byte int. Handled int8 upto int256
-	if strings.HasPrefix(arg.Type, "int") {
-		nextChunk := data[startingPosition : startingPosition+32]
-		val := ParseInt(nextChunk)
-		return val, startingPosition + 32, nil
-	}
+	case IntKind:
+		return ParseInt(word), nil
 
 	// a fixed 32 byte uint. Handled uint8 upto uint256
-	if strings.HasPrefix(arg.Type, "uint") {
-		nextChunk := data[startingPosition : startingPosition+32]
-		val := ParseUint(nextChunk)
-		return val, startingPosition + 32, nil
-	}
+	case UintKind:
+		return ParseUint(word), nil
+
+	// a fixed point number, stored as an int or uint of the value * 10^N
+	case FixedPointKind:
+		if t.Signed {
+			return FormatFixed(ParseInt(word), t.Decimals), nil
+		}
+		return FormatFixed(ParseUint(word), t.Decimals), nil
+
+	// a function, which is the 20 byte address of the contract followed by the 4 byte selector,
+	// right-padded to 32 bytes the same as a bytes24
+	case FunctionKind:
+		return d.bytesValue(word[:24]), nil
 
 	// a fixed 20 byte address, with leading 0s to pad it to 32 bytes
-	if strings.HasPrefix(arg.Type, "address") {
-		nextChunk := data[startingPosition : startingPosition+32]
-		addressBytes := nextChunk[12:32]
-		return "0x" + hex.EncodeToString(addressBytes), startingPosition + 32, nil
+	case AddressKind:
+		return "0x" + hex.EncodeToString(word[12:32]), nil
 	}
 
-	// this is a static tuple, we can treat this as though the elements were
-	// individually named (instead of being grouped in the tuple), parsing one at a time inline
-	if strings.HasPrefix(arg.Type, "tuple") {
-		results := make([]interface{}, 0)
-		nextOffset := startingPosition
-		for _, comp := range arg.Components {
-			nextResult, updatedOffset, err := ParseStaticType(comp, data, nextOffset)
-			if err != nil {
-				return nil, 0, err
-			}
-			results = append(results, nextResult)
-			nextOffset = updatedOffset
-		}
-		return results, nextOffset, nil
+	return nil, d.error(path, offset, t.TypeName()+" is not a static type")
+}
+
+//bytesValue gives the value of a bytes or bytes<x> type in the configured format
+func (d *abiDecoder) bytesValue(b []byte) interface{} {
+	if d.bytesFormat == BytesAsSlice {
+		return append([]byte{}, b...)
 	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+//readWord reads the 32 byte word at the given position, checking the data is long enough
+func (d *abiDecoder) readWord(data []byte, position uint64, base uint64, path string) ([]byte, error) {
+	if position > uint64(len(data)) || uint64(len(data))-position < 32 {
+		return nil, d.error(path, base+position, fmt.Sprintf("need 32 bytes, have %d", uint64(len(data))-minUint64(position, uint64(len(data)))))
+	}
+	return data[position : position+32], nil
+}
 
-	return nil, 0, errors.New("unknown type: " + arg.Type)
+//readOffset reads the starting position of a dynamic element's tail, which must point inside the data
+func (d *abiDecoder) readOffset(data []byte, position uint64, base uint64, path string) (uint64, error) {
+	word, err := d.readWord(data, position, base, path)
+	if err != nil {
+		return 0, err
+	}
+	offset := ParseUint(word)
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)) {
+		return 0, d.error(path, base+position, fmt.Sprintf("offset %s is out of bounds of the %d bytes of data", offset.String(), len(data)))
+	}
+	return offset.Uint64(), nil
+}
+
+//readLength reads the length prefix of a dynamic element, which can't be larger than the data itself
+func (d *abiDecoder) readLength(data []byte, base uint64, path string) (uint64, error) {
+	word, err := d.readWord(data, 0, base, path)
+	if err != nil {
+		return 0, err
+	}
+	length := ParseUint(word)
+	if !length.IsUint64() || length.Uint64() > uint64(len(data)) {
+		return 0, d.error(path, base, fmt.Sprintf("length %s exceeds the %d bytes of data", length.String(), len(data)))
+	}
+	return length.Uint64(), nil
+}
+
+//countElement makes sure the limit on the number of decoded values hasn't been reached
+func (d *abiDecoder) countElement(path string, offset uint64) error {
+	if d.remainingElements == 0 {
+		return d.error(path, offset, fmt.Sprintf("more than %d elements in data", d.maxElements))
+	}
+	d.remainingElements--
+	return nil
+}
+
+//countBytes makes sure the bytes and strings decoded so far, including this one of the given length, aren't longer
+//than the data, which they can only be if their tails overlap
+func (d *abiDecoder) countBytes(length uint64, path string, offset uint64) error {
+	if length > d.remainingBytes {
+		return d.error(path, offset, "bytes and strings in data overlap, as they are longer than the data")
+	}
+	d.remainingBytes -= length
+	return nil
+}
+
+func (d *abiDecoder) error(path string, offset uint64, msg string) error {
+	return &DecodeError{Path: path, Offset: offset, Msg: msg}
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+//decodeTopic decodes an indexed event argument from its topic. Value types are stored in
+//the topic as they would be in the data, but anything else is only stored as its hash
+func (d *abiDecoder) decodeTopic(arg ContractABIArgument, topic Hash, path string) (*DecodedValue, error) {
+	t, err := arg.ParsedType()
+	if err != nil {
+		return nil, d.error(path, 0, err.Error())
+	}
+	topicBytes, err := fromHex(string(topic))
+	if err != nil || len(topicBytes) != 32 {
+		return nil, d.error(path, 0, "invalid topic "+topic.String())
+	}
+	if t.IsDynamic() || t.Kind == TupleKind || t.isArray() {
+		return &DecodedValue{Name: arg.Name, Type: t.TypeName(), Value: IndexedHash{Type: t.String(), Hash: topic}}, nil
+	}
+	result, _, err := d.decodeStaticType(t, arg.Name, topicBytes, 0, 0, path)
+	return result, err
+}
+
+//argumentKey gives the key a decoded argument is stored under in the results,
+//which is its name, or its position if the argument is unnamed
+func argumentKey(arg ContractABIArgument, i int) string {
+	if arg.Name == "" {
+		return strconv.Itoa(i)
+	}
+	return arg.Name
+}
+
+//elementPath gives the path of the i-th element being parsed, either as a named
+//field of a tuple, or as an index into an array
+func elementPath(path string, name string, i int, isArray bool) string {
+	if isArray {
+		return fmt.Sprintf("%s[%d]", path, i)
+	}
+	if name == "" {
+		name = strconv.Itoa(i)
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
 }
@@ -0,0 +1,55 @@
+//go:build go1.18
+// +build go1.18
+
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func FuzzContractABIEvent_Parse(f *testing.F) {
+	abi, events := fixtureEvents(f)
+	for _, c := range events {
+		f.Add(c.Data.AsBytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, ev := range abi.Events {
+			_, err := ev.Parse([]Hash{NewHash(ev.Signature())}, data)
+			if err == nil {
+				continue
+			}
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("event %s: expected a DecodeError, got %v", ev.Name, err)
+			}
+		}
+	})
+}
+
+func FuzzParseDynamicType(f *testing.F) {
+	types := []ContractABIArgument{
+		{Name: "bytes", Type: "bytes"},
+		{Name: "string", Type: "string"},
+		{Name: "array", Type: "uint256[]"},
+		{Name: "nested", Type: "uint256[][2][]"},
+		{Name: "strings", Type: "string[]"},
+		{Name: "tuple", Type: "tuple[]", Components: []ContractABIArgument{{Name: "first", Type: "string"}, {Name: "second", Type: "bytes32[2]"}}},
+	}
+	f.Add(hexToBytes(word("2") + word("1") + word("2")))
+	f.Add(hexToBytes(word("1") + word("20") + word("1") + word("61")))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, arg := range types {
+			_, err := ParseDynamicType(arg, data)
+			if err == nil {
+				continue
+			}
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("type %s: expected a DecodeError, got %v", arg.Type, err)
+			}
+		}
+	})
+}
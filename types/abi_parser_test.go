@@ -0,0 +1,329 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureEvent(t *testing.T, name string) (ContractABIEvent, []Hash, []byte) {
+	abi, events := fixtureEvents(t)
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() == c.Topics[0].String() && ev.Name == name {
+				return ev, c.Topics, c.Data.AsBytes()
+			}
+		}
+	}
+	t.Fatalf("%s event not found", name)
+	return ContractABIEvent{}, nil, nil
+}
+
+func word(hexValue string) string {
+	return strings.Repeat("0", 64-len(hexValue)) + hexValue
+}
+
+func assertDecodeError(t *testing.T, err error, path string, offset uint64, msg string) {
+	var decodeErr *DecodeError
+	if assert.True(t, errors.As(err, &decodeErr), "expected a DecodeError, got %v", err) {
+		assert.Equal(t, path, decodeErr.Path)
+		assert.Equal(t, offset, decodeErr.Offset)
+		assert.Contains(t, decodeErr.Msg, msg)
+	}
+}
+
+func TestParseAllData_InvalidNestedOffset(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "Mixed")
+
+	//the fourth element is a tuple starting at 0x140, and the first
+	//word of it is the offset to its string
+	corrupted := append([]byte{}, data...)
+	copy(corrupted[0x140:0x160], hexToBytes(word("ffff")))
+
+	_, err := ev.Parse(topics, corrupted)
+
+	assertDecodeError(t, err, "Mixed.fourth.first", 0x140, "offset 65535 is out of bounds")
+	assert.EqualError(t, err, "abi: cannot decode Mixed.fourth.first at offset 320: offset 65535 is out of bounds of the 224 bytes of data")
+}
+
+func TestParseAllData_InvalidArrayElement(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "StructArray")
+
+	//the second element is a dynamic array of 2 tuples starting at 0x400, with the offsets to the
+	//tuples after the length. The first tuple starts at 0x460, with the offset to its string first
+	corrupted := append([]byte{}, data...)
+	copy(corrupted[0x460:0x480], hexToBytes(word("ffffffffffffffffffffffff")))
+
+	_, err := ev.Parse(topics, corrupted)
+
+	assertDecodeError(t, err, "StructArray.second[0].first", 0x460, "out of bounds")
+}
+
+func TestParseAllData_Truncated(t *testing.T) {
+	abi, events := fixtureEvents(t)
+
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() != c.Topics[0].String() {
+				continue
+			}
+			data := c.Data.AsBytes()
+			for length := 0; length < len(data); length++ {
+				_, err := ev.Parse(c.Topics, data[:length])
+				if err != nil {
+					var decodeErr *DecodeError
+					assert.True(t, errors.As(err, &decodeErr), "event %s truncated to %d bytes: %v", ev.Name, length, err)
+				}
+			}
+		}
+	}
+}
+
+func TestParseAllData_StaticTruncated(t *testing.T) {
+	args := []ContractABIArgument{{Name: "first", Type: "uint256"}, {Name: "second", Type: "bool"}}
+
+	_, err := ParseAllData(args, hexToBytes(word("1")+"00"))
+
+	assertDecodeError(t, err, "second", 32, "need 32 bytes, have 1")
+}
+
+func TestParseAllData_OversizedString(t *testing.T) {
+	args := []ContractABIArgument{{Name: "first", Type: "string"}}
+	data := hexToBytes(word("20") + "8" + strings.Repeat("0", 63) + word("61"))
+
+	_, err := ParseAllData(args, data)
+
+	assertDecodeError(t, err, "first", 32, "exceeds the 64 bytes of data")
+}
+
+func TestParseAllData_OversizedArray(t *testing.T) {
+	args := []ContractABIArgument{{Name: "first", Type: "uint256[]"}}
+	data := hexToBytes(word("20") + word("40") + word("1"))
+
+	_, err := ParseAllData(args, data)
+
+	assertDecodeError(t, err, "first", 64, "array of 64 elements needs at least 2048 bytes, have 32")
+}
+
+func TestParseAllData_OversizedFixedArrayType(t *testing.T) {
+	args := []ContractABIArgument{{Name: "first", Type: "uint256[1000000000]"}}
+
+	_, err := ParseAllData(args, hexToBytes(word("1")))
+
+	assertDecodeError(t, err, "first", 0, "array of 1000000000 elements")
+}
+
+func TestParseAllData_ElementLimit(t *testing.T) {
+	//an array of 20 arrays, which all point to the same array of 10 elements
+	var encoded strings.Builder
+	encoded.WriteString(word("20") + word("14"))
+	for i := 0; i < 20; i++ {
+		encoded.WriteString(word("280"))
+	}
+	encoded.WriteString(word("a"))
+	for i := 0; i < 10; i++ {
+		encoded.WriteString(word("1"))
+	}
+	args := []ContractABIArgument{{Name: "first", Type: "uint256[][]"}}
+
+	_, err := DecodeAllDataWithOptions(args, hexToBytes(encoded.String()), DecodeOptions{MaxElements: 100})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "more than 100 elements in data")
+
+	//the limit only applies to the call it is given to
+	_, err = ParseAllData(args, hexToBytes(encoded.String()))
+	assert.Nil(t, err)
+}
+
+func TestParseAllData_OverlappingStrings(t *testing.T) {
+	//an array of 100 strings, which all point to the same string of 4096 bytes
+	var encoded strings.Builder
+	encoded.WriteString(word("20") + word("64"))
+	for i := 0; i < 100; i++ {
+		encoded.WriteString(word("c80"))
+	}
+	encoded.WriteString(word("1000") + strings.Repeat("61", 4096))
+	args := []ContractABIArgument{{Name: "first", Type: "string[]"}}
+
+	_, err := ParseAllData(args, hexToBytes(encoded.String()))
+
+	assertDecodeError(t, err, "first[1]", 0xcc0, "bytes and strings in data overlap")
+
+	//the same with bytes[], which is given as hex
+	args = []ContractABIArgument{{Name: "first", Type: "bytes[]"}}
+
+	_, err = ParseAllData(args, hexToBytes(encoded.String()))
+
+	assertDecodeError(t, err, "first[1]", 0xcc0, "bytes and strings in data overlap")
+}
+
+func TestParseAllData_RandomCorruption(t *testing.T) {
+	//flips random bytes in each of the fixture events, making sure the
+	//parser never panics, and only ever returns a DecodeError
+	abi, events := fixtureEvents(t)
+	random := rand.New(rand.NewSource(1))
+
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() != c.Topics[0].String() {
+				continue
+			}
+			for i := 0; i < 200; i++ {
+				data := append([]byte{}, c.Data.AsBytes()...)
+				for j := 0; j < 1+random.Intn(4); j++ {
+					data[random.Intn(len(data))] = byte(random.Intn(256))
+				}
+				_, err := ev.Parse(c.Topics, data)
+				if err != nil {
+					var decodeErr *DecodeError
+					assert.True(t, errors.As(err, &decodeErr), "event %s: %v", ev.Name, err)
+				}
+			}
+		}
+	}
+}
+
+func hexToBytes(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
+func TestContractABIEvent_Parse_IndexedTopics(t *testing.T) {
+	transfer := ContractABIEvent{
+		Type: "event",
+		Name: "Transfer",
+		Inputs: []ContractABIEventArgument{
+			{ContractABIArgument{Name: "from", Type: "address"}, true},
+			{ContractABIArgument{Name: "to", Type: "address"}, true},
+			{ContractABIArgument{Name: "value", Type: "uint256"}, false},
+		},
+	}
+	assert.Equal(t, "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", transfer.Signature())
+
+	topics := []Hash{
+		NewHash(transfer.Signature()),
+		NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+		NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab"),
+	}
+	result, err := transfer.Parse(topics, hexToBytes(word("3e8")))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", result["from"])
+	assert.Equal(t, "0x9d13c6d3afe1721beef56b55d303b09e021e27ab", result["to"])
+	assert.EqualValues(t, big.NewInt(1000), result["value"])
+
+	var unpacked struct {
+		From  Address
+		To    Address
+		Value *big.Int
+	}
+	err = transfer.Unpack(topics, hexToBytes(word("3e8")), &unpacked)
+	assert.Nil(t, err)
+	assert.Equal(t, NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"), unpacked.From)
+	assert.Equal(t, NewAddress("0x9d13c6d3afe1721beef56b55d303b09e021e27ab"), unpacked.To)
+
+	_, err = transfer.Parse(topics[:2], hexToBytes(word("3e8")))
+	assertDecodeError(t, err, "Transfer.to", 0, "missing topic 2, the log only has 2 topics")
+}
+
+func TestContractABIEvent_Parse_IndexedHashed(t *testing.T) {
+	event := ContractABIEvent{
+		Type: "event",
+		Name: "Registered",
+		Inputs: []ContractABIEventArgument{
+			{ContractABIArgument{Name: "name", Type: "string"}, true},
+			{ContractABIArgument{Type: "uint256[2]"}, true},
+			{ContractABIArgument{Type: "bool"}, false},
+		},
+	}
+	nameHash := NewHash(hex.EncodeToString(hash("alice")))
+	arrayHash := NewHash(hex.EncodeToString(hash(string(hexToBytes(word("1") + word("2"))))))
+
+	decoded, err := event.Decode([]Hash{NewHash(event.Signature()), nameHash, arrayHash}, hexToBytes(word("1")))
+
+	assert.Nil(t, err)
+	result := decoded.ToIndexedMap()
+	assert.Equal(t, map[string]interface{}{
+		"name": IndexedHash{Type: "string", Hash: nameHash},
+		"1":    IndexedHash{Type: "uint256[2]", Hash: arrayHash},
+		"2":    true,
+	}, result)
+
+	encoded, _ := json.Marshal(result["name"])
+	assert.JSONEq(t, `{"type":"string","hash":"`+nameHash.String()+`"}`, string(encoded))
+
+	var unpacked struct {
+		Name  IndexedHash
+		Array [32]byte
+		Flag  bool
+	}
+	err = event.Unpack([]Hash{NewHash(event.Signature()), nameHash, arrayHash}, hexToBytes(word("1")), &unpacked)
+	assert.Nil(t, err)
+	assert.Equal(t, nameHash, unpacked.Name.Hash)
+	assert.Equal(t, hash(string(hexToBytes(word("1")+word("2")))), unpacked.Array[:])
+	assert.True(t, unpacked.Flag)
+}
+
+func TestContractABIEvent_Parse_MissingSignatureTopic(t *testing.T) {
+	ev, _, data := fixtureEvent(t, "Mixed")
+
+	_, err := ev.Parse(nil, data)
+
+	assertDecodeError(t, err, "Mixed", 0, "missing the event signature topic")
+}
+
+func TestContractABIEvent_Matches(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "UintFixed")
+
+	assert.True(t, ev.Matches(topics, data))
+	assert.False(t, ev.Matches(topics, data[:len(data)-32]))
+	assert.False(t, ev.Matches(topics, append(data, make([]byte, 32)...)))
+	assert.False(t, ev.Matches(append(topics, topics[0]), data))
+	assert.False(t, ev.Matches([]Hash{NewHash("1")}, data))
+
+	ev.Anonymous = true
+	assert.True(t, ev.Matches(nil, data))
+	assert.False(t, ev.Matches(topics, data))
+}
+
+func TestDecodeAllDataStrict(t *testing.T) {
+	args := []ContractABIArgument{{Name: "flag", Type: "bool"}, {Name: "name", Type: "string"}}
+	data := word("1") + word("40") + word("2") + "6869" + word("")[4:]
+
+	values, err := DecodeAllDataStrict(args, hexToBytes(data))
+	assert.Nil(t, err)
+	assert.Equal(t, "hi", values.Get("name").Value)
+
+	testMatrix := []struct {
+		data          string
+		expectedPath  string
+		expectedMsg   string
+		expectedOffset uint64
+	}{
+		{data + word("0"), "", "expected 128 bytes of data, got 160", 128},
+		{word("2") + data[64:], "", "data is not the exact encoding of the arguments", 31},
+		{word("1") + word("60") + word("0") + data[128:], "", "data is not the exact encoding of the arguments", 63},
+		{data[:len(data)-2] + "01", "", "data is not the exact encoding of the arguments", 127},
+		{word("1") + word("40") + word("1f"), "name", "string of length 31 exceeds the remaining 0 bytes of data", 96},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := DecodeAllDataStrict(args, hexToBytes(test.data))
+		decodeErr, ok := err.(*DecodeError)
+		if assert.True(t, ok, "Test index %d failed", idx) {
+			assert.Equal(t, test.expectedPath, decodeErr.Path, "Test index %d failed", idx)
+			assert.Equal(t, test.expectedMsg, decodeErr.Msg, "Test index %d failed", idx)
+			assert.Equal(t, test.expectedOffset, decodeErr.Offset, "Test index %d failed", idx)
+		}
+	}
+
+	_, err = DecodeAllDataStrict([]ContractABIArgument{{Name: "small", Type: "uint8"}}, hexToBytes(word("100")))
+	assertDecodeError(t, err, "", 0, "data is not a valid encoding of the arguments: value 256 overflows uint8")
+}
@@ -0,0 +1,442 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CompiledABI is a contract ABI with its functions indexed by selector and its events indexed by
+// signature, so that transactions and events can be matched without scanning the whole ABI.
+// It must not be modified once compiled, and so is safe to share between goroutines
+type CompiledABI struct {
+	*ContractABI
+
+	functions       map[string]ContractABIFunction
+	events          map[string][]ContractABIEvent
+	anonymousEvents []ContractABIEvent
+	options         DecodeOptions
+}
+
+// CompileABI parses a JSON ABI and indexes it
+func CompileABI(rawABI string) (*CompiledABI, error) {
+	structure, err := NewABIStructureFromJSON(rawABI)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompiledABI(structure.ToInternalABI()), nil
+}
+
+// NewCompiledABI indexes the functions and events of the ABI
+func NewCompiledABI(abi *ContractABI) *CompiledABI {
+	compiled := &CompiledABI{
+		ContractABI: abi,
+		functions:   make(map[string]ContractABIFunction, len(abi.Functions)),
+		events:      make(map[string][]ContractABIEvent, len(abi.Events)),
+	}
+	for _, function := range abi.Functions {
+		//the first function is kept if the ABI somehow has two with the same selector
+		if _, exists := compiled.functions[function.Signature()]; !exists {
+			compiled.functions[function.Signature()] = function
+		}
+	}
+	for _, event := range abi.Events {
+		if event.Anonymous {
+			compiled.anonymousEvents = append(compiled.anonymousEvents, event)
+			continue
+		}
+		compiled.events[event.Signature()] = append(compiled.events[event.Signature()], event)
+	}
+	return compiled
+}
+
+// WithOptions gives a copy of the compiled ABI that decodes transactions and events with the given options,
+// sharing the same indexes
+func (compiled *CompiledABI) WithOptions(opts DecodeOptions) *CompiledABI {
+	withOptions := *compiled
+	withOptions.options = opts
+	return &withOptions
+}
+
+// Function gives the function with the given 4 byte selector, as a hex string with or without "0x"
+func (compiled *CompiledABI) Function(selector string) (ContractABIFunction, bool) {
+	function, ok := compiled.functions[normaliseHex(selector)]
+	return function, ok
+}
+
+// EventsByTopic gives the events that aren't anonymous whose signature is the given topic
+func (compiled *CompiledABI) EventsByTopic(topic Hash) []ContractABIEvent {
+	return compiled.events[normaliseHex(string(topic))]
+}
+
+// RegisteredFunction is a function from one of the templates in an ABIRegistry
+type RegisteredFunction struct {
+	TemplateName string
+	Function     ContractABIFunction
+}
+
+// RegisteredEvent is an event from one of the templates in an ABIRegistry
+type RegisteredEvent struct {
+	TemplateName string
+	Event        ContractABIEvent
+}
+
+// ABICollision is a selector or event topic that is shared by different definitions across the templates
+// in an ABIRegistry, so the data for it can't be decoded without knowing which template the contract uses
+type ABICollision struct {
+	// Kind is either "function" or "event"
+	Kind string `json:"kind"`
+	// Selector is the 4 byte selector of a function, or the signature topic of an event
+	Selector string `json:"selector"`
+	// Definitions are the different definitions that share the selector, see ABIRegistry.Collisions
+	Definitions []string `json:"definitions"`
+	// Templates are the names of the templates that declare each definition, in the same order
+	Templates [][]string `json:"templates"`
+}
+
+/*
+ABIRegistry holds the compiled ABIs of a set of templates, along with which template each contract address uses.
+
+Functions are indexed by selector and events by their signature topic across every template, so that transactions
+and logs can be decoded without re-parsing the ABI each time. Transactions and events from a contract that is bound
+to a template are decoded using only that template. Otherwise they are decoded using any template that declares a
+matching function or event, although anonymous events can only be decoded for a bound contract since they have no
+signature to look them up by.
+
+The registry is safe for concurrent use.
+*/
+type ABIRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]*CompiledABI
+	bindings  map[Address]string
+	functions map[string][]RegisteredFunction
+	events    map[string][]RegisteredEvent
+	options   DecodeOptions
+}
+
+// NewABIRegistry creates an empty registry
+func NewABIRegistry() *ABIRegistry {
+	return NewABIRegistryWithOptions(DecodeOptions{})
+}
+
+// NewABIRegistryWithOptions creates an empty registry that decodes transactions and events with the given options
+func NewABIRegistryWithOptions(opts DecodeOptions) *ABIRegistry {
+	return &ABIRegistry{
+		templates: make(map[string]*CompiledABI),
+		bindings:  make(map[Address]string),
+		functions: make(map[string][]RegisteredFunction),
+		events:    make(map[string][]RegisteredEvent),
+		options:   opts,
+	}
+}
+
+// Register compiles the ABI of the template and adds it to the registry, replacing
+// any template that was registered with the same name
+func (r *ABIRegistry) Register(template Template) error {
+	if template.TemplateName == "" {
+		return errors.New("template name is required")
+	}
+	compiled, err := CompileABI(template.ABI)
+	if err != nil {
+		return fmt.Errorf("invalid ABI for template %s: %s", template.TemplateName, err.Error())
+	}
+	compiled = compiled.WithOptions(r.options)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeFromIndexes(template.TemplateName)
+	r.templates[template.TemplateName] = compiled
+	for _, function := range compiled.Functions {
+		selector := function.Signature()
+		r.functions[selector] = append(r.functions[selector], RegisteredFunction{template.TemplateName, function})
+	}
+	for _, event := range compiled.Events {
+		if !event.Anonymous {
+			topic := event.Signature()
+			r.events[topic] = append(r.events[topic], RegisteredEvent{template.TemplateName, event})
+		}
+	}
+	return nil
+}
+
+// Unregister removes the template from the registry, along with any addresses bound to it
+func (r *ABIRegistry) Unregister(templateName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeFromIndexes(templateName)
+	delete(r.templates, templateName)
+	for address, bound := range r.bindings {
+		if bound == templateName {
+			delete(r.bindings, address)
+		}
+	}
+}
+
+//removeFromIndexes removes the functions and events of the template from the selector and topic indexes
+func (r *ABIRegistry) removeFromIndexes(templateName string) {
+	if _, exists := r.templates[templateName]; !exists {
+		return
+	}
+	for selector, registered := range r.functions {
+		kept := registered[:0]
+		for _, entry := range registered {
+			if entry.TemplateName != templateName {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(r.functions, selector)
+		} else {
+			r.functions[selector] = kept
+		}
+	}
+	for topic, registered := range r.events {
+		kept := registered[:0]
+		for _, entry := range registered {
+			if entry.TemplateName != templateName {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(r.events, topic)
+		} else {
+			r.events[topic] = kept
+		}
+	}
+}
+
+// Bind sets the template used by the contract at the given address, which must already be registered
+func (r *ABIRegistry) Bind(address Address, templateName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[templateName]; !exists {
+		return errors.New("unknown template: " + templateName)
+	}
+	r.bindings[normaliseAddress(address)] = templateName
+	return nil
+}
+
+// Unbind removes the template binding of the contract at the given address
+func (r *ABIRegistry) Unbind(address Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.bindings, normaliseAddress(address))
+}
+
+// Template gives the compiled ABI of the named template
+func (r *ABIRegistry) Template(templateName string) (*CompiledABI, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	compiled, ok := r.templates[templateName]
+	return compiled, ok
+}
+
+// TemplateFor gives the name and compiled ABI of the template bound to the contract at the given address
+func (r *ABIRegistry) TemplateFor(address Address) (string, *CompiledABI, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templateName, ok := r.bindings[normaliseAddress(address)]
+	if !ok {
+		return "", nil, false
+	}
+	return templateName, r.templates[templateName], true
+}
+
+// Functions gives every registered function with the given 4 byte selector, as a hex string with or without "0x"
+func (r *ABIRegistry) Functions(selector string) []RegisteredFunction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]RegisteredFunction(nil), r.functions[normaliseHex(selector)]...)
+}
+
+// Events gives every registered event that isn't anonymous whose signature is the given topic
+func (r *ABIRegistry) Events(topic Hash) []RegisteredEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]RegisteredEvent(nil), r.events[normaliseHex(string(topic))]...)
+}
+
+/*
+Collisions reports the selectors and topics that have different definitions in different templates, sorted by kind
+and selector.
+
+Functions collide if they have the same selector but a different signature, e.g. "transfer(address,uint256)" and
+"gasprice_bit_ether(int128)". Events can only share a topic if they have the same signature, but they still collide
+if they index different arguments, such as the Transfer events of ERC-20 and ERC-721, since their topics and data
+decode differently. The same definition declared by many templates is not a collision.
+*/
+func (r *ABIRegistry) Collisions() []ABICollision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var collisions []ABICollision
+	for selector, registered := range r.functions {
+		definitions := make([]string, len(registered))
+		templates := make([]string, len(registered))
+		for i, entry := range registered {
+			definitions[i] = entry.Function.StringNoName()
+			templates[i] = entry.TemplateName
+		}
+		if collision, ok := newABICollision("function", selector, definitions, templates); ok {
+			collisions = append(collisions, collision)
+		}
+	}
+	for topic, registered := range r.events {
+		definitions := make([]string, len(registered))
+		templates := make([]string, len(registered))
+		for i, entry := range registered {
+			definitions[i] = eventDefinition(entry.Event)
+			templates[i] = entry.TemplateName
+		}
+		if collision, ok := newABICollision("event", topic, definitions, templates); ok {
+			collisions = append(collisions, collision)
+		}
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		if collisions[i].Kind != collisions[j].Kind {
+			return collisions[i].Kind > collisions[j].Kind
+		}
+		return collisions[i].Selector < collisions[j].Selector
+	})
+	return collisions
+}
+
+//newABICollision groups the templates by definition, giving a collision if there is more than one definition
+func newABICollision(kind string, selector string, definitions []string, templates []string) (ABICollision, bool) {
+	byDefinition := make(map[string][]string)
+	for i, definition := range definitions {
+		byDefinition[definition] = append(byDefinition[definition], templates[i])
+	}
+	if len(byDefinition) < 2 {
+		return ABICollision{}, false
+	}
+
+	collision := ABICollision{Kind: kind, Selector: "0x" + selector}
+	for definition := range byDefinition {
+		collision.Definitions = append(collision.Definitions, definition)
+	}
+	sort.Strings(collision.Definitions)
+	for _, definition := range collision.Definitions {
+		names := byDefinition[definition]
+		sort.Strings(names)
+		collision.Templates = append(collision.Templates, names)
+	}
+	return collision, true
+}
+
+//eventDefinition gives the signature of the event along with which of its arguments are indexed,
+//e.g. "Transfer(address indexed,address indexed,uint256)"
+func eventDefinition(event ContractABIEvent) string {
+	inputs := make([]string, len(event.Inputs))
+	for i, input := range event.Inputs {
+		inputs[i] = input.StringNoName()
+		if input.Indexed {
+			inputs[i] += " indexed"
+		}
+	}
+	return fmt.Sprintf("%s(%s)", event.Name, strings.Join(inputs, ","))
+}
+
+/*
+ParseTransaction parses the transaction in the same way as ParsedTransaction.ParseTransaction, using the template
+bound to the contract it was sent to, or the contract it created for a deployment.
+
+If the contract isn't bound to a template, the function is found by its selector across every template instead,
+which fails if no template declares the function or if the selector is a collision (see Collisions).
+*/
+func (r *ABIRegistry) ParseTransaction(ptx *ParsedTransaction) error {
+	if ptx.RawTransaction == nil {
+		return errors.New("transaction is nil or invalid")
+	}
+
+	address := ptx.RawTransaction.To
+	if address.IsEmpty() {
+		address = ptx.RawTransaction.CreatedContract
+	}
+	if _, compiled, ok := r.TemplateFor(address); ok {
+		return ptx.ParseTransactionWithABI(compiled)
+	}
+	if ptx.RawTransaction.To.IsEmpty() {
+		return errors.New("no template is bound to created contract " + address.String())
+	}
+
+	data := ptx.RawTransaction.Data.AsBytes()
+	if len(ptx.RawTransaction.PrivateData) > 0 {
+		data = ptx.RawTransaction.PrivateData.AsBytes()
+	}
+	if len(data) < 4 {
+		return errors.New("no template is bound to contract " + address.String())
+	}
+	selector := hex.EncodeToString(data[:4])
+
+	registered := r.Functions(selector)
+	if len(registered) == 0 {
+		return errors.New("no template has a function with selector 0x" + selector)
+	}
+	for _, entry := range registered[1:] {
+		if entry.Function.StringNoName() != registered[0].Function.StringNoName() {
+			return errors.New("function selector 0x" + selector + " is declared differently by more than one template")
+		}
+	}
+	compiled, ok := r.Template(registered[0].TemplateName)
+	if !ok {
+		return errors.New("unknown template: " + registered[0].TemplateName)
+	}
+	return ptx.ParseTransactionWithABI(compiled)
+}
+
+/*
+ParseEvent parses the event in the same way as ParsedEvent.ParseEvent, using the template bound to the contract
+that emitted it.
+
+If the contract isn't bound to a template, every registered event whose signature is the first topic is tried
+instead, with each distinct definition tried once no matter how many templates declare it.
+*/
+func (r *ABIRegistry) ParseEvent(pe *ParsedEvent) error {
+	if pe.RawEvent == nil {
+		return errors.New("event is nil or invalid")
+	}
+
+	if _, compiled, ok := r.TemplateFor(pe.RawEvent.Address); ok {
+		return pe.ParseEventWithABI(compiled)
+	}
+	if len(pe.RawEvent.Topics) == 0 {
+		return errors.New("no template is bound to contract " + pe.RawEvent.Address.String())
+	}
+
+	registered := r.Events(pe.RawEvent.Topics[0])
+	if len(registered) == 0 {
+		return errors.New("no template has an event with topic " + pe.RawEvent.Topics[0].String())
+	}
+	var events []ContractABIEvent
+	seen := make(map[string]bool)
+	for _, entry := range registered {
+		if definition := eventDefinition(entry.Event); !seen[definition] {
+			seen[definition] = true
+			events = append(events, entry.Event)
+		}
+	}
+	return pe.parseEvent(events, nil, r.options)
+}
+
+//normaliseAddress gives the address in the form used as a key for bindings
+func normaliseAddress(address Address) Address {
+	return NewAddress(strings.ToLower(string(address)))
+}
+
+//normaliseHex gives the hex string in the form used as a key for selectors and topics
+func normaliseHex(hexString string) string {
+	return strings.ToLower(strings.TrimPrefix(hexString, "0x"))
+}
@@ -0,0 +1,240 @@
+package types
+
+import (
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const erc20ABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+const erc721ABI = `[
+	{"type":"function","name":"transferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"Log","anonymous":true,"inputs":[{"name":"message","type":"string","indexed":false}]}
+]`
+
+//the selector of gasprice_bit_ether(int128) is the same as transferFrom(address,address,uint256)
+const collidingABI = `[
+	{"type":"function","name":"gasprice_bit_ether","inputs":[{"name":"value","type":"int128"}],"outputs":[]}
+]`
+
+var (
+	tokenAddress      = NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17")
+	collectionAddress = NewAddress("0x9d13c6d3afe1721beef56b55d303b09e021e27ab")
+	transferTopic     = NewHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+)
+
+func testRegistry(t *testing.T, templates ...Template) *ABIRegistry {
+	registry := NewABIRegistry()
+	for _, template := range templates {
+		assert.Nil(t, registry.Register(template))
+	}
+	return registry
+}
+
+func transferTransaction(t *testing.T, to Address) *ParsedTransaction {
+	compiled, err := CompileABI(erc20ABI)
+	assert.Nil(t, err)
+	input, err := compiled.Functions[0].Pack(NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"), 1000)
+	assert.Nil(t, err)
+	return &ParsedTransaction{RawTransaction: &Transaction{Status: true, To: to, Data: HexData(hex.EncodeToString(input))}}
+}
+
+func TestCompiledABI_Lookup(t *testing.T) {
+	compiled, err := CompileABI(erc721ABI)
+	assert.Nil(t, err)
+
+	function, ok := compiled.Function("0x23b872dd")
+	assert.True(t, ok)
+	assert.Equal(t, "transferFrom", function.Name)
+	_, ok = compiled.Function("a9059cbb")
+	assert.False(t, ok)
+
+	events := compiled.EventsByTopic(transferTopic)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "Transfer", events[0].Name)
+	}
+	assert.Len(t, compiled.anonymousEvents, 1)
+}
+
+func TestABIRegistry_Register(t *testing.T) {
+	registry := NewABIRegistry()
+
+	assert.EqualError(t, registry.Register(Template{ABI: erc20ABI}), "template name is required")
+	assert.EqualError(t, registry.Register(Template{TemplateName: "Broken", ABI: `[{"type":"function","name":"f","inputs":[{"name":"a","type":"uint7"}]}]`}),
+		"invalid ABI for template Broken: invalid function f: input a: invalid integer type: uint7, the size must be a multiple of 8 from 8 to 256")
+	assert.EqualError(t, registry.Bind(tokenAddress, "ERC20"), "unknown template: ERC20")
+
+	assert.Nil(t, registry.Register(Template{TemplateName: "ERC20", ABI: erc20ABI}))
+	assert.Nil(t, registry.Bind(NewAddress("0x1349F3E1B8D71EFFB47B840594FF27DA7E603D17"), "ERC20"))
+
+	name, compiled, ok := registry.TemplateFor(tokenAddress)
+	assert.True(t, ok)
+	assert.Equal(t, "ERC20", name)
+	assert.Equal(t, "transfer", compiled.Functions[0].Name)
+	assert.Len(t, registry.Functions("0xa9059cbb"), 1)
+	assert.Len(t, registry.Events(transferTopic), 1)
+
+	//registering again replaces the template in the indexes
+	assert.Nil(t, registry.Register(Template{TemplateName: "ERC20", ABI: erc721ABI}))
+	assert.Len(t, registry.Functions("0xa9059cbb"), 0)
+	assert.Len(t, registry.Functions("0x23b872dd"), 1)
+	assert.Len(t, registry.Events(transferTopic), 1)
+
+	registry.Unregister("ERC20")
+	_, _, ok = registry.TemplateFor(tokenAddress)
+	assert.False(t, ok)
+	assert.Len(t, registry.Events(transferTopic), 0)
+}
+
+func TestABIRegistry_Collisions(t *testing.T) {
+	registry := testRegistry(t,
+		Template{TemplateName: "TokenA", ABI: erc20ABI},
+		Template{TemplateName: "TokenB", ABI: erc20ABI},
+		Template{TemplateName: "Collection", ABI: erc721ABI},
+		Template{TemplateName: "Colliding", ABI: collidingABI},
+	)
+
+	assert.Equal(t, []ABICollision{
+		{
+			Kind:        "function",
+			Selector:    "0x23b872dd",
+			Definitions: []string{"gasprice_bit_ether(int128)", "transferFrom(address,address,uint256)"},
+			Templates:   [][]string{{"Colliding"}, {"Collection"}},
+		},
+		{
+			Kind:        "event",
+			Selector:    "0x" + string(transferTopic),
+			Definitions: []string{"Transfer(address indexed,address indexed,uint256 indexed)", "Transfer(address indexed,address indexed,uint256)"},
+			Templates:   [][]string{{"Collection"}, {"TokenA", "TokenB"}},
+		},
+	}, registry.Collisions())
+
+	registry.Unregister("Colliding")
+	registry.Unregister("Collection")
+	assert.Nil(t, registry.Collisions())
+}
+
+func TestABIRegistry_ParseTransaction(t *testing.T) {
+	registry := testRegistry(t, Template{TemplateName: "ERC20", ABI: erc20ABI}, Template{TemplateName: "ERC721", ABI: erc721ABI})
+	assert.Nil(t, registry.Bind(tokenAddress, "ERC20"))
+
+	//a bound contract, then an unbound contract found by selector
+	for _, to := range []Address{tokenAddress, collectionAddress} {
+		ptx := transferTransaction(t, to)
+
+		assert.Nil(t, registry.ParseTransaction(ptx))
+		assert.Equal(t, "transfer(address to,uint256 value)", ptx.Sig)
+		assert.EqualValues(t, big.NewInt(1000), ptx.ParsedData["value"])
+	}
+
+	//a bound contract only uses its own template
+	assert.Nil(t, registry.Bind(collectionAddress, "ERC721"))
+	ptx := transferTransaction(t, collectionAddress)
+	assert.Nil(t, registry.ParseTransaction(ptx))
+	assert.Equal(t, "", ptx.Sig)
+
+	assert.Nil(t, registry.Register(Template{TemplateName: "Colliding", ABI: collidingABI}))
+	ptx = &ParsedTransaction{RawTransaction: &Transaction{To: NewAddress("0x01"), Data: NewHexData("23b872dd" + word("1"))}}
+	assert.EqualError(t, registry.ParseTransaction(ptx), "function selector 0x23b872dd is declared differently by more than one template")
+
+	ptx = &ParsedTransaction{RawTransaction: &Transaction{To: NewAddress("0x01"), Data: "12345678"}}
+	assert.EqualError(t, registry.ParseTransaction(ptx), "no template has a function with selector 0x12345678")
+}
+
+func TestABIRegistry_ParseEvent(t *testing.T) {
+	registry := testRegistry(t,
+		Template{TemplateName: "TokenA", ABI: erc20ABI},
+		Template{TemplateName: "TokenB", ABI: erc20ABI},
+		Template{TemplateName: "Collection", ABI: erc721ABI},
+	)
+	assert.Nil(t, registry.Bind(collectionAddress, "Collection"))
+
+	from := NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34")
+	to := NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab")
+
+	testMatrix := []struct {
+		event       *Event
+		expectedSig string
+		expectedKey string
+	}{
+		//unbound, so the ERC-20 event is the only one that matches, even though two templates declare it
+		{&Event{Address: tokenAddress, Topics: []Hash{transferTopic, from, to}, Data: NewHexData(word("3e8"))}, "event Transfer(address from,address to,uint256 value)", "value"},
+		{&Event{Address: tokenAddress, Topics: []Hash{transferTopic, from, to, NewHash("3e8")}}, "event Transfer(address from,address to,uint256 tokenId)", "tokenId"},
+		//bound, so anonymous events can be matched
+		{&Event{Address: collectionAddress, Data: NewHexData(word("20") + word("2") + "6869" + word("")[4:])}, "event Log(string message)", "message"},
+	}
+
+	for idx, test := range testMatrix {
+		pe := &ParsedEvent{RawEvent: test.event}
+
+		err := registry.ParseEvent(pe)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedSig, pe.Sig, "Test index %d failed", idx)
+		assert.Contains(t, pe.ParsedData, test.expectedKey, "Test index %d failed", idx)
+		assert.Nil(t, pe.Candidates, "Test index %d failed", idx)
+	}
+
+	pe := &ParsedEvent{RawEvent: &Event{Address: tokenAddress, Topics: []Hash{NewHash("01")}}}
+	assert.EqualError(t, registry.ParseEvent(pe), "no template has an event with topic 0x0000000000000000000000000000000000000000000000000000000000000001")
+}
+
+func TestABIRegistry_Options(t *testing.T) {
+	storeABI := `[{"type":"function","name":"store","inputs":[{"name":"data","type":"bytes"}],"outputs":[]}]`
+	compiled, err := CompileABI(storeABI)
+	assert.Nil(t, err)
+	input, err := compiled.Functions[0].Pack([]byte{0x12, 0x34})
+	assert.Nil(t, err)
+
+	testMatrix := []struct {
+		registry *ABIRegistry
+		expected interface{}
+	}{
+		{NewABIRegistry(), "0x1234"},
+		{NewABIRegistryWithOptions(DecodeOptions{BytesFormat: BytesAsSlice}), []byte{0x12, 0x34}},
+	}
+
+	for idx, test := range testMatrix {
+		assert.Nil(t, test.registry.Register(Template{TemplateName: "Store", ABI: storeABI}), "Test index %d failed", idx)
+		ptx := &ParsedTransaction{RawTransaction: &Transaction{To: tokenAddress, Data: HexData(hex.EncodeToString(input))}}
+
+		assert.Nil(t, test.registry.ParseTransaction(ptx), "Test index %d failed", idx)
+		assert.Equal(t, test.expected, ptx.ParsedData["data"], "Test index %d failed", idx)
+	}
+}
+
+func TestABIRegistry_Concurrent(t *testing.T) {
+	registry := testRegistry(t, Template{TemplateName: "ERC20", ABI: erc20ABI})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, registry.Register(Template{TemplateName: "ERC721", ABI: erc721ABI}))
+			assert.Nil(t, registry.Bind(collectionAddress, "ERC721"))
+			registry.Collisions()
+		}()
+		go func() {
+			defer wg.Done()
+			ptx := transferTransaction(t, tokenAddress)
+			assert.Nil(t, registry.ParseTransaction(ptx))
+			assert.Equal(t, "transfer(address to,uint256 value)", ptx.Sig)
+		}()
+	}
+	wg.Wait()
+}
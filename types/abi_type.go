@@ -0,0 +1,325 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ABITypeKind is the kind of value an ABI type holds
+type ABITypeKind int
+
+const (
+	UintKind ABITypeKind = iota
+	IntKind
+	AddressKind
+	BoolKind
+	// FixedBytesKind is a bytes<x> type
+	FixedBytesKind
+	// FixedPointKind is a fixed<M>x<N> or ufixed<M>x<N> type
+	FixedPointKind
+	FunctionKind
+	BytesKind
+	StringKind
+	// ArrayKind is an array with a fixed number of elements, T[k]
+	ArrayKind
+	// SliceKind is an array with a dynamic number of elements, T[]
+	SliceKind
+	TupleKind
+)
+
+/*
+ABIType is a parsed ABI type, which is either an elementary type, an array of another type, or a tuple of other types.
+
+Types are parsed using the grammar from the ABI spec, and anything that isn't a valid type is rejected:
+
+	type       = elementary | "tuple" | type "[" "]" | type "[" size "]"
+	elementary = "uint" | "uint" M | "int" | "int" M | "address" | "bool" | "bytes" | "bytes" N | "string" |
+	             "fixed" | "fixed" M "x" D | "ufixed" | "ufixed" M "x" D | "function"
+
+where M is a multiple of 8 from 8 to 256, N is from 1 to 32, D is from 1 to 80 and size is at least 1.
+The components of a tuple are given separately, as they are in the JSON ABI.
+*/
+type ABIType struct {
+	Kind ABITypeKind
+
+	// Signed is set for int<M> and fixed<M>x<N> types
+	Signed bool
+	// Size is the number of bits of an integer or fixed point type, or the number of bytes of a bytes<x> type
+	Size uint
+	// Decimals is the number of decimal places of a fixed point type
+	Decimals uint
+
+	// Elem is the type of the elements of an array, and Length the number of elements if it is fixed
+	Elem   *ABIType
+	Length uint64
+
+	// Components are the types of the components of a tuple, and ComponentNames their names
+	Components     []*ABIType
+	ComponentNames []string
+}
+
+const (
+	//maxArrayLength is the largest length of a fixed size array type, so that the size of its heads can't overflow
+	maxArrayLength = 1 << 32
+	//maxStaticSize is the largest number of bytes that a static type can be encoded as, so that its size can be
+	//worked out without overflowing however deeply arrays of it are nested
+	maxStaticSize = 1 << 40
+)
+
+// ParseABIType parses and validates an ABI type, such as "uint256", "bytes32[2][]" or "tuple",
+// where the components are only used for tuples
+func ParseABIType(typeName string, components []ContractABIArgument) (*ABIType, error) {
+	//arrays are read from the end, so that "T[2][]" is a dynamic array of T[2]
+	if strings.HasSuffix(typeName, "]") {
+		start := strings.LastIndex(typeName, "[")
+		if start < 0 {
+			return nil, errors.New("invalid array type: " + typeName + ", missing '['")
+		}
+		elem, err := ParseABIType(typeName[:start], components)
+		if err != nil {
+			return nil, err
+		}
+
+		sizeString := typeName[start+1 : len(typeName)-1]
+		if sizeString == "" {
+			return &ABIType{Kind: SliceKind, Elem: elem}, nil
+		}
+		length, err := strconv.ParseUint(sizeString, 10, 64)
+		if err != nil || length == 0 || sizeString[0] == '0' {
+			return nil, fmt.Errorf("invalid array type: %s, the size must be a positive integer", typeName)
+		}
+		if length > maxArrayLength {
+			return nil, fmt.Errorf("invalid array type: %s, the size can't be more than %d", typeName, uint64(maxArrayLength))
+		}
+		if !elem.IsDynamic() && elem.staticSize() > maxStaticSize/length {
+			return nil, fmt.Errorf("invalid array type: %s, it can't be encoded as more than %d bytes", typeName, uint64(maxStaticSize))
+		}
+		return &ABIType{Kind: ArrayKind, Elem: elem, Length: length}, nil
+	}
+	if strings.ContainsAny(typeName, "[]") {
+		return nil, errors.New("invalid array type: " + typeName + ", missing ']'")
+	}
+
+	if typeName == "tuple" {
+		t := &ABIType{Kind: TupleKind}
+		for i, comp := range components {
+			compType, err := comp.ParsedType()
+			if err != nil {
+				return nil, fmt.Errorf("invalid tuple component %s: %s", argumentKey(comp, i), err.Error())
+			}
+			t.Components = append(t.Components, compType)
+			t.ComponentNames = append(t.ComponentNames, comp.Name)
+		}
+		if !t.IsDynamic() && t.staticSize() > maxStaticSize {
+			return nil, fmt.Errorf("invalid tuple type, it can't be encoded as more than %d bytes", uint64(maxStaticSize))
+		}
+		return t, nil
+	}
+	return parseElementaryType(typeName)
+}
+
+func parseElementaryType(typeName string) (*ABIType, error) {
+	switch typeName {
+	case "address":
+		return &ABIType{Kind: AddressKind}, nil
+	case "bool":
+		return &ABIType{Kind: BoolKind}, nil
+	case "bytes":
+		return &ABIType{Kind: BytesKind}, nil
+	case "string":
+		return &ABIType{Kind: StringKind}, nil
+	case "function":
+		return &ABIType{Kind: FunctionKind}, nil
+	}
+
+	switch {
+	case strings.HasPrefix(typeName, "uint"), strings.HasPrefix(typeName, "int"):
+		signed := strings.HasPrefix(typeName, "int")
+		bits, ok := parseTypeSize(strings.TrimPrefix(strings.TrimPrefix(typeName, "u"), "int"), 256)
+		if !ok || bits%8 != 0 || bits > 256 {
+			return nil, errors.New("invalid integer type: " + typeName + ", the size must be a multiple of 8 from 8 to 256")
+		}
+		kind := UintKind
+		if signed {
+			kind = IntKind
+		}
+		return &ABIType{Kind: kind, Signed: signed, Size: bits}, nil
+
+	case strings.HasPrefix(typeName, "bytes"):
+		size, ok := parseTypeSize(typeName[5:], 0)
+		if !ok || size > 32 {
+			return nil, errors.New("invalid fixed bytes type: " + typeName + ", the size must be from 1 to 32")
+		}
+		return &ABIType{Kind: FixedBytesKind, Size: size}, nil
+
+	case strings.HasPrefix(typeName, "fixed"), strings.HasPrefix(typeName, "ufixed"):
+		signed := strings.HasPrefix(typeName, "fixed")
+		sizes := strings.TrimPrefix(strings.TrimPrefix(typeName, "u"), "fixed")
+		if sizes == "" {
+			return &ABIType{Kind: FixedPointKind, Signed: signed, Size: 128, Decimals: 18}, nil
+		}
+		var bits, decimals uint
+		valid := false
+		if separator := strings.Index(sizes, "x"); separator >= 0 {
+			var bitsOk, decimalsOk bool
+			bits, bitsOk = parseTypeSize(sizes[:separator], 0)
+			decimals, decimalsOk = parseTypeSize(sizes[separator+1:], 0)
+			valid = bitsOk && decimalsOk && bits%8 == 0 && bits <= 256 && decimals <= 80
+		}
+		if !valid {
+			return nil, errors.New("invalid fixed point type: " + typeName + ", the size must be a multiple of 8 from 8 to 256 and the decimals from 1 to 80")
+		}
+		return &ABIType{Kind: FixedPointKind, Signed: signed, Size: bits, Decimals: decimals}, nil
+	}
+
+	return nil, errors.New("unknown type: " + typeName)
+}
+
+//parseTypeSize parses the size given after the name of an elementary type, which must be a positive decimal
+//without leading zeroes. An empty size gives the default, if there is one
+func parseTypeSize(size string, defaultSize uint) (uint, bool) {
+	if size == "" {
+		return defaultSize, defaultSize != 0
+	}
+	if size[0] == '0' {
+		return 0, false
+	}
+	parsed, err := strconv.ParseUint(size, 10, 16)
+	if err != nil {
+		return 0, false
+	}
+	return uint(parsed), true
+}
+
+// tupleType gives the type of a list of arguments, such as the inputs of a function, which are encoded as a tuple
+func tupleType(args []ContractABIArgument) (*ABIType, error) {
+	return ParseABIType("tuple", args)
+}
+
+// ParsedType parses and validates the type of the argument
+func (arg ContractABIArgument) ParsedType() (*ABIType, error) {
+	return ParseABIType(arg.Type, arg.Components)
+}
+
+// String gives the canonical form of the type used in signatures, where aliases such as "uint" are
+// expanded and tuples are given as their components, e.g. "(uint256,bytes32)[]"
+func (t *ABIType) String() string {
+	switch t.Kind {
+	case ArrayKind:
+		return fmt.Sprintf("%s[%d]", t.Elem.String(), t.Length)
+	case SliceKind:
+		return t.Elem.String() + "[]"
+	case TupleKind:
+		componentSigs := make([]string, len(t.Components))
+		for i, comp := range t.Components {
+			componentSigs[i] = comp.String()
+		}
+		return "(" + strings.Join(componentSigs, ",") + ")"
+	}
+	return t.TypeName()
+}
+
+// TypeName gives the type as it is written in a JSON ABI, where tuples are given as "tuple", e.g. "tuple[]"
+func (t *ABIType) TypeName() string {
+	switch t.Kind {
+	case UintKind:
+		return fmt.Sprintf("uint%d", t.Size)
+	case IntKind:
+		return fmt.Sprintf("int%d", t.Size)
+	case AddressKind:
+		return "address"
+	case BoolKind:
+		return "bool"
+	case FixedBytesKind:
+		return fmt.Sprintf("bytes%d", t.Size)
+	case FixedPointKind:
+		if t.Signed {
+			return fmt.Sprintf("fixed%dx%d", t.Size, t.Decimals)
+		}
+		return fmt.Sprintf("ufixed%dx%d", t.Size, t.Decimals)
+	case FunctionKind:
+		return "function"
+	case BytesKind:
+		return "bytes"
+	case StringKind:
+		return "string"
+	case ArrayKind:
+		return fmt.Sprintf("%s[%d]", t.Elem.TypeName(), t.Length)
+	case SliceKind:
+		return t.Elem.TypeName() + "[]"
+	}
+	return "tuple"
+}
+
+/*
+IsDynamic reports whether the type is encoded in the tail of the data, with only its offset in the head:
+
++-----------------+--------+---------+
+|      Type       | Static | Dynamic |
++-----------------+--------+---------+
+| uint<x>         | ✔      |         |
+| int<x>          | ✔      |         |
+| address         | ✔      |         |
+| bool            | ✔      |         |
+| bytes<x>        | ✔      |         |
+| fixed<M>x<N>    | ✔      |         |
+| ufixed<M>x<N>   | ✔      |         |
+| function        | ✔      |         |
+| bytes           |        | ✔       |
+| string          |        | ✔       |
+| T[]             |        | ✔       |
+| T<static>[m]    | ✔      |         |
+| T<dynamic>[m]   |        | ✔       |
+| Tuple<static>   | ✔      |         |
+| Tuple<dynamic>  |        | ✔       |
++-----------------+--------+---------+
+
+Note: a tuple is dynamic if at least one element is dynamic
+*/
+func (t *ABIType) IsDynamic() bool {
+	switch t.Kind {
+	case BytesKind, StringKind, SliceKind:
+		return true
+	case ArrayKind:
+		return t.Elem.IsDynamic()
+	case TupleKind:
+		for _, comp := range t.Components {
+			if comp.IsDynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isArray reports whether the type is an array of either fixed or dynamic size
+func (t *ABIType) isArray() bool {
+	return t.Kind == ArrayKind || t.Kind == SliceKind
+}
+
+//staticSize gives the number of bytes a static type takes up in the encoding
+func (t *ABIType) staticSize() uint64 {
+	switch t.Kind {
+	case ArrayKind:
+		return t.Length * t.Elem.staticSize()
+	case TupleKind:
+		size := uint64(0)
+		for _, comp := range t.Components {
+			size += comp.staticSize()
+		}
+		return size
+	}
+	return 32
+}
+
+//repeated gives a list with the type repeated for each element of an array, so that the
+//array can be handled as a tuple of its elements
+func (t *ABIType) repeated(numberOfElements uint64) []*ABIType {
+	elements := make([]*ABIType, numberOfElements)
+	for i := range elements {
+		elements[i] = t
+	}
+	return elements
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseABIType(t *testing.T) {
+	components := []ContractABIArgument{{Name: "id", Type: "uint"}, {Name: "data", Type: "bytes"}}
+
+	testMatrix := []struct {
+		typeName          string
+		expectedKind      ABITypeKind
+		expectedString    string
+		expectedTypeName  string
+		expectedIsDynamic bool
+	}{
+		{"uint", UintKind, "uint256", "uint256", false},
+		{"int8", IntKind, "int8", "int8", false},
+		{"address", AddressKind, "address", "address", false},
+		{"bool", BoolKind, "bool", "bool", false},
+		{"bytes1", FixedBytesKind, "bytes1", "bytes1", false},
+		{"bytes32", FixedBytesKind, "bytes32", "bytes32", false},
+		{"ufixed", FixedPointKind, "ufixed128x18", "ufixed128x18", false},
+		{"fixed256x80", FixedPointKind, "fixed256x80", "fixed256x80", false},
+		{"function", FunctionKind, "function", "function", false},
+		{"bytes", BytesKind, "bytes", "bytes", true},
+		{"string", StringKind, "string", "string", true},
+		{"uint[2]", ArrayKind, "uint256[2]", "uint256[2]", false},
+		{"string[2]", ArrayKind, "string[2]", "string[2]", true},
+		{"int[2][]", SliceKind, "int256[2][]", "int256[2][]", true},
+		{"tuple", TupleKind, "(uint256,bytes)", "tuple", true},
+		{"tuple[3]", ArrayKind, "(uint256,bytes)[3]", "tuple[3]", true},
+	}
+
+	for idx, test := range testMatrix {
+		parsed, err := ParseABIType(test.typeName, components)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedKind, parsed.Kind, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedString, parsed.String(), "Test index %d failed", idx)
+		assert.Equal(t, test.expectedTypeName, parsed.TypeName(), "Test index %d failed", idx)
+		assert.Equal(t, test.expectedIsDynamic, parsed.IsDynamic(), "Test index %d failed", idx)
+	}
+}
+
+func TestParseABIType_Sizes(t *testing.T) {
+	parsed, err := ParseABIType("ufixed64x10[4][]", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, SliceKind, parsed.Kind)
+	assert.Equal(t, ArrayKind, parsed.Elem.Kind)
+	assert.EqualValues(t, 4, parsed.Elem.Length)
+	assert.Equal(t, &ABIType{Kind: FixedPointKind, Size: 64, Decimals: 10}, parsed.Elem.Elem)
+}
+
+func TestParseABIType_Invalid(t *testing.T) {
+	testMatrix := []struct {
+		typeName      string
+		components    []ContractABIArgument
+		expectedError string
+	}{
+		{"uint7", nil, "invalid integer type: uint7, the size must be a multiple of 8 from 8 to 256"},
+		{"int264", nil, "invalid integer type: int264, the size must be a multiple of 8 from 8 to 256"},
+		{"uint08", nil, "invalid integer type: uint08, the size must be a multiple of 8 from 8 to 256"},
+		{"uintx", nil, "invalid integer type: uintx, the size must be a multiple of 8 from 8 to 256"},
+		{"bytes0", nil, "invalid fixed bytes type: bytes0, the size must be from 1 to 32"},
+		{"bytes33", nil, "invalid fixed bytes type: bytes33, the size must be from 1 to 32"},
+		{"fixed128x81", nil, "invalid fixed point type: fixed128x81, the size must be a multiple of 8 from 8 to 256 and the decimals from 1 to 80"},
+		{"ufixed128", nil, "invalid fixed point type: ufixed128, the size must be a multiple of 8 from 8 to 256 and the decimals from 1 to 80"},
+		{"uint256[x]", nil, "invalid array type: uint256[x], the size must be a positive integer"},
+		{"uint256[0]", nil, "invalid array type: uint256[0], the size must be a positive integer"},
+		{"uint256[01]", nil, "invalid array type: uint256[01], the size must be a positive integer"},
+		{"uint256[2", nil, "invalid array type: uint256[2, missing ']'"},
+		{"uint256]", nil, "invalid array type: uint256], missing '['"},
+		{"uint256[2]x", nil, "invalid array type: uint256[2]x, missing ']'"},
+		{"uint256[2][0]", nil, "invalid array type: uint256[2][0], the size must be a positive integer"},
+		{"uint256[18446744073709551615]", nil, "invalid array type: uint256[18446744073709551615], the size can't be more than 4294967296"},
+		{"string[4294967297]", nil, "invalid array type: string[4294967297], the size can't be more than 4294967296"},
+		{"uint256[4294967296][16]", nil, "invalid array type: uint256[4294967296][16], it can't be encoded as more than 1099511627776 bytes"},
+		{"uint256[1048576][1048576]", nil, "invalid array type: uint256[1048576][1048576], it can't be encoded as more than 1099511627776 bytes"},
+		{"tuple", []ContractABIArgument{{Type: "uint256[4294967296][4]"}, {Type: "uint256[4294967296][4]"}, {Type: "uint256[4294967296][4]"}}, "invalid tuple type, it can't be encoded as more than 1099511627776 bytes"},
+		{"decimal", nil, "unknown type: decimal"},
+		{"", nil, "unknown type: "},
+		{"tuple", []ContractABIArgument{{Name: "a", Type: "uint"}, {Type: "bytes33"}}, "invalid tuple component 1: invalid fixed bytes type: bytes33, the size must be from 1 to 32"},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := ParseABIType(test.typeName, test.components)
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestNewABIStructureFromJSON_InvalidTypes(t *testing.T) {
+	testMatrix := []struct {
+		abi           string
+		expectedError string
+	}{
+		{
+			`[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint7"}],"outputs":[]}]`,
+			"invalid function transfer: input amount: invalid integer type: uint7, the size must be a multiple of 8 from 8 to 256",
+		},
+		{
+			`[{"type":"function","name":"get","inputs":[],"outputs":[{"name":"","type":"tuple","components":[{"name":"data","type":"bytes33"}]}]}]`,
+			"invalid function get: output 0: invalid tuple component data: invalid fixed bytes type: bytes33, the size must be from 1 to 32",
+		},
+		{
+			`[{"type":"event","name":"Transfer","inputs":[{"name":"values","type":"uint256[0]","indexed":false}]}]`,
+			"invalid event Transfer: input values: invalid array type: uint256[0], the size must be a positive integer",
+		},
+		{
+			`[{"type":"constructor","inputs":[{"name":"owner","type":"addr"}]}]`,
+			"invalid constructor: input owner: unknown type: addr",
+		},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := NewABIStructureFromJSON(test.abi)
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+
+	_, err := NewABIStructureEntryFromHumanReadable("function transfer(address to, uint7 amount)")
+	assert.EqualError(t, err, `invalid human-readable ABI entry "function transfer(address to, uint7 amount)": invalid function transfer: input amount: invalid integer type: uint7, the size must be a multiple of 8 from 8 to 256`)
+}
+
+func TestEncodeAllData_InvalidType(t *testing.T) {
+	args := []ContractABIArgument{{Name: "first", Type: "uint256[0]"}}
+
+	_, err := EncodeAllData(args, []interface{}{[]interface{}{}})
+	assert.EqualError(t, err, "invalid tuple component first: invalid array type: uint256[0], the size must be a positive integer")
+
+	_, err = ParseAllData(args, nil)
+	assert.EqualError(t, err, "abi: cannot decode data at offset 0: invalid tuple component first: invalid array type: uint256[0], the size must be a positive integer")
+	assert.False(t, args[0].IsDynamic())
+}
@@ -0,0 +1,371 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+var bigIntType = reflect.TypeOf(&big.Int{})
+
+/*
+UnpackAllData decodes a set of elements from the ABI, and stores the result in the value pointed to by into
+
+The destination is usually a pointer to a struct, where each argument is stored in the field that has a matching
+`abi:"name"` tag, or otherwise in the exported field whose name matches the argument name (ignoring case and underscores).
+If there is only a single argument, it can also be stored directly into a pointer to a matching type, so a single
+unnamed tuple is stored directly into a struct.
+
+ABI types are stored in the following Go types:
+- uint<x>/int<x>: any Go integer type that is large enough to hold the ABI type, or *big.Int
+- address: Address, [20]byte or []byte
+- bool: bool
+- bytes<x>: [x]byte or []byte
+- bytes: []byte
+- fixed<M>x<N>/ufixed<M>x<N>: string or *big.Rat
+- function: [24]byte or []byte
+- string: string
+- T[k]: [k]T or []T
+- T[]: []T
+- tuple: a struct, using the same rules as above for matching components to fields
+
+Storing into an interface{} gives the natural Go type for the ABI type, which is uint8...uint64 and int8...int64 for
+integers that fit, *big.Int for larger integers, [x]byte for bytes<x>, a decimal string for fixed point numbers,
+slices and arrays for ABI arrays, and []interface{} for tuples.
+
+An indexed event argument that is only known by its hash is stored as an IndexedHash, a Hash or a [32]byte.
+*/
+func UnpackAllData(inputs []ContractABIArgument, data []byte, into interface{}) error {
+	values, err := DecodeAllData(inputs, data)
+	if err != nil {
+		return err
+	}
+	return unpackValues(inputs, values.ToIndexedMap(), into, "")
+}
+
+func unpackValues(inputs []ContractABIArgument, values map[string]interface{}, into interface{}, path string) error {
+	dst := reflect.ValueOf(into)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("abi: cannot unpack into %T, a non-nil pointer is required", into)
+	}
+	dst = dst.Elem()
+
+	t, err := tupleType(inputs)
+	if err != nil {
+		return err
+	}
+
+	//a single value can be unpacked directly, unless the destination is a struct to hold it. A single unnamed tuple,
+	//such as a getter returning a struct, is unpacked into the struct itself rather than into its first field
+	if len(inputs) == 1 {
+		unnamedTuple := inputs[0].Name == "" && t.Components[0].Kind == TupleKind && dst.Kind() == reflect.Struct
+		if _, hasField := findField(dst, inputs[0].Name, 0); unnamedTuple || !hasField {
+			return assignABIValue(t.Components[0], values[argumentKey(inputs[0], 0)], dst, elementPath(path, inputs[0].Name, 0, false))
+		}
+	}
+
+	if dst.Kind() != reflect.Struct {
+		return fmt.Errorf("abi: cannot unpack %d values into Go value of type %s", len(inputs), dst.Type())
+	}
+	for i, input := range inputs {
+		field, ok := findField(dst, input.Name, i)
+		if !ok {
+			return fmt.Errorf("abi: no field in %s for %s", dst.Type(), elementPath(path, input.Name, i, false))
+		}
+		if err := assignABIValue(t.Components[i], values[argumentKey(input, i)], field, elementPath(path, input.Name, i, false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//findField finds the struct field for the named argument, by its `abi` tag or by its name.
+//An unnamed argument is matched by its position instead
+func findField(dst reflect.Value, name string, position int) (reflect.Value, bool) {
+	if dst.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	normalisedName := strings.Replace(name, "_", "", -1)
+	var byName reflect.Value
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if field.PkgPath != "" {
+			continue // unexported field
+		}
+		tag := field.Tag.Get("abi")
+		if tag == "-" {
+			continue
+		}
+		if tag != "" {
+			if tag == name {
+				return dst.Field(i), true
+			}
+			continue
+		}
+		if !byName.IsValid() && normalisedName != "" && strings.EqualFold(field.Name, normalisedName) {
+			byName = dst.Field(i)
+		}
+		if name == "" && i == position {
+			byName = dst.Field(i)
+		}
+	}
+	return byName, byName.IsValid()
+}
+
+//assignABIValue stores a value produced by the parser for the given type into the destination
+func assignABIValue(t *ABIType, value interface{}, dst reflect.Value, path string) error {
+	mismatch := func() error {
+		return fmt.Errorf("abi: cannot unmarshal %s into Go value of type %s (%s)", t.TypeName(), dst.Type(), path)
+	}
+	overflow := func() error {
+		return &DecodeError{Path: path, Msg: fmt.Sprintf("%s value %s overflows Go value of type %s", t.TypeName(), value, dst.Type())}
+	}
+
+	//only the hash is known for an indexed event argument that isn't a value type
+	if hashed, ok := value.(IndexedHash); ok {
+		switch {
+		case dst.Type() == reflect.TypeOf(hashed), dst.Kind() == reflect.Interface && dst.NumMethod() == 0:
+			dst.Set(reflect.ValueOf(hashed))
+		case dst.Type() == reflect.TypeOf(hashed.Hash):
+			dst.Set(reflect.ValueOf(hashed.Hash))
+		case dst.Kind() == reflect.Array && dst.Type().Elem().Kind() == reflect.Uint8 && dst.Len() == 32:
+			b, err := fromHex(string(hashed.Hash))
+			if err != nil {
+				return fmt.Errorf("abi: invalid hash of %s (%s): %s", t.TypeName(), path, err.Error())
+			}
+			reflect.Copy(dst, reflect.ValueOf(b))
+		default:
+			return fmt.Errorf("abi: cannot unmarshal hashed %s into Go value of type %s (%s)", t.TypeName(), dst.Type(), path)
+		}
+		return nil
+	}
+
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		converted := reflect.New(naturalType(t)).Elem()
+		if err := assignABIValue(t, value, converted, path); err != nil {
+			return err
+		}
+		dst.Set(converted)
+		return nil
+	}
+
+	//allocate any pointers, except for *big.Int which is handled directly as an integer
+	if dst.Kind() == reflect.Ptr && dst.Type() != bigIntType {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assignABIValue(t, value, dst.Elem(), path)
+	}
+
+	switch t.Kind {
+	//an array of either fixed or dynamic size
+	case ArrayKind, SliceKind:
+		elements, ok := value.([]interface{})
+		if !ok && value != nil {
+			return mismatch()
+		}
+
+		switch dst.Kind() {
+		case reflect.Slice:
+			dst.Set(reflect.MakeSlice(dst.Type(), len(elements), len(elements)))
+		case reflect.Array:
+			if dst.Len() != len(elements) {
+				return fmt.Errorf("abi: cannot unmarshal %s of %d elements into Go value of type %s (%s)", t.TypeName(), len(elements), dst.Type(), path)
+			}
+		default:
+			return mismatch()
+		}
+		for i, element := range elements {
+			if err := assignABIValue(t.Elem, element, dst.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	//a tuple is given as a map if it is dynamic, and a list in component order if it is static
+	case TupleKind:
+		values := make([]interface{}, len(t.Components))
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for i, name := range t.ComponentNames {
+				values[i] = v[argumentKey(ContractABIArgument{Name: name}, i)]
+			}
+		case []interface{}:
+			copy(values, v)
+		default:
+			return mismatch()
+		}
+
+		if dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Interface {
+			dst.Set(reflect.MakeSlice(dst.Type(), len(values), len(values)))
+			for i, comp := range t.Components {
+				if err := assignABIValue(comp, values[i], dst.Index(i), elementPath(path, t.ComponentNames[i], i, false)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if dst.Kind() != reflect.Struct {
+			return mismatch()
+		}
+		for i, comp := range t.Components {
+			name := t.ComponentNames[i]
+			field, ok := findField(dst, name, i)
+			if !ok {
+				return fmt.Errorf("abi: no field in %s for %s", dst.Type(), elementPath(path, name, i, false))
+			}
+			if err := assignABIValue(comp, values[i], field, elementPath(path, name, i, false)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case StringKind:
+		str, ok := value.(string)
+		if !ok || dst.Kind() != reflect.String {
+			return mismatch()
+		}
+		dst.SetString(str)
+		return nil
+
+	//bytes, bytes<x> and functions are given as either hex strings or []byte, see DecodeOptions,
+	//and addresses are always given as hex strings
+	case BytesKind, FixedBytesKind, FunctionKind, AddressKind:
+		var b []byte
+		switch v := value.(type) {
+		case []byte:
+			b = v
+		case string:
+			var err error
+			if b, err = fromHex(v); err != nil {
+				return fmt.Errorf("abi: invalid %s value (%s): %s", t.TypeName(), path, err.Error())
+			}
+		default:
+			return mismatch()
+		}
+
+		switch {
+		case t.Kind == AddressKind && dst.Type() == reflect.TypeOf(Address("")):
+			dst.Set(reflect.ValueOf(NewAddress(hex.EncodeToString(b))))
+		case dst.Kind() == reflect.Array && dst.Type().Elem().Kind() == reflect.Uint8 && dst.Len() == len(b):
+			reflect.Copy(dst, reflect.ValueOf(b))
+		case dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8:
+			dst.SetBytes(b)
+		default:
+			return mismatch()
+		}
+		return nil
+
+	case BoolKind:
+		b, ok := value.(bool)
+		if !ok || dst.Kind() != reflect.Bool {
+			return mismatch()
+		}
+		dst.SetBool(b)
+		return nil
+
+	//fixed point numbers are given as decimal strings
+	case FixedPointKind:
+		str, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		if dst.Kind() == reflect.String {
+			dst.SetString(str)
+			return nil
+		}
+		r, ok := new(big.Rat).SetString(str)
+		if !ok {
+			return fmt.Errorf("abi: invalid %s value (%s): %s", t.TypeName(), path, str)
+		}
+		switch dst.Type() {
+		case reflect.TypeOf(r):
+			dst.Set(reflect.ValueOf(r))
+		case reflect.TypeOf(r).Elem():
+			dst.Set(reflect.ValueOf(*r))
+		default:
+			return mismatch()
+		}
+		return nil
+	}
+
+	i, ok := value.(*big.Int)
+	if !ok {
+		return mismatch()
+	}
+
+	//the Go type must be able to hold every value of the ABI type
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if (t.Signed && t.Size > uint(dst.Type().Bits())) || (!t.Signed && t.Size >= uint(dst.Type().Bits())) {
+			return mismatch()
+		}
+		//the decoder doesn't check that the word holds a value in the range of the type, so it may still not fit
+		if !i.IsInt64() || dst.OverflowInt(i.Int64()) {
+			return overflow()
+		}
+		dst.SetInt(i.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t.Signed || t.Size > uint(dst.Type().Bits()) {
+			return mismatch()
+		}
+		if !i.IsUint64() || dst.OverflowUint(i.Uint64()) {
+			return overflow()
+		}
+		dst.SetUint(i.Uint64())
+	case reflect.Ptr:
+		dst.Set(reflect.ValueOf(new(big.Int).Set(i)))
+	case reflect.Struct:
+		if dst.Type() != bigIntType.Elem() {
+			return mismatch()
+		}
+		dst.Set(reflect.ValueOf(*new(big.Int).Set(i)))
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+//naturalType gives the Go type used for an ABI type when unpacking into an interface{}
+func naturalType(t *ABIType) reflect.Type {
+	switch t.Kind {
+	case ArrayKind:
+		return reflect.ArrayOf(int(t.Length), naturalType(t.Elem))
+	case SliceKind:
+		return reflect.SliceOf(naturalType(t.Elem))
+	case TupleKind:
+		return reflect.TypeOf([]interface{}{})
+	case StringKind, FixedPointKind:
+		return reflect.TypeOf("")
+	case BytesKind:
+		return reflect.TypeOf([]byte{})
+	case AddressKind:
+		return reflect.TypeOf(Address(""))
+	case FunctionKind:
+		return reflect.ArrayOf(24, reflect.TypeOf(byte(0)))
+	case BoolKind:
+		return reflect.TypeOf(false)
+	case FixedBytesKind:
+		return reflect.ArrayOf(int(t.Size), reflect.TypeOf(byte(0)))
+	}
+	return integerType(t.Size, t.Signed)
+}
+
+//integerType gives the smallest Go integer type that holds the given number of bits, or *big.Int if none are large enough
+func integerType(bits uint, signed bool) reflect.Type {
+	signedTypes := []reflect.Type{reflect.TypeOf(int8(0)), reflect.TypeOf(int16(0)), reflect.TypeOf(int32(0)), reflect.TypeOf(int64(0))}
+	unsignedTypes := []reflect.Type{reflect.TypeOf(uint8(0)), reflect.TypeOf(uint16(0)), reflect.TypeOf(uint32(0)), reflect.TypeOf(uint64(0))}
+
+	for i, size := range []uint{8, 16, 32, 64} {
+		if bits <= size {
+			if signed {
+				return signedTypes[i]
+			}
+			return unsignedTypes[i]
+		}
+	}
+	return bigIntType
+}
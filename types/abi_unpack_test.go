@@ -0,0 +1,253 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type custom struct {
+	First  string
+	Second [32]byte
+	Third  bool
+}
+
+func bytes32(hexValue string) [32]byte {
+	var b [32]byte
+	copy(b[:], hexToBytes(hexValue))
+	return b
+}
+
+func TestContractABIEvent_Unpack_Mixed(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "Mixed")
+
+	var mixed struct {
+		Custom custom `abi:"first"`
+		Second [32]byte
+		Third  int16
+		Fourth *custom
+		Fifth  string
+	}
+	err := ev.Unpack(topics, data, &mixed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, custom{"qwerty", bytes32("1234567890123456789012345678901234567890123456789012345678901234"), true}, mixed.Custom)
+	assert.Equal(t, bytes32("4386578974647808650460543048357430403897631067453064043476584731"), mixed.Second)
+	assert.Equal(t, int16(3455), mixed.Third)
+	assert.Equal(t, &custom{"asdfghjkl", bytes32("4013487654674538507684738547847680974039786439857345674358096798"), false}, mixed.Fourth)
+	assert.Equal(t, "string fifth", mixed.Fifth)
+}
+
+func TestContractABIEvent_Unpack_Integers(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "IntFixed")
+
+	var ints struct {
+		First  *big.Int
+		Second int16
+		Third  int64
+		Fourth big.Int
+	}
+	err := ev.Unpack(topics, data, &ints)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "-98765432109876543210", ints.First.String())
+	assert.Equal(t, int16(12345), ints.Second)
+	assert.Equal(t, int64(-43857439857398534), ints.Third)
+	assert.Equal(t, "12345678901234567890", ints.Fourth.String())
+
+	ev, topics, data = fixtureEvent(t, "UintFixed")
+
+	var uints struct {
+		First  *big.Int
+		Second uint32
+		Third  uint64
+		Fourth *big.Int
+	}
+	err = ev.Unpack(topics, data, &uints)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "98765432109876543210", uints.First.String())
+	assert.Equal(t, uint32(12345), uints.Second)
+	assert.Equal(t, uint64(43857439857398534), uints.Third)
+	assert.Equal(t, "12345678901234567890", uints.Fourth.String())
+}
+
+func TestContractABIEvent_Unpack_Arrays(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "ArrayFixedSize")
+
+	var fixed struct {
+		First  [10]*big.Int
+		Second []bool
+	}
+	err := ev.Unpack(topics, data, &fixed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "132", fixed.First[9].String())
+	assert.Equal(t, []bool{true, false, true, false, true, false}, fixed.Second)
+
+	ev, topics, data = fixtureEvent(t, "StructArray")
+
+	var structs struct {
+		First  [5]custom
+		Second []custom
+	}
+	err = ev.Unpack(topics, data, &structs)
+
+	assert.Nil(t, err)
+	assert.Equal(t, custom{}, structs.First[0])
+	assert.Equal(t, "qwerty", structs.First[4].First)
+	assert.Len(t, structs.Second, 2)
+	assert.True(t, structs.Second[1].Third)
+}
+
+func TestContractABIEvent_Unpack_StaticTuple(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "StaticTupleEventOne")
+
+	type staticTuple struct {
+		First  uint64
+		Second [1]byte
+		Third  bool
+	}
+	var tuples struct {
+		First  staticTuple
+		Second staticTuple
+	}
+	err := ev.Unpack(topics, data, &tuples)
+
+	assert.Nil(t, err)
+	assert.Equal(t, staticTuple{15423, [1]byte{0x08}, true}, tuples.First)
+	assert.Equal(t, staticTuple{193, [1]byte{0x26}, false}, tuples.Second)
+}
+
+func TestContractABIEvent_Unpack_Address(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "AddressFixed")
+
+	var addresses struct {
+		First  Address
+		Second [20]byte
+	}
+	err := ev.Unpack(topics, data, &addresses)
+
+	assert.Nil(t, err)
+	assert.Equal(t, NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"), addresses.First)
+	assert.Equal(t, "9d13c6d3afe1721beef56b55d303b09e021e27ab", hex.EncodeToString(addresses.Second[:]))
+}
+
+func TestContractABIEvent_Unpack_Interface(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "StaticTupleEventOne")
+
+	var tuples struct {
+		First  interface{}
+		Second interface{}
+	}
+	err := ev.Unpack(topics, data, &tuples)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{uint64(15423), [1]byte{0x08}, true}, tuples.First)
+}
+
+func TestContractABIFunction_Unpack_SingleValue(t *testing.T) {
+	function := ContractABIFunction{Name: "set", Inputs: []ContractABIArgument{{Name: "value", Type: "uint8"}}}
+	data, _ := EncodeAllData(function.Inputs, []interface{}{42})
+
+	var value uint16
+	err := function.Unpack(data, &value)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(42), value)
+}
+
+func TestContractABIFunction_UnpackOutput_SingleTuple(t *testing.T) {
+	function := ContractABIFunction{Name: "get", Outputs: []ContractABIArgument{
+		{Type: "tuple", Components: []ContractABIArgument{{Name: "a", Type: "uint64"}, {Name: "b", Type: "string"}}},
+	}}
+	data, err := EncodeAllData(function.Outputs, []interface{}{[]interface{}{uint64(7), "seven"}})
+	assert.Nil(t, err)
+
+	var order struct {
+		A uint64
+		B string
+	}
+	err = function.UnpackOutput(data, &order)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), order.A)
+	assert.Equal(t, "seven", order.B)
+}
+
+func TestUnpackAllData_Overflow(t *testing.T) {
+	testMatrix := []struct {
+		argType       string
+		word          string
+		into          interface{}
+		expectedError string
+	}{
+		{"uint8", word("1ff"), new(uint8), "abi: cannot decode value at offset 0: uint8 value 511 overflows Go value of type uint8"},
+		{"uint64", word("10000000000000000"), new(uint64), "abi: cannot decode value at offset 0: uint64 value 18446744073709551616 overflows Go value of type uint64"},
+		{"int8", word("80"), new(int8), "abi: cannot decode value at offset 0: int8 value 128 overflows Go value of type int8"},
+		{"int8", strings.Repeat("f", 62) + "7f", new(int8), "abi: cannot decode value at offset 0: int8 value -129 overflows Go value of type int8"},
+		{"int64", word("8000000000000000"), new(int64), "abi: cannot decode value at offset 0: int64 value 9223372036854775808 overflows Go value of type int64"},
+	}
+
+	for idx, test := range testMatrix {
+		args := []ContractABIArgument{{Name: "value", Type: test.argType}}
+
+		err := UnpackAllData(args, hexToBytes(test.word), test.into)
+
+		var decodeErr *DecodeError
+		assert.True(t, errors.As(err, &decodeErr), "Test index %d failed", idx)
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+
+	//values in range are still unpacked
+	var value int8
+	err := UnpackAllData([]ContractABIArgument{{Name: "value", Type: "int8"}}, hexToBytes(strings.Repeat("f", 62)+"80"), &value)
+	assert.Nil(t, err)
+	assert.Equal(t, int8(-128), value)
+}
+
+func TestContractABIEvent_Unpack_Errors(t *testing.T) {
+	ev, topics, data := fixtureEvent(t, "UintFixed")
+
+	var tooSmall struct {
+		First  uint64
+		Second uint16
+		Third  uint64
+		Fourth *big.Int
+	}
+	err := ev.Unpack(topics, data, &tooSmall)
+	assert.EqualError(t, err, "abi: cannot unmarshal uint256 into Go value of type uint64 (UintFixed.first)")
+
+	var signed struct {
+		First  *big.Int
+		Second int16
+		Third  uint64
+		Fourth *big.Int
+	}
+	err = ev.Unpack(topics, data, &signed)
+	assert.EqualError(t, err, "abi: cannot unmarshal uint16 into Go value of type int16 (UintFixed.second)")
+
+	var missing struct {
+		First *big.Int
+	}
+	err = ev.Unpack(topics, data, &missing)
+	assert.EqualError(t, err, "abi: no field in struct { First *big.Int } for UintFixed.second")
+
+	err = ev.Unpack(topics, data, missing)
+	assert.EqualError(t, err, "abi: cannot unpack into struct { First *big.Int }, a non-nil pointer is required")
+
+	ev, topics, data = fixtureEvent(t, "Mixed")
+	var wrongTuple struct {
+		First struct {
+			First  int
+			Second [32]byte
+			Third  bool
+		}
+	}
+	err = ev.Unpack(topics, data, &wrongTuple)
+	assert.EqualError(t, err, "abi: cannot unmarshal string into Go value of type int (Mixed.first.first)")
+}
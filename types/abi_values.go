@@ -0,0 +1,116 @@
+package types
+
+import (
+	"strings"
+)
+
+// DecodedValue is a single decoded ABI value, keeping the name and type of the argument it was decoded from.
+// Elementary types (and indexed event arguments that are only stored as a hash) have a Value, whilst arrays
+// and tuples have their elements or components as Children, in the order they were declared
+type DecodedValue struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Value    interface{}   `json:"value,omitempty"`
+	Children DecodedValues `json:"children,omitempty"`
+}
+
+// DecodedValues is an ordered list of decoded ABI values, such as the inputs of a function call
+type DecodedValues []*DecodedValue
+
+// ToMap converts the values to the map format given by ParseAllData, where each value is stored under its name.
+// Unnamed values all share the "" key, as they always have in ParseAllData, so only the last dynamic one is kept,
+// or the last static one if none are dynamic. Use ToIndexedMap to keep every value
+func (values DecodedValues) ToMap() map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	//static values are parsed from the heads before dynamic values are parsed from the tails
+	for _, dynamic := range []bool{false, true} {
+		for _, value := range values {
+			if value.isDynamic() == dynamic {
+				result[value.Name] = value.legacyValue(false)
+			}
+		}
+	}
+	return result
+}
+
+// ToIndexedMap converts the values to a map in the same way as ToMap, but stores unnamed values under their
+// position, e.g. "0", so that none of them are lost. The components of tuples are stored in the same way
+func (values DecodedValues) ToIndexedMap() map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		result[argumentKey(ContractABIArgument{Name: value.Name}, i)] = value.legacyValue(true)
+	}
+	return result
+}
+
+// Get gives the value with the given name, or nil if there isn't one
+func (values DecodedValues) Get(name string) *DecodedValue {
+	for _, value := range values {
+		if value.Name == name {
+			return value
+		}
+	}
+	return nil
+}
+
+/*
+LegacyValue gives the value in the format used by ParseAllData:
+
+  - elementary types are given as their Value
+  - arrays are given as a []interface{} of their elements, which is nil for an empty dynamic array
+  - tuples with a dynamic component are given as a map[string]interface{}, keyed the same way as ToMap
+  - static tuples are given as a []interface{} of their components, in order
+*/
+func (value *DecodedValue) LegacyValue() interface{} {
+	return value.legacyValue(false)
+}
+
+//legacyValue gives the value in the format used by ParseAllData, with tuples keyed as ToIndexedMap if indexed is set
+func (value *DecodedValue) legacyValue(indexed bool) interface{} {
+	if _, isHash := value.Value.(IndexedHash); isHash {
+		return value.Value
+	}
+
+	if strings.HasSuffix(value.Type, "]") {
+		var elements []interface{}
+		if !value.isDynamic() {
+			elements = make([]interface{}, 0, len(value.Children))
+		}
+		for _, child := range value.Children {
+			elements = append(elements, child.legacyValue(indexed))
+		}
+		return elements
+	}
+
+	if value.Type == "tuple" {
+		if value.isDynamic() {
+			if indexed {
+				return value.Children.ToIndexedMap()
+			}
+			return value.Children.ToMap()
+		}
+		components := make([]interface{}, 0, len(value.Children))
+		for _, child := range value.Children {
+			components = append(components, child.legacyValue(indexed))
+		}
+		return components
+	}
+
+	return value.Value
+}
+
+//isDynamic reports whether the value was decoded from a dynamic type, in the same way as ContractABIArgument.IsDynamic
+//but working from the decoded children, since the components of a tuple are not kept
+func (value *DecodedValue) isDynamic() bool {
+	switch {
+	case value.Type == "string" || value.Type == "bytes" || strings.HasSuffix(value.Type, "[]"):
+		return true
+	case value.Type == "tuple" || strings.HasSuffix(value.Type, "]"):
+		for _, child := range value.Children {
+			if child.isDynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
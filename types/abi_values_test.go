@@ -0,0 +1,130 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAllData_KeepsOrder(t *testing.T) {
+	args := []ContractABIArgument{
+		{Name: "z", Type: "uint256"},
+		{Name: "", Type: "string"},
+		{Name: "a", Type: "tuple", Components: []ContractABIArgument{{Name: "y", Type: "bool"}, {Name: "b", Type: "address"}}},
+		{Name: "m", Type: "uint8[]"},
+	}
+	data := hexToBytes(word("1") + word("a0") + word("1") + word("1932c48b2bf8102ba33b4a6b545c32236e342f34") + word("e0") +
+		word("2") + "6f6b" + word("")[4:] + word("2") + word("7") + word("8"))
+
+	values, err := DecodeAllData(args, data)
+	assert.Nil(t, err)
+
+	assert.Equal(t, DecodedValues{
+		{Name: "z", Type: "uint256", Value: big.NewInt(1)},
+		{Name: "", Type: "string", Value: "ok"},
+		{Name: "a", Type: "tuple", Children: DecodedValues{
+			{Name: "y", Type: "bool", Value: true},
+			{Name: "b", Type: "address", Value: "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		}},
+		{Name: "m", Type: "uint8[]", Children: DecodedValues{
+			{Name: "", Type: "uint8", Value: big.NewInt(7)},
+			{Name: "", Type: "uint8", Value: big.NewInt(8)},
+		}},
+	}, values)
+	assert.Equal(t, "y", values.Get("a").Children[0].Name)
+	assert.Nil(t, values.Get("missing"))
+
+	asJSON, err := json.Marshal(values)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"name":"z","type":"uint256","value":1},{"name":"","type":"string","value":"ok"},`+
+		`{"name":"a","type":"tuple","children":[{"name":"y","type":"bool","value":true},{"name":"b","type":"address","value":"0x1932c48b2bf8102ba33b4a6b545c32236e342f34"}]},`+
+		`{"name":"m","type":"uint8[]","children":[{"name":"","type":"uint8","value":7},{"name":"","type":"uint8","value":8}]}]`, string(asJSON))
+
+	assert.Equal(t, map[string]interface{}{
+		"z": big.NewInt(1),
+		"":  "ok",
+		"a": []interface{}{true, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		"m": []interface{}{big.NewInt(7), big.NewInt(8)},
+	}, values.ToMap())
+	assert.Equal(t, map[string]interface{}{
+		"z": big.NewInt(1),
+		"1": "ok",
+		"a": []interface{}{true, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		"m": []interface{}{big.NewInt(7), big.NewInt(8)},
+	}, values.ToIndexedMap())
+}
+
+func TestDecodedValues_ToMap_MatchesParse(t *testing.T) {
+	abi, events := fixtureEvents(t)
+
+	for _, c := range events {
+		for _, ev := range abi.Events {
+			if "0x"+ev.Signature() != c.Topics[0].String() {
+				continue
+			}
+
+			parsed, err := ev.Parse(c.Topics, c.Data.AsBytes())
+			assert.Nil(t, err, "event %s failed", ev.Name)
+			decoded, err := ev.Decode(c.Topics, c.Data.AsBytes())
+			assert.Nil(t, err, "event %s failed", ev.Name)
+
+			assert.Equal(t, parsed, decoded.ToMap(), "event %s failed", ev.Name)
+			for i, arg := range ev.Inputs {
+				assert.Equal(t, arg.Name, decoded[i].Name, "event %s failed", ev.Name)
+				assert.Equal(t, arg.Type, decoded[i].Type, "event %s failed", ev.Name)
+			}
+		}
+	}
+}
+
+func TestContractABIEvent_Decode_IndexedOrder(t *testing.T) {
+	event := ContractABIEvent{
+		Type: "event",
+		Name: "Log",
+		Inputs: []ContractABIEventArgument{
+			{ContractABIArgument{Name: "", Type: "uint256"}, false},
+			{ContractABIArgument{Name: "topic", Type: "string"}, true},
+			{ContractABIArgument{Name: "", Type: "bool"}, false},
+		},
+	}
+	topics := []Hash{NewHash(event.Signature()), NewHash(word("abc"))}
+
+	decoded, err := event.Decode(topics, hexToBytes(word("3")+word("1")))
+	assert.Nil(t, err)
+
+	assert.Equal(t, DecodedValues{
+		{Name: "", Type: "uint256", Value: big.NewInt(3)},
+		{Name: "topic", Type: "string", Value: IndexedHash{Type: "string", Hash: topics[1]}},
+		{Name: "", Type: "bool", Value: true},
+	}, decoded)
+	assert.Equal(t, map[string]interface{}{
+		"0":     big.NewInt(3),
+		"topic": IndexedHash{Type: "string", Hash: topics[1]},
+		"2":     true,
+	}, decoded.ToIndexedMap())
+}
+
+func TestDecodedValue_LegacyValue_EmptyArrays(t *testing.T) {
+	assert.Nil(t, (&DecodedValue{Type: "uint256[]"}).LegacyValue())
+	assert.Equal(t, []interface{}{}, (&DecodedValue{Type: "uint256[0]"}).LegacyValue())
+	assert.Equal(t, map[string]interface{}{"": "a"}, (&DecodedValue{Type: "tuple", Children: DecodedValues{{Type: "string", Value: "a"}}}).LegacyValue())
+}
+
+func TestDecodedValues_ToMap_Unnamed(t *testing.T) {
+	//ParseAllData has always stored unnamed arguments under "", with dynamic values parsed after static ones
+	args := []ContractABIArgument{{Type: "string"}, {Type: "uint256"}, {Type: "bool"}}
+	data, err := EncodeAllData(args, []interface{}{"first", 2, true})
+	assert.Nil(t, err)
+
+	values, err := DecodeAllData(args, data)
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{"": "first"}, values.ToMap())
+	assert.Equal(t, map[string]interface{}{"0": "first", "1": big.NewInt(2), "2": true}, values.ToIndexedMap())
+
+	parsed, err := ParseAllData(args, data)
+	assert.Nil(t, err)
+	assert.Equal(t, values.ToMap(), parsed)
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+/*
+Checksum gives the address in mixed-case hex, with a "0x" prefix, where the case of each letter is a checksum of the
+address as given by EIP-55, e.g. "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed".
+
+If a chain id is given, the checksum also depends on the chain as given by EIP-1191, so that an address checksummed
+for one network is rejected by another. A nil chain id gives the EIP-55 checksum.
+*/
+func (addr *Address) Checksum(chainID *big.Int) string {
+	lower := strings.ToLower(string(*addr))
+
+	hashInput := lower
+	if chainID != nil {
+		hashInput = chainID.String() + "0x" + lower
+	}
+	hashed := hash(hashInput)
+
+	checksummed := []byte(lower)
+	for i, c := range checksummed {
+		nibble := hashed[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hashed[i/2] & 0xf
+		}
+		if c >= 'a' && c <= 'f' && nibble >= 8 {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(checksummed)
+}
+
+// ParseChecksumAddress parses an address in the same way as ParseAddress, and if it is in mixed case also checks that
+// it has the EIP-1191 checksum for the chain id, or the EIP-55 checksum if the chain id is nil
+func ParseChecksumAddress(hexString string, chainID *big.Int) (Address, error) {
+	addr, err := ParseAddress(hexString)
+	if err != nil {
+		return "", err
+	}
+
+	digits := hexString
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits = digits[2:]
+	}
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return addr, nil
+	}
+	if expected := addr.Checksum(chainID); expected[2:] != digits {
+		return "", fmt.Errorf("invalid address %q: checksum does not match, expected %s", hexString, expected)
+	}
+	return addr, nil
+}
+
+/*
+ChecksumAddress is an address kept exactly as it was written, such as in a config file, so that mis-typed addresses
+are rejected by checking their checksum.
+
+Unmarshalling it from JSON or TOML checks that it is a 20 byte address, and that a mixed-case address has its EIP-55
+checksum, so that typos are caught when the config is read. The chain isn't known then, so an address with an
+EIP-1191 checksum can only be given with NewChecksumAddress. Addresses that are all lowercase or all uppercase have no
+checksum, so are always accepted.
+*/
+type ChecksumAddress string
+
+// NewChecksumAddress checks the address in the same way as ParseChecksumAddress, so that a mixed-case address must
+// have the EIP-1191 checksum for the chain id, or the EIP-55 checksum if the chain id is nil
+func NewChecksumAddress(hexString string, chainID *big.Int) (ChecksumAddress, error) {
+	if _, err := ParseChecksumAddress(hexString, chainID); err != nil {
+		return "", err
+	}
+	return ChecksumAddress(hexString), nil
+}
+
+// Parse gives the address, checking the checksum of a mixed-case address, see ParseChecksumAddress
+func (addr ChecksumAddress) Parse(chainID *big.Int) (Address, error) {
+	return ParseChecksumAddress(string(addr), chainID)
+}
+
+func (addr *ChecksumAddress) UnmarshalText(input []byte) error {
+	checked, err := NewChecksumAddress(string(input), nil)
+	if err != nil {
+		return err
+	}
+	*addr = checked
+	return nil
+}
+
+func (addr *ChecksumAddress) UnmarshalJSON(input []byte) error {
+	unwrapped, err := unmarshalHexString(input)
+	if err != nil || unwrapped == nil {
+		return err
+	}
+	return addr.UnmarshalText([]byte(*unwrapped))
+}
+
+func (addr *ChecksumAddress) UnmarshalTOML(input []byte) error {
+	return addr.UnmarshalJSON(input)
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/naoina/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress_Checksum(t *testing.T) {
+	//the test vectors of EIP-55 and EIP-1191
+	testMatrix := []struct {
+		chainID  *big.Int
+		expected []string
+	}{
+		{nil, []string{
+			"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+			"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+			"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		}},
+		{big.NewInt(30), []string{
+			"0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD",
+			"0xFb6916095cA1Df60bb79ce92cE3EA74c37c5d359",
+			"0xDBF03B407c01E7CD3cBea99509D93F8Dddc8C6FB",
+			"0xD1220A0Cf47c7B9BE7a2e6ba89F429762E7B9adB",
+		}},
+		{big.NewInt(31), []string{
+			"0x5aAeb6053F3e94c9b9A09F33669435E7EF1BEaEd",
+			"0xFb6916095CA1dF60bb79CE92ce3Ea74C37c5D359",
+			"0xdbF03B407C01E7cd3cbEa99509D93f8dDDc8C6fB",
+			"0xd1220a0CF47c7B9Be7A2E6Ba89f429762E7b9adB",
+		}},
+	}
+
+	for idx, test := range testMatrix {
+		for _, expected := range test.expected {
+			addr := NewAddress(strings.ToLower(expected))
+
+			assert.Equal(t, expected, addr.Checksum(test.chainID), "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestParseChecksumAddress(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		chainID       *big.Int
+		expectedError string
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil, ""},
+		{"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil, ""},
+		{"0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD", big.NewInt(30), ""},
+		//addresses that aren't mixed-case have no checksum
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", nil, ""},
+		{"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", big.NewInt(30), ""},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", nil, `invalid address "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD": checksum does not match, expected 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed`},
+		//the EIP-55 checksum isn't valid for a chain id
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", big.NewInt(30), `invalid address "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed": checksum does not match, expected 0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD`},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", nil, `invalid address "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA": expected 20 bytes but got 19`},
+	}
+
+	for idx, test := range testMatrix {
+		addr, err := ParseChecksumAddress(test.input, test.chainID)
+
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+			assert.Equal(t, Address("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), addr, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	type TestConfig struct {
+		Addr ChecksumAddress `toml:"address"`
+	}
+	mistyped := `address = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"`
+
+	//a mixed-case address is checked when the config is read
+	var config TestConfig
+	err := toml.NewDecoder(strings.NewReader(mistyped)).Decode(&config)
+	assert.EqualError(t, err, `line 1: (types.TestConfig.Addr) invalid address "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD": checksum does not match, expected 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed`)
+
+	err = toml.NewDecoder(strings.NewReader(`address = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA"`)).Decode(&config)
+	assert.EqualError(t, err, `line 1: (types.TestConfig.Addr) invalid address "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA": expected 20 bytes but got 19`)
+
+	err = toml.NewDecoder(strings.NewReader(`address = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"`)).Decode(&config)
+	assert.Nil(t, err)
+	assert.Equal(t, ChecksumAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), config.Addr)
+
+	var addr ChecksumAddress
+	err = json.Unmarshal([]byte(`"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"`), &addr)
+	assert.Nil(t, err)
+	parsed, err := addr.Parse(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Address("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), parsed)
+
+	//the EIP-55 checksum isn't valid for a chain id
+	_, err = addr.Parse(big.NewInt(30))
+	assert.EqualError(t, err, `invalid address "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed": checksum does not match, expected 0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD`)
+
+	//an EIP-1191 checksum can only be given with the chain id
+	err = json.Unmarshal([]byte(`"0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD"`), &addr)
+	assert.EqualError(t, err, `invalid address "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD": checksum does not match, expected 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed`)
+	addr, err = NewChecksumAddress("0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD", big.NewInt(30))
+	assert.Nil(t, err)
+	parsed, err = addr.Parse(big.NewInt(30))
+	assert.Nil(t, err)
+	assert.Equal(t, Address("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"), parsed)
+
+	//a plain Address doesn't check the checksum
+	var plain Address
+	err = json.Unmarshal([]byte(`"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"`), &plain)
+	assert.Nil(t, err)
+}
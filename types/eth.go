@@ -1,9 +1,13 @@
 package types
 
 import (
+	"database/sql/driver"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -23,21 +27,40 @@ func NewAddress(hexString string) Address {
 	return Address(fmt.Sprintf("%040v", hexString))
 }
 
+// ParseAddress parses a hex address, with or without a "0x" prefix, which must be exactly 20 bytes. Unlike NewAddress,
+// input that isn't an address is rejected rather than padded or truncated. The checksum of a mixed-case address isn't
+// checked, see ParseChecksumAddress
+func ParseAddress(hexString string) (Address, error) {
+	bytes, err := parseHexBytes(hexString)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %s", hexString, err.Error())
+	}
+	if len(bytes) != 20 {
+		return "", fmt.Errorf("invalid address %q: expected 20 bytes but got %d", hexString, len(bytes))
+	}
+	return Address(hex.EncodeToString(bytes)), nil
+}
+
+// MustParseAddress is ParseAddress for addresses that are known to be valid, such as constants in tests,
+// and panics if the address is invalid
+func MustParseAddress(hexString string) Address {
+	addr, err := ParseAddress(hexString)
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
 func (addr Address) MarshalJSON() ([]byte, error) {
 	return json.Marshal(addr.String())
 }
 
 func (addr *Address) UnmarshalJSON(input []byte) error {
-	var unwrapped string
-	if err := json.Unmarshal(input, &unwrapped); err != nil {
+	unwrapped, err := unmarshalHexString(input)
+	if err != nil || unwrapped == nil {
 		return err
 	}
-	bytes, err := fromHex(unwrapped)
-	if err != nil {
-		return err
-	}
-	*addr = NewAddress(hex.EncodeToString(bytes))
-	return nil
+	return addr.UnmarshalText([]byte(*unwrapped))
 }
 
 func (addr *Address) UnmarshalTOML(input []byte) error {
@@ -56,6 +79,87 @@ func (addr *Address) IsEmpty() bool {
 	return *addr == "" || *addr == "0000000000000000000000000000000000000000"
 }
 
+func (addr Address) MarshalText() ([]byte, error) {
+	return []byte(addr.String()), nil
+}
+
+// UnmarshalText parses the address with ParseAddress, except that "" and "0x", which an empty address is marshalled
+// as, give an empty address
+func (addr *Address) UnmarshalText(input []byte) error {
+	if isEmptyHex(string(input)) {
+		*addr = ""
+		return nil
+	}
+	parsed, err := ParseAddress(string(input))
+	if err != nil {
+		return err
+	}
+	*addr = parsed
+	return nil
+}
+
+// Scan reads an address from a database column, which can hold the hex of the address, with or without a "0x"
+// prefix, or its 20 bytes. NULL gives an empty address
+func (addr *Address) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*addr = ""
+		return nil
+	case string:
+		return addr.UnmarshalText([]byte(value))
+	case []byte:
+		if len(value) == 20 {
+			*addr = Address(hex.EncodeToString(value))
+			return nil
+		}
+		return addr.UnmarshalText(value)
+	}
+	return fmt.Errorf("cannot scan %T into an address", src)
+}
+
+// Value gives the address to store in a database column as "0x" prefixed hex, or NULL if it is empty
+func (addr Address) Value() (driver.Value, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	return addr.String(), nil
+}
+
+// Format formats the address for fmt. %s and %v give the "0x" prefixed hex, and %q the same in quotes, whilst %x and
+// %X give the hex without the prefix, unless the '#' flag is given. Widths are honoured, padding with spaces
+func (addr Address) Format(s fmt.State, verb rune) {
+	var text string
+	switch verb {
+	case 's', 'v':
+		text = addr.String()
+	case 'q':
+		text = strconv.Quote(addr.String())
+	case 'x':
+		text = string(addr)
+		if s.Flag('#') {
+			text = "0x" + text
+		}
+	case 'X':
+		text = strings.ToUpper(string(addr))
+		if s.Flag('#') {
+			text = "0X" + text
+		}
+	default:
+		fmt.Fprintf(s, "%%!%c(address=%s)", verb, addr.String())
+		return
+	}
+
+	if width, ok := s.Width(); ok && width > len(text) {
+		padding := strings.Repeat(" ", width-len(text))
+		if s.Flag('-') {
+			text += padding
+		} else {
+			text = padding + text
+		}
+	}
+	io.WriteString(s, text)
+}
+
 type Hash string
 
 // NewHashFromHex creates a new hash from a given hex string
@@ -71,20 +175,48 @@ func NewHash(hexString string) Hash {
 	return Hash(fmt.Sprintf("%064v", hexString))
 }
 
+// ParseHash parses a hex hash, with or without a "0x" prefix, which must be exactly 32 bytes. Unlike NewHash,
+// input that isn't a hash is rejected rather than padded or truncated
+func ParseHash(hexString string) (Hash, error) {
+	bytes, err := parseHexBytes(hexString)
+	if err != nil {
+		return "", fmt.Errorf("invalid hash %q: %s", hexString, err.Error())
+	}
+	if len(bytes) != 32 {
+		return "", fmt.Errorf("invalid hash %q: expected 32 bytes but got %d", hexString, len(bytes))
+	}
+	return Hash(hex.EncodeToString(bytes)), nil
+}
+
+// MustParseHash is ParseHash for hashes that are known to be valid, such as constants in tests,
+// and panics if the hash is invalid
+func MustParseHash(hexString string) Hash {
+	hsh, err := ParseHash(hexString)
+	if err != nil {
+		panic(err)
+	}
+	return hsh
+}
+
 func (hsh Hash) MarshalJSON() ([]byte, error) {
 	return json.Marshal(hsh.String())
 }
 
 func (hsh *Hash) UnmarshalJSON(input []byte) error {
-	var unwrapped string
-	if err := json.Unmarshal(input, &unwrapped); err != nil {
+	unwrapped, err := unmarshalHexString(input)
+	if err != nil || unwrapped == nil {
 		return err
 	}
-	bytes, err := fromHex(unwrapped)
+	//an empty hash is marshalled as "0x"
+	if isEmptyHex(*unwrapped) {
+		*hsh = ""
+		return nil
+	}
+	parsed, err := ParseHash(*unwrapped)
 	if err != nil {
 		return err
 	}
-	*hsh = NewHash(hex.EncodeToString(bytes))
+	*hsh = parsed
 	return nil
 }
 
@@ -111,6 +243,38 @@ func fromHex(hexString string) ([]byte, error) {
 	return hex.DecodeString(hexString)
 }
 
+//parseHexBytes decodes hex with an optional "0x" prefix, giving an error that says what is wrong with it
+func parseHexBytes(hexString string) ([]byte, error) {
+	if strings.HasPrefix(hexString, "0x") || strings.HasPrefix(hexString, "0X") {
+		hexString = hexString[2:]
+	}
+	if len(hexString)%2 != 0 {
+		return nil, errors.New("odd number of hex digits")
+	}
+	bytes, err := hex.DecodeString(hexString)
+	if invalid, ok := err.(hex.InvalidByteError); ok {
+		return nil, fmt.Errorf("invalid hex digit %q", rune(invalid))
+	}
+	return bytes, err
+}
+
+//isEmptyHex reports whether the hex has no digits, which is how empty addresses and hashes are marshalled
+func isEmptyHex(hexString string) bool {
+	return hexString == "" || hexString == "0x" || hexString == "0X"
+}
+
+//unmarshalHexString gives the string of a JSON or TOML string, or nil if it is null, so that it is left unchanged
+func unmarshalHexString(input []byte) (*string, error) {
+	if string(input) == "null" {
+		return nil, nil
+	}
+	var unwrapped string
+	if err := json.Unmarshal(input, &unwrapped); err != nil {
+		return nil, err
+	}
+	return &unwrapped, nil
+}
+
 type RawAccountState struct {
 	Root    Hash              `json:"root"`
 	Storage map[string]string `json:"storage,omitempty"`
@@ -130,23 +294,47 @@ func NewHexData(input string) HexData {
 	return HexData(input)
 }
 
+// ParseHexData parses hex, with or without a "0x" prefix, which must have an even number of digits.
+// Unlike NewHexData, input that isn't hex is rejected
+func ParseHexData(input string) (HexData, error) {
+	bytes, err := parseHexBytes(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid hex data %q: %s", input, err.Error())
+	}
+	return HexData(hex.EncodeToString(bytes)), nil
+}
+
+// MustParseHexData is ParseHexData for data that is known to be valid, such as constants in tests,
+// and panics if the data is invalid
+func MustParseHexData(input string) HexData {
+	data, err := ParseHexData(input)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
 func (data HexData) MarshalJSON() ([]byte, error) {
 	return json.Marshal(data.String())
 }
 
 func (data *HexData) UnmarshalJSON(input []byte) error {
-	var unwrapped string
-	if err := json.Unmarshal(input, &unwrapped); err != nil {
+	unwrapped, err := unmarshalHexString(input)
+	if err != nil || unwrapped == nil {
 		return err
 	}
-	hexBytes, err := fromHex(unwrapped)
+	parsed, err := ParseHexData(*unwrapped) //Removes the leading "0x" if there
 	if err != nil {
 		return err
 	}
-	*data = HexData(hex.EncodeToString(hexBytes)) //Removes the leading "0x" if there
+	*data = parsed
 	return nil
 }
 
+func (data *HexData) UnmarshalTOML(input []byte) error {
+	return data.UnmarshalJSON(input)
+}
+
 func (data *HexData) String() string {
 	return "0x" + string(*data)
 }
@@ -160,12 +348,21 @@ func (data *HexData) IsEmpty() bool {
 	return len(data.AsBytes()) == 0
 }
 
-// Call args for checking a contract for EIP165 interfaces
-type EIP165Call struct {
+// CallMsg is the message of an eth_call, which calls a contract without sending a transaction. From is left out
+// if it is empty
+type CallMsg struct {
+	From Address `json:"from,omitempty"`
 	To   Address `json:"to"`
 	Data HexData `json:"data"`
 }
 
+// EIP165Call is the message used by the client helpers to check a contract for EIP165 interfaces and call its
+// functions. It has the same fields as CallMsg, but is a distinct type so that it keeps its name when formatted,
+// e.g. in the keys of StubQuorumClient
+type EIP165Call CallMsg
+
+// HexNumber is a 64 bit JSON-RPC quantity, which is marshalled as hex, e.g. "0x1f", and unmarshalled from hex or
+// decimal, as a string or a JSON number
 type HexNumber uint64
 
 func (num HexNumber) MarshalJSON() ([]byte, error) {
@@ -173,18 +370,120 @@ func (num HexNumber) MarshalJSON() ([]byte, error) {
 }
 
 func (num *HexNumber) UnmarshalJSON(input []byte) error {
-	var unwrapped string
-	if err := json.Unmarshal(input, &unwrapped); err != nil {
-		return err
-	}
-	out, err := strconv.ParseUint(unwrapped, 0, 64)
+	out, err := unmarshalQuantity(input)
 	if err != nil {
 		return err
 	}
-	*num = HexNumber(out)
+	if !out.IsUint64() {
+		return errors.New("quantity " + out.String() + " does not fit in 64 bits")
+	}
+	*num = HexNumber(out.Uint64())
 	return nil
 }
 
 func (num *HexNumber) ToUint64() uint64 {
 	return uint64(*num)
 }
+
+/*
+HexBig is a JSON-RPC quantity of any size up to 256 bits, such as an amount of wei, which would overflow a HexNumber.
+
+It is marshalled as hex, e.g. "0x1bc16d674ec80000", both in JSON and as text, which is also used for TOML, whilst
+String gives it in decimal. It can be unmarshalled from hex or decimal, as a string or a JSON or TOML number.
+*/
+type HexBig big.Int
+
+// NewHexBig copies the integer into a HexBig, so that changes to one don't change the other
+func NewHexBig(i *big.Int) HexBig {
+	var out big.Int
+	//zero is always the zero value, so that it is equal to an unset HexBig
+	if i != nil && i.Sign() != 0 {
+		out.Set(i)
+	}
+	return HexBig(out)
+}
+
+func (num HexBig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(num.Hex())
+}
+
+func (num *HexBig) UnmarshalJSON(input []byte) error {
+	out, err := unmarshalQuantity(input)
+	if err != nil {
+		return err
+	}
+	*num = NewHexBig(out)
+	return nil
+}
+
+func (num HexBig) MarshalText() ([]byte, error) {
+	return []byte(num.Hex()), nil
+}
+
+func (num *HexBig) UnmarshalText(input []byte) error {
+	out, err := parseQuantity(string(input))
+	if err != nil {
+		return err
+	}
+	*num = NewHexBig(out)
+	return nil
+}
+
+// ToInt gives a copy of the integer
+func (num HexBig) ToInt() *big.Int {
+	return new(big.Int).Set((*big.Int)(&num))
+}
+
+// String gives the integer in decimal. It has a value receiver, like the marshalling methods, so that a HexBig field
+// is a fmt.Stringer
+func (num HexBig) String() string {
+	return (*big.Int)(&num).String()
+}
+
+// Hex gives the integer as a "0x" prefixed hex quantity
+func (num HexBig) Hex() string {
+	i := (*big.Int)(&num)
+	if i.Sign() < 0 {
+		return "-0x" + new(big.Int).Neg(i).Text(16)
+	}
+	return "0x" + i.Text(16)
+}
+
+//unmarshalQuantity parses a quantity given as a JSON string or number
+func unmarshalQuantity(input []byte) (*big.Int, error) {
+	if len(input) > 0 && input[0] == '"' {
+		var unwrapped string
+		if err := json.Unmarshal(input, &unwrapped); err != nil {
+			return nil, err
+		}
+		return parseQuantity(unwrapped)
+	}
+	return parseQuantity(string(input))
+}
+
+//parseQuantity parses a hex quantity, e.g. "0x1f", or a decimal, e.g. "31". As in the JSON-RPC spec for quantities,
+//there must be at least one digit and no leading zeros, so zero is "0x0" or "0"
+func parseQuantity(input string) (*big.Int, error) {
+	digits, base := input, 10
+	if strings.HasPrefix(input, "0x") || strings.HasPrefix(input, "0X") {
+		digits, base = input[2:], 16
+	}
+
+	if digits == "" {
+		return nil, fmt.Errorf("invalid quantity %q: no digits", input)
+	}
+	if digits[0] == '+' || digits[0] == '-' {
+		return nil, fmt.Errorf("invalid quantity %q: quantities have no sign", input)
+	}
+	if len(digits) > 1 && digits[0] == '0' {
+		return nil, fmt.Errorf("invalid quantity %q: leading zeros are not allowed", input)
+	}
+	out, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity %q", input)
+	}
+	if out.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid quantity %q: larger than 256 bits", input)
+	}
+	return out, nil
+}
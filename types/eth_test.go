@@ -1,7 +1,11 @@
 package types
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 
@@ -121,3 +125,424 @@ func TestHexNumber_UnmarshalJSON(t *testing.T) {
 	assert.Nil(t, err)
 	assert.EqualValues(t, 16, num)
 }
+
+func TestHexNumber_UnmarshalJSONFormats(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		expected      uint64
+		expectedError string
+	}{
+		{`"0x0"`, 0, ""},
+		{`"0"`, 0, ""},
+		{`"16"`, 16, ""},
+		{`16`, 16, ""},
+		{`"0XFF"`, 255, ""},
+		{`"0xffffffffffffffff"`, 18446744073709551615, ""},
+		{`"0x010"`, 0, `invalid quantity "0x010": leading zeros are not allowed`},
+		{`"010"`, 0, `invalid quantity "010": leading zeros are not allowed`},
+		{`"0x00"`, 0, `invalid quantity "0x00": leading zeros are not allowed`},
+		{`"0x"`, 0, `invalid quantity "0x": no digits`},
+		{`""`, 0, `invalid quantity "": no digits`},
+		{`"-1"`, 0, `invalid quantity "-1": quantities have no sign`},
+		{`"0x1g"`, 0, `invalid quantity "0x1g"`},
+		{`"1_000"`, 0, `invalid quantity "1_000"`},
+		{`1.5`, 0, `invalid quantity "1.5"`},
+		{`"0x10000000000000000"`, 0, "quantity 18446744073709551616 does not fit in 64 bits"},
+	}
+
+	for idx, test := range testMatrix {
+		var num HexNumber
+		err := json.Unmarshal([]byte(test.input), &num)
+
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+			assert.EqualValues(t, test.expected, num, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestHexBig_MarshalJSON(t *testing.T) {
+	wei, _ := new(big.Int).SetString("100000000000000000000", 10)
+
+	result, err := json.Marshal(map[string]HexBig{"value": NewHexBig(wei), "zero": {}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"value":"0x56bc75e2d63100000","zero":"0x0"}`, string(result))
+}
+
+func TestHexBig_UnmarshalJSON(t *testing.T) {
+	wei, _ := new(big.Int).SetString("100000000000000000000", 10)
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	testMatrix := []struct {
+		input         string
+		expected      *big.Int
+		expectedError string
+	}{
+		{`"0x56bc75e2d63100000"`, wei, ""},
+		{`"100000000000000000000"`, wei, ""},
+		{`100000000000000000000`, wei, ""},
+		{`"0x0"`, big.NewInt(0), ""},
+		{`0`, big.NewInt(0), ""},
+		{`"0x` + maxUint256.Text(16) + `"`, maxUint256, ""},
+		{`"0x1` + maxUint256.Text(16) + `"`, nil, `invalid quantity "0x1` + maxUint256.Text(16) + `": larger than 256 bits`},
+		{`"0x056bc75e2d63100000"`, nil, `invalid quantity "0x056bc75e2d63100000": leading zeros are not allowed`},
+		{`"-0x1"`, nil, `invalid quantity "-0x1": quantities have no sign`},
+		{`1e20`, nil, `invalid quantity "1e20"`},
+		{`true`, nil, `invalid quantity "true"`},
+	}
+
+	for idx, test := range testMatrix {
+		var num HexBig
+		err := json.Unmarshal([]byte(test.input), &num)
+
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+			assert.Equal(t, 0, test.expected.Cmp(num.ToInt()), "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestHexBig_TOML(t *testing.T) {
+	type TestBalances struct {
+		Balance HexBig `toml:"balance"`
+		Limit   HexBig `toml:"limit"`
+	}
+
+	var balances TestBalances
+	err := toml.NewDecoder(strings.NewReader("balance = \"0x56bc75e2d63100000\"\nlimit = 1000\n")).Decode(&balances)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "100000000000000000000", balances.Balance.String())
+	assert.Equal(t, "1000", balances.Limit.String())
+
+	var buf bytes.Buffer
+	err = toml.NewEncoder(&buf).Encode(balances)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "balance = \"0x56bc75e2d63100000\"\nlimit = \"0x3e8\"\n", buf.String())
+}
+
+func TestNewHexBig(t *testing.T) {
+	original := big.NewInt(42)
+	num := NewHexBig(original)
+	original.SetInt64(7)
+
+	assert.Equal(t, "42", num.String())
+	assert.Equal(t, "0x2a", num.Hex())
+	negative := NewHexBig(big.NewInt(-42))
+	assert.Equal(t, "-0x2a", negative.Hex())
+	assert.Equal(t, HexBig{}, NewHexBig(new(big.Int).SetBytes(make([]byte, 16))))
+	assert.Equal(t, HexBig{}, NewHexBig(nil))
+}
+
+func TestHexBig_Stringer(t *testing.T) {
+	tx := Transaction{Value: NewHexBig(big.NewInt(1000))}
+
+	var stringer fmt.Stringer = tx.Value
+	assert.Equal(t, "1000", stringer.String())
+	assert.Equal(t, "1000 1000", fmt.Sprintf("%v %s", tx.Value, tx.Value))
+	//the methods can be called on values that aren't addressable
+	assert.Equal(t, "0x3e8", NewHexBig(big.NewInt(1000)).Hex())
+	assert.Equal(t, big.NewInt(1000), NewHexBig(big.NewInt(1000)).ToInt())
+}
+
+func TestTransaction_UnmarshalLargeValue(t *testing.T) {
+	var tx Transaction
+	err := json.Unmarshal([]byte(`{"value":"0x56bc75e2d63100000","gasPrice":"0x3b9aca00","gas":21000}`), &tx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "100000000000000000000", tx.Value.String())
+	assert.Equal(t, "1000000000", tx.GasPrice.String())
+	assert.EqualValues(t, 21000, tx.Gas)
+}
+
+func TestParseAddress(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		expected      Address
+		expectedError string
+	}{
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342f34", "1932c48b2bf8102ba33b4a6b545c32236e342f34", ""},
+		{"1932c48b2bf8102ba33b4a6b545c32236e342f34", "1932c48b2bf8102ba33b4a6b545c32236e342f34", ""},
+		{"0X1932C48B2BF8102BA33B4A6B545C32236E342F34", "1932c48b2bf8102ba33b4a6b545c32236e342f34", ""},
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342f", "", `invalid address "0x1932c48b2bf8102ba33b4a6b545c32236e342f": expected 20 bytes but got 19`},
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342f3400", "", `invalid address "0x1932c48b2bf8102ba33b4a6b545c32236e342f3400": expected 20 bytes but got 21`},
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342f3", "", `invalid address "0x1932c48b2bf8102ba33b4a6b545c32236e342f3": odd number of hex digits`},
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342fzz", "", `invalid address "0x1932c48b2bf8102ba33b4a6b545c32236e342fzz": invalid hex digit 'z'`},
+		{"", "", `invalid address "": expected 20 bytes but got 0`},
+	}
+
+	for idx, test := range testMatrix {
+		addr, err := ParseAddress(test.input)
+
+		assert.Equal(t, test.expected, addr, "Test index %d failed", idx)
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		expected      Hash
+		expectedError string
+	}{
+		{"0xe625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa8", "e625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa8", ""},
+		{"e625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa8", "e625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa8", ""},
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342f34", "", `invalid hash "0x1932c48b2bf8102ba33b4a6b545c32236e342f34": expected 32 bytes but got 20`},
+		{"0xe625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa", "", `invalid hash "0xe625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa": odd number of hex digits`},
+		{"0xe625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30ax8", "", `invalid hash "0xe625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30ax8": invalid hex digit 'x'`},
+	}
+
+	for idx, test := range testMatrix {
+		hsh, err := ParseHash(test.input)
+
+		assert.Equal(t, test.expected, hsh, "Test index %d failed", idx)
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestParseHexData(t *testing.T) {
+	testMatrix := []struct {
+		input         string
+		expected      HexData
+		expectedError string
+	}{
+		{"0x", "", ""},
+		{"", "", ""},
+		{"0x01FF", "01ff", ""},
+		{"01ff", "01ff", ""},
+		{"0x1ff", "", `invalid hex data "0x1ff": odd number of hex digits`},
+		{"0x01fg", "", `invalid hex data "0x01fg": invalid hex digit 'g'`},
+	}
+
+	for idx, test := range testMatrix {
+		data, err := ParseHexData(test.input)
+
+		assert.Equal(t, test.expected, data, "Test index %d failed", idx)
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	assert.Equal(t, Address("1932c48b2bf8102ba33b4a6b545c32236e342f34"), MustParseAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"))
+	assert.Equal(t, Hash("e625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa8"), MustParseHash("0xe625ba9f14eed0671508966080fb01374d0a3a16b9cee545a324179b75f30aa8"))
+	assert.Equal(t, HexData("01ff"), MustParseHexData("0x01ff"))
+
+	assert.PanicsWithError(t, `invalid address "0xabc": odd number of hex digits`, func() { MustParseAddress("0xabc") })
+	assert.PanicsWithError(t, `invalid hash "0xabcd": expected 32 bytes but got 2`, func() { MustParseHash("0xabcd") })
+	assert.PanicsWithError(t, `invalid hex data "0xabc": odd number of hex digits`, func() { MustParseHexData("0xabc") })
+}
+
+func TestUnmarshalJSON_Malformed(t *testing.T) {
+	var tx struct {
+		From Address `json:"from"`
+		Hash Hash    `json:"hash"`
+		Data HexData `json:"data"`
+	}
+
+	testMatrix := []struct {
+		input         string
+		expectedError string
+	}{
+		{`{"from": "0xabc"}`, `invalid address "0xabc": odd number of hex digits`},
+		{`{"from": "0x1932c48b2bf8102ba33b4a6b545c32236e342f34ff"}`, `invalid address "0x1932c48b2bf8102ba33b4a6b545c32236e342f34ff": expected 20 bytes but got 21`},
+		{`{"hash": "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"}`, `invalid hash "0x1932c48b2bf8102ba33b4a6b545c32236e342f34": expected 32 bytes but got 20`},
+		{`{"data": "0xnothex"}`, `invalid hex data "0xnothex": invalid hex digit 'n'`},
+		{`{"from": 1}`, "json: cannot unmarshal number into Go value of type string"},
+	}
+
+	for idx, test := range testMatrix {
+		err := json.Unmarshal([]byte(test.input), &tx)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+
+	//null leaves the value unchanged
+	tx.From = MustParseAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34")
+	err := json.Unmarshal([]byte(`{"from": null, "hash": null, "data": null}`), &tx)
+	assert.Nil(t, err)
+	assert.Equal(t, Address("1932c48b2bf8102ba33b4a6b545c32236e342f34"), tx.From)
+	assert.Equal(t, Hash(""), tx.Hash)
+	assert.Equal(t, HexData(""), tx.Data)
+}
+
+func TestUnmarshalJSON_Empty(t *testing.T) {
+	var tx struct {
+		From Address `json:"from"`
+		Hash Hash    `json:"hash"`
+	}
+
+	for idx, input := range []string{`{"from": "", "hash": ""}`, `{"from": "0x", "hash": "0x"}`} {
+		tx.From = MustParseAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34")
+		tx.Hash = NewHash("0x01")
+
+		err := json.Unmarshal([]byte(input), &tx)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, Address(""), tx.From, "Test index %d failed", idx)
+		assert.Equal(t, Hash(""), tx.Hash, "Test index %d failed", idx)
+	}
+}
+
+func TestCallMsg_MarshalJSON(t *testing.T) {
+	to := NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17")
+
+	encoded, err := json.Marshal(CallMsg{To: to, Data: "70a08231"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"to":"0x1349f3e1b8d71effb47b840594ff27da7e603d17","data":"0x70a08231"}`, string(encoded))
+
+	encoded, err = json.Marshal(CallMsg{From: to, To: to})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"from":"0x1349f3e1b8d71effb47b840594ff27da7e603d17","to":"0x1349f3e1b8d71effb47b840594ff27da7e603d17","data":"0x"}`, string(encoded))
+
+	//EIP165Call is marshalled in the same way, but keeps its own name
+	encoded, err = json.Marshal(EIP165Call{To: to, Data: "70a08231"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"to":"0x1349f3e1b8d71effb47b840594ff27da7e603d17","data":"0x70a08231"}`, string(encoded))
+	assert.Equal(t, "types.EIP165Call", fmt.Sprintf("%T", EIP165Call{}))
+}
+
+func TestTransaction_JSONRoundTrip(t *testing.T) {
+	tx := Transaction{
+		Hash:        NewHash("0x01"),
+		BlockNumber: 1,
+		From:        NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+		Value:       NewHexBig(big.NewInt(1000)),
+		Data:        HexData("646f67"),
+		Events:      []*Event{{Address: NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34")}},
+	}
+
+	marshalled, err := json.Marshal(tx)
+	assert.Nil(t, err)
+
+	var result Transaction
+	err = json.Unmarshal(marshalled, &result)
+
+	assert.Nil(t, err)
+	assert.Equal(t, tx, result)
+}
+
+func TestEvent_JSONRoundTrip(t *testing.T) {
+	marshalled, err := json.Marshal(Event{})
+	assert.Nil(t, err)
+
+	var result Event
+	err = json.Unmarshal(marshalled, &result)
+
+	assert.Nil(t, err)
+	assert.Equal(t, Event{}, result)
+}
+
+func TestUnmarshalTOML_Malformed(t *testing.T) {
+	type TestConfig struct {
+		Addr Address `toml:"address"`
+		Data HexData `toml:"data"`
+	}
+
+	var config TestConfig
+	err := toml.NewDecoder(strings.NewReader(`address = "0x1932c48b2bf8102ba33b4a6b545c32236e342f"`)).Decode(&config)
+	assert.EqualError(t, err, `line 1: (types.TestConfig.Addr) invalid address "0x1932c48b2bf8102ba33b4a6b545c32236e342f": expected 20 bytes but got 19`)
+
+	err = toml.NewDecoder(strings.NewReader(`data = "0x01ff"`)).Decode(&config)
+	assert.Nil(t, err)
+	assert.Equal(t, HexData("01ff"), config.Data)
+}
+
+func TestAddress_Text(t *testing.T) {
+	address := NewAddress("1932c48b2bf8102ba33b4a6b545c32236e342f34")
+
+	text, err := address.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", string(text))
+
+	var parsed Address
+	err = parsed.UnmarshalText(text)
+	assert.Nil(t, err)
+	assert.Equal(t, address, parsed)
+
+	err = parsed.UnmarshalText([]byte("0x1932"))
+	assert.EqualError(t, err, `invalid address "0x1932": expected 20 bytes but got 2`)
+}
+
+func TestAddress_Scan(t *testing.T) {
+	addressBytes, _ := hex.DecodeString("1932c48b2bf8102ba33b4a6b545c32236e342f34")
+
+	testMatrix := []struct {
+		src           interface{}
+		expected      Address
+		expectedError string
+	}{
+		{"0x1932c48b2bf8102ba33b4a6b545c32236e342f34", "1932c48b2bf8102ba33b4a6b545c32236e342f34", ""},
+		{[]byte("1932c48b2bf8102ba33b4a6b545c32236e342f34"), "1932c48b2bf8102ba33b4a6b545c32236e342f34", ""},
+		{addressBytes, "1932c48b2bf8102ba33b4a6b545c32236e342f34", ""},
+		{nil, "", ""},
+		{"0x1932", "", `invalid address "0x1932": expected 20 bytes but got 2`},
+		{int64(1), "", "cannot scan int64 into an address"},
+	}
+
+	for idx, test := range testMatrix {
+		var addr Address
+		err := addr.Scan(test.src)
+
+		assert.Equal(t, test.expected, addr, "Test index %d failed", idx)
+		if test.expectedError == "" {
+			assert.Nil(t, err, "Test index %d failed", idx)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestAddress_Value(t *testing.T) {
+	value, err := NewAddress("1932c48b2bf8102ba33b4a6b545c32236e342f34").Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", value)
+
+	value, err = Address("").Value()
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
+
+func TestAddress_Format(t *testing.T) {
+	address := NewAddress("1932c48b2bf8102ba33b4a6b545c32236e342f34")
+
+	testMatrix := []struct {
+		format   string
+		expected string
+	}{
+		{"%s", "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		{"%v", "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		{"%q", `"0x1932c48b2bf8102ba33b4a6b545c32236e342f34"`},
+		{"%x", "1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		{"%#x", "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		{"%X", "1932C48B2BF8102BA33B4A6B545C32236E342F34"},
+		{"%#X", "0X1932C48B2BF8102BA33B4A6B545C32236E342F34"},
+		{"%44s|", "  0x1932c48b2bf8102ba33b4a6b545c32236e342f34|"},
+		{"%-44s|", "0x1932c48b2bf8102ba33b4a6b545c32236e342f34  |"},
+		{"%d", "%!d(address=0x1932c48b2bf8102ba33b4a6b545c32236e342f34)"},
+	}
+
+	for idx, test := range testMatrix {
+		assert.Equal(t, test.expected, fmt.Sprintf(test.format, address), "Test index %d failed", idx)
+	}
+
+	//addresses within other values are formatted in the same way
+	assert.Equal(t, "{0x1932c48b2bf8102ba33b4a6b545c32236e342f34}", fmt.Sprintf("%v", struct{ To Address }{address}))
+}
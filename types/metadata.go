@@ -0,0 +1,333 @@
+package types
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ContractMetadata is the CBOR encoded metadata that the compiler appends to the end of the code of a contract.
+//
+// Solidity appends a map holding the hash of the metadata file and the compiler version, followed by its length
+// as 2 big-endian bytes. Vyper appends either a map holding its version, or from 0.3.10 an array that ends with
+// such a map, and from 0.4.0 the length also counts the 2 bytes of the length itself; all of these are accepted.
+type ContractMetadata struct {
+	// IPFS is the multihash of the metadata file, see IPFSHash for its usual form
+	IPFS HexData `json:"ipfs,omitempty"`
+	// Bzzr0 and Bzzr1 are the Swarm hashes of the metadata file, given by solc before 0.6.0
+	Bzzr0 Hash `json:"bzzr0,omitempty"`
+	Bzzr1 Hash `json:"bzzr1,omitempty"`
+	// Solc is the version of the compiler, e.g. "0.8.19", or the full version string of a prerelease build
+	Solc string `json:"solc,omitempty"`
+	// Experimental is set if the contract uses experimental language features
+	Experimental bool `json:"experimental,omitempty"`
+	// Vyper is the version of the compiler for a contract compiled with Vyper
+	Vyper string `json:"vyper,omitempty"`
+	// Fields are all of the decoded entries of the map, including any the fields above don't cover
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// Length is the number of bytes at the end of the code that the metadata takes, including its length
+	Length int `json:"length"`
+}
+
+// ParseContractMetadata decodes the metadata at the end of the code of a contract, which is either the
+// runtime code, or the creation code without the constructor arguments
+func ParseContractMetadata(code []byte) (*ContractMetadata, error) {
+	return parseMetadataAt(code, len(code))
+}
+
+// IPFSHash gives the IPFS multihash in base58, as it is used to fetch the metadata file, e.g. "Qm..."
+func (metadata *ContractMetadata) IPFSHash() string {
+	return base58Encode(metadata.IPFS.AsBytes())
+}
+
+/*
+SplitDeploymentData splits the data of a contract deployment into the creation code and the constructor
+arguments that are appended to it, returning the metadata that ends the creation code.
+
+The creation code ends with the runtime code, and so with its metadata. If the constructor only has arguments
+of a fixed size then the split is made from their size, and the metadata is only decoded if it is there.
+Otherwise the split is made at the metadata nearest the end of the data for which the rest of the data is
+exactly the encoding of the constructor arguments, so that neither code that deploys other contracts, nor
+arguments that hold code, are mistaken for the end of the code.
+*/
+func SplitDeploymentData(data []byte, constructor ContractABIFunction) (code []byte, args []byte, metadata *ContractMetadata, err error) {
+	argumentsType, err := tupleType(constructor.Inputs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	headSize := argumentsType.staticSize()
+	if uint64(len(data)) < headSize {
+		return nil, nil, nil, fmt.Errorf("expected at least %d bytes of constructor arguments, got %d bytes of data", headSize, len(data))
+	}
+	end := len(data) - int(headSize)
+
+	if !argumentsType.IsDynamic() {
+		metadata, _ = parseMetadataAt(data, end)
+		return data[:end], data[end:], metadata, nil
+	}
+
+	for ; end >= 2; end-- {
+		found, err := parseMetadataAt(data, end)
+		if err != nil {
+			continue
+		}
+		if _, err := DecodeAllDataStrict(constructor.Inputs, data[end:]); err == nil {
+			return data[:end], data[end:], found, nil
+		}
+	}
+	return nil, nil, nil, errors.New("no metadata was found that is followed by the constructor arguments")
+}
+
+//parseMetadataAt decodes the metadata that ends at the given position in the code
+func parseMetadataAt(code []byte, end int) (*ContractMetadata, error) {
+	if end < 2 || end > len(code) {
+		return nil, errors.New("code is too short to hold metadata")
+	}
+	length := int(binary.BigEndian.Uint16(code[end-2 : end]))
+
+	metadata, err := decodeMetadata(code, end-2, length)
+	if err != nil && length >= 2 {
+		//Vyper 0.4.0 onwards includes the 2 bytes of the length
+		if vyperMetadata, vyperErr := decodeMetadata(code, end-2, length-2); vyperErr == nil && vyperMetadata.Vyper != "" {
+			return vyperMetadata, nil
+		}
+	}
+	return metadata, err
+}
+
+//decodeMetadata decodes the CBOR of the given length that ends at the given position in the code
+func decodeMetadata(code []byte, end int, length int) (*ContractMetadata, error) {
+	if length == 0 || length > end {
+		return nil, fmt.Errorf("invalid metadata length %d for %d bytes of code", length, end)
+	}
+
+	reader := &cborReader{data: code[end-length : end]}
+	value, err := reader.readValue(0)
+	if err != nil {
+		return nil, errors.New("invalid metadata: " + err.Error())
+	}
+	if reader.pos != length {
+		return nil, fmt.Errorf("invalid metadata: %d bytes are left after the CBOR value", length-reader.pos)
+	}
+
+	//from Vyper 0.3.10 the map is the last item of an array that also holds the sizes of the code sections
+	if items, ok := value.([]interface{}); ok && len(items) > 0 {
+		value = items[len(items)-1]
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid metadata: expected a CBOR map")
+	}
+
+	metadata := &ContractMetadata{Fields: fields, Length: length + 2}
+	if err := metadata.setFields(); err != nil {
+		return nil, errors.New("invalid metadata: " + err.Error())
+	}
+	if metadata.IPFS == "" && metadata.Bzzr0 == "" && metadata.Bzzr1 == "" && metadata.Solc == "" && metadata.Vyper == "" {
+		return nil, errors.New("invalid metadata: no source hash or compiler version")
+	}
+	return metadata, nil
+}
+
+//setFields sets the known fields from the decoded map
+func (metadata *ContractMetadata) setFields() error {
+	for key, value := range metadata.Fields {
+		switch key {
+		case "ipfs":
+			hash, ok := value.([]byte)
+			if !ok {
+				return errors.New("ipfs must be a byte string")
+			}
+			metadata.IPFS = HexData(hex.EncodeToString(hash))
+		case "bzzr0", "bzzr1":
+			hash, ok := value.([]byte)
+			if !ok || len(hash) != 32 {
+				return errors.New(key + " must be a 32 byte string")
+			}
+			if key == "bzzr0" {
+				metadata.Bzzr0 = Hash(hex.EncodeToString(hash))
+			} else {
+				metadata.Bzzr1 = Hash(hex.EncodeToString(hash))
+			}
+		case "solc":
+			switch version := value.(type) {
+			case []byte:
+				if len(version) != 3 {
+					return errors.New("solc must be 3 bytes, or the full version string")
+				}
+				metadata.Solc = fmt.Sprintf("%d.%d.%d", version[0], version[1], version[2])
+			case string:
+				metadata.Solc = version
+			default:
+				return errors.New("solc must be 3 bytes, or the full version string")
+			}
+		case "experimental":
+			experimental, ok := value.(bool)
+			if !ok {
+				return errors.New("experimental must be a bool")
+			}
+			metadata.Experimental = experimental
+		case "vyper":
+			switch version := value.(type) {
+			case []interface{}:
+				parts := make([]string, len(version))
+				for i, part := range version {
+					number, ok := part.(uint64)
+					if !ok {
+						return errors.New("vyper must be a list of numbers, or a version string")
+					}
+					parts[i] = strconv.FormatUint(number, 10)
+				}
+				metadata.Vyper = strings.Join(parts, ".")
+			case string:
+				metadata.Vyper = version
+			default:
+				return errors.New("vyper must be a list of numbers, or a version string")
+			}
+		}
+	}
+	return nil
+}
+
+//maxCBORDepth is the deepest nesting of arrays and maps that is decoded, metadata only ever has a few levels
+const maxCBORDepth = 8
+
+//cborReader decodes the subset of CBOR used by compiler metadata: integers, byte and text strings, arrays,
+//maps with text keys, and the simple values false, true and null. Lengths must be definite
+type cborReader struct {
+	data []byte
+	pos  int
+}
+
+func (reader *cborReader) readValue(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("CBOR values are nested too deeply")
+	}
+	if reader.pos >= len(reader.data) {
+		return nil, errors.New("unexpected end of CBOR data")
+	}
+	initial := reader.data[reader.pos]
+	reader.pos++
+	major, info := initial>>5, initial&0x1f
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unsupported CBOR simple value or float 0x%02x", initial)
+		}
+	}
+
+	argument, err := reader.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		return argument, nil
+	case 1:
+		return new(big.Int).Sub(big.NewInt(-1), new(big.Int).SetUint64(argument)), nil
+	case 2, 3:
+		if argument > uint64(len(reader.data)-reader.pos) {
+			return nil, errors.New("unexpected end of CBOR data")
+		}
+		content := reader.data[reader.pos : reader.pos+int(argument)]
+		reader.pos += int(argument)
+		if major == 3 {
+			return string(content), nil
+		}
+		return content, nil
+	case 4:
+		//every item takes at least a byte, so the length can be checked before allocating
+		if argument > uint64(len(reader.data)-reader.pos) {
+			return nil, errors.New("unexpected end of CBOR data")
+		}
+		items := make([]interface{}, argument)
+		for i := range items {
+			if items[i], err = reader.readValue(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case 5:
+		if argument > uint64(len(reader.data)-reader.pos)/2 {
+			return nil, errors.New("unexpected end of CBOR data")
+		}
+		entries := make(map[string]interface{}, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, err := reader.readValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, errors.New("CBOR map keys must be text strings")
+			}
+			if _, exists := entries[name]; exists {
+				return nil, errors.New("duplicate CBOR map key: " + name)
+			}
+			if entries[name], err = reader.readValue(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	default:
+		return nil, errors.New("unsupported CBOR tag")
+	}
+}
+
+//readArgument reads the number that follows the initial byte, which is a value, length or count
+func (reader *cborReader) readArgument(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+	if info > 27 {
+		return 0, errors.New("indefinite length CBOR values are not supported")
+	}
+	size := 1 << (info - 24)
+	if size > len(reader.data)-reader.pos {
+		return 0, errors.New("unexpected end of CBOR data")
+	}
+	var argument uint64
+	for _, b := range reader.data[reader.pos : reader.pos+size] {
+		argument = argument<<8 | uint64(b)
+	}
+	reader.pos += size
+	return argument, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+//base58Encode encodes the bytes with the Bitcoin alphabet used by IPFS, keeping leading zeros as "1"
+func base58Encode(input []byte) string {
+	number := new(big.Int).SetBytes(input)
+	base := big.NewInt(58)
+	remainder := new(big.Int)
+
+	var encoded []byte
+	for number.Sign() > 0 {
+		number.QuoRem(number, base, remainder)
+		encoded = append(encoded, base58Alphabet[remainder.Int64()])
+	}
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
@@ -0,0 +1,149 @@
+package types
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	//solc >= 0.6.0: {"ipfs": <multihash>, "solc": 0.8.19}
+	ipfsMetadata = "a2" + "64697066735822" + "1220" + strings.Repeat("11", 32) + "64736f6c6343" + "000813" + "0033"
+	//solc 0.5.x: {"bzzr1": <hash>, "solc": 0.5.12}
+	bzzr1Metadata = "a2" + "65627a7a72315820" + strings.Repeat("22", 32) + "64736f6c6343" + "00050c" + "0032"
+	//solc < 0.5.10: {"bzzr0": <hash>}
+	bzzr0Metadata = "a1" + "65627a7a72305820" + strings.Repeat("33", 32) + "0029"
+	//{"ipfs": <multihash>, "experimental": true, "solc": 0.8.19}
+	experimentalMetadata = "a3" + "64697066735822" + "1220" + strings.Repeat("11", 32) + "6c6578706572696d656e74616c" + "f5" + "64736f6c6343" + "000813" + "0041"
+	//vyper 0.3.7: {"vyper": [0, 3, 7]}
+	vyperMetadata = "a1" + "657679706572" + "83000307" + "000b"
+	//vyper 0.4.0: [291, [], 0, {"vyper": [0, 4, 0]}], where the length includes itself
+	vyperArrayMetadata = "84" + "190123" + "80" + "00" + "a1" + "657679706572" + "83000400" + "0013"
+)
+
+func TestParseContractMetadata(t *testing.T) {
+	testMatrix := []struct {
+		code     string
+		expected ContractMetadata
+	}{
+		{"6080604052" + ipfsMetadata, ContractMetadata{IPFS: NewHexData("1220" + strings.Repeat("11", 32)), Solc: "0.8.19", Length: 53}},
+		{"6080604052" + bzzr1Metadata, ContractMetadata{Bzzr1: Hash(strings.Repeat("22", 32)), Solc: "0.5.12", Length: 52}},
+		{"6080604052" + bzzr0Metadata, ContractMetadata{Bzzr0: Hash(strings.Repeat("33", 32)), Length: 43}},
+		{"6080604052" + experimentalMetadata, ContractMetadata{IPFS: NewHexData("1220" + strings.Repeat("11", 32)), Solc: "0.8.19", Experimental: true, Length: 67}},
+		{"6003600055" + vyperMetadata, ContractMetadata{Vyper: "0.3.7", Length: 13}},
+		{"6003600055" + vyperArrayMetadata, ContractMetadata{Vyper: "0.4.0", Length: 19}},
+	}
+
+	for idx, test := range testMatrix {
+		metadata, err := ParseContractMetadata(hexToBytes(test.code))
+
+		if assert.Nil(t, err, "Test index %d failed", idx) {
+			metadata.Fields = nil
+			assert.Equal(t, test.expected, *metadata, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestParseContractMetadata_Errors(t *testing.T) {
+	testMatrix := []struct {
+		code          string
+		expectedError string
+	}{
+		{"00", "code is too short to hold metadata"},
+		{"6080604052" + "0000", "invalid metadata length 0 for 5 bytes of code"},
+		{"6080604052" + "0033", "invalid metadata length 51 for 5 bytes of code"},
+		//the length covers one byte too many
+		{"60" + ipfsMetadata[:len(ipfsMetadata)-4] + "0034", "invalid metadata: 51 bytes are left after the CBOR value"},
+		{"a1" + "6474657374" + "01" + "0007", "invalid metadata: no source hash or compiler version"},
+		{"a1" + "64736f6c63" + "420008" + "0009", "invalid metadata: solc must be 3 bytes, or the full version string"},
+		{"a1" + "65627a7a72305820" + "00" + "000a", "invalid metadata: unexpected end of CBOR data"},
+		{"bf" + "ff" + "0002", "invalid metadata: indefinite length CBOR values are not supported"},
+		{"a2" + "6473696c63" + "f5" + "6473696c63" + "f4" + "000d", "invalid metadata: duplicate CBOR map key: silc"},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := ParseContractMetadata(hexToBytes(test.code))
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestContractMetadata_IPFSHash(t *testing.T) {
+	metadata, err := ParseContractMetadata(hexToBytes(ipfsMetadata))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "QmPVGjYFugq4XUyBfoTHG6c3qxfBS26jEdaFM1gdAVuMZ2", metadata.IPFSHash())
+}
+
+func TestSplitDeploymentData(t *testing.T) {
+	staticConstructor := ContractABIFunction{Type: "constructor", Inputs: []ContractABIArgument{{Name: "supply", Type: "uint256"}, {Name: "owner", Type: "address"}}}
+	dynamicConstructor := ContractABIFunction{Type: "constructor", Inputs: []ContractABIArgument{{Name: "code", Type: "bytes"}, {Name: "name", Type: "string"}}}
+
+	//a factory holds the code of the contract it deploys, with its own metadata
+	code := "6080604052" + "6080604052" + bzzr1Metadata + "6000" + ipfsMetadata
+	//and the arguments hold code too
+	args, err := EncodeAllData(dynamicConstructor.Inputs, []interface{}{hexToBytes("60" + ipfsMetadata), "token"})
+	assert.Nil(t, err)
+	encodedArgs := hex.EncodeToString(args)
+
+	testMatrix := []struct {
+		constructor  ContractABIFunction
+		data         string
+		expectedCode string
+		expectedArgs string
+		expectedSolc string
+	}{
+		{staticConstructor, code + word("3e8") + word("1932c48b2bf8102ba33b4a6b545c32236e342f34"), code, word("3e8") + word("1932c48b2bf8102ba33b4a6b545c32236e342f34"), "0.8.19"},
+		{ContractABIFunction{Type: "constructor"}, code, code, "", "0.8.19"},
+		{dynamicConstructor, code + encodedArgs, code, encodedArgs, "0.8.19"},
+		//an old contract, whose metadata comes before the arguments
+		{dynamicConstructor, "6080604052" + bzzr0Metadata + encodedArgs, "6080604052" + bzzr0Metadata, encodedArgs, ""},
+	}
+
+	for idx, test := range testMatrix {
+		code, args, metadata, err := SplitDeploymentData(hexToBytes(test.data), test.constructor)
+
+		if assert.Nil(t, err, "Test index %d failed", idx) {
+			assert.Equal(t, test.expectedCode, hex.EncodeToString(code), "Test index %d failed", idx)
+			assert.Equal(t, test.expectedArgs, hex.EncodeToString(args), "Test index %d failed", idx)
+			assert.Equal(t, test.expectedSolc, metadata.Solc, "Test index %d failed", idx)
+		}
+	}
+
+	//the size of static arguments is known, so metadata isn't needed
+	split, rest, metadata, err := SplitDeploymentData(hexToBytes("6080604052"+word("3e8")+word("01")), staticConstructor)
+	assert.Nil(t, err)
+	assert.Equal(t, "6080604052", hex.EncodeToString(split))
+	assert.Len(t, rest, 64)
+	assert.Nil(t, metadata)
+
+	_, _, _, err = SplitDeploymentData(hexToBytes("6080604052"+encodedArgs), dynamicConstructor)
+	assert.EqualError(t, err, "no metadata was found that is followed by the constructor arguments")
+	_, _, _, err = SplitDeploymentData(hexToBytes("6080604052"), staticConstructor)
+	assert.EqualError(t, err, "expected at least 64 bytes of constructor arguments, got 5 bytes of data")
+}
+
+func TestParsedTransaction_ParseTransactionDeployment(t *testing.T) {
+	rawABI := `[{"type":"constructor","inputs":[{"name":"name","type":"string"},{"name":"supply","type":"uint256"}],"stateMutability":"nonpayable"}]`
+	code := "6080604052" + experimentalMetadata
+	args := word("40") + word("3e8") + word("5") + "746f6b656e" + strings.Repeat("00", 27)
+
+	ptx := &ParsedTransaction{RawTransaction: &Transaction{Data: NewHexData(code + args)}}
+	assert.Nil(t, ptx.ParseTransaction(rawABI))
+
+	assert.Equal(t, "constructor(string name,uint256 supply)", ptx.Sig)
+	assert.Equal(t, "token", ptx.ParsedData["name"])
+	assert.Equal(t, ConfidenceABI, ptx.Confidence)
+	if assert.NotNil(t, ptx.Metadata) {
+		assert.Equal(t, "0.8.19", ptx.Metadata.Solc)
+		assert.True(t, ptx.Metadata.Experimental)
+	}
+
+	//without metadata the end of the code can't be found
+	ptx = &ParsedTransaction{RawTransaction: &Transaction{Data: NewHexData("6080604052" + args)}}
+	assert.Nil(t, ptx.ParseTransaction(rawABI))
+	assert.Equal(t, "unable to parse params", ptx.ParsedData["error"])
+	assert.Nil(t, ptx.Metadata)
+}
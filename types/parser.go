@@ -3,17 +3,49 @@ package types
 import (
 	"encoding/hex"
 	"errors"
-	"strings"
 
 	"github.com/ConsenSys/quorum-go-utils/log"
 )
 
+// Confidence is how certain a parsed transaction or event is to have been decoded with the right signature
+type Confidence string
+
+const (
+	// ConfidenceABI is a match against the ABI of the contract
+	ConfidenceABI Confidence = "abi"
+	// ConfidenceSignature is a match against the only signature from a signature database, rather than the
+	// ABI of the contract, whose arguments are exactly encoded by the data
+	ConfidenceSignature Confidence = "signature"
+	// ConfidenceAmbiguous is where more than one signature matches, which are given as the candidates
+	ConfidenceAmbiguous Confidence = "ambiguous"
+)
+
+// ParsedTransaction is a transaction with its input, output and events decoded using the contract ABI.
+// ParsedData and ParsedOutput hold the values by name, with unnamed outputs keyed by position, whilst
+// DecodedData and DecodedOutput hold the same values in the order they are declared in the ABI. If the output of the transaction can't be
+// decoded, the input is still parsed and OutputError says why
 type ParsedTransaction struct {
-	Sig            string                 `json:"txSig"`
-	Func4Bytes     HexData                `json:"func4Bytes"`
-	ParsedData     map[string]interface{} `json:"parsedData"`
-	ParsedEvents   []*ParsedEvent         `json:"parsedEvents"`
-	RawTransaction *Transaction           `json:"rawTransaction"`
+	Sig            string                        `json:"txSig"`
+	Func4Bytes     HexData                       `json:"func4Bytes"`
+	ParsedData     map[string]interface{}        `json:"parsedData"`
+	DecodedData    DecodedValues                 `json:"decodedData,omitempty"`
+	Confidence     Confidence                    `json:"confidence,omitempty"`
+	Candidates     []*ParsedTransactionCandidate `json:"candidates,omitempty"`
+	ParsedEvents   []*ParsedEvent                `json:"parsedEvents"`
+	ParsedOutput   map[string]interface{}        `json:"parsedOutput,omitempty"`
+	DecodedOutput  DecodedValues                 `json:"decodedOutput,omitempty"`
+	OutputError    string                        `json:"outputError,omitempty"`
+	Revert         *RevertError                  `json:"revert,omitempty"`
+	Metadata       *ContractMetadata             `json:"metadata,omitempty"`
+	RawTransaction *Transaction                  `json:"rawTransaction"`
+}
+
+// ParsedTransactionCandidate is one of the functions that a transaction could have been parsed as,
+// when it was matched against a signature database rather than the ABI of the contract
+type ParsedTransactionCandidate struct {
+	Sig         string                 `json:"txSig"`
+	ParsedData  map[string]interface{} `json:"parsedData"`
+	DecodedData DecodedValues          `json:"decodedData,omitempty"`
 }
 
 func (ptx *ParsedTransaction) ParseTransaction(rawABI string) error {
@@ -21,12 +53,22 @@ func (ptx *ParsedTransaction) ParseTransaction(rawABI string) error {
 		return errors.New("transaction is nil or invalid")
 	}
 
-	structure, err := NewABIStructureFromJSON(rawABI)
+	compiled, err := CompileABI(rawABI)
 	if err != nil {
 		log.Error("Could not unmarshal ABI", "abi", rawABI)
 		return errors.New("could not unmarshal ABI")
 	}
-	internalAbi := structure.ToInternalABI()
+	return ptx.ParseTransactionWithABI(compiled)
+}
+
+// ParseTransactionWithABI parses the transaction in the same way as ParseTransaction, using an ABI that has
+// already been compiled so that it can be shared between transactions, along with its options, see
+// CompiledABI.WithOptions
+func (ptx *ParsedTransaction) ParseTransactionWithABI(compiled *CompiledABI) error {
+	if ptx.RawTransaction == nil {
+		return errors.New("transaction is nil or invalid")
+	}
+	internalAbi := compiled.ContractABI
 
 	log.Debug("Parse transaction", "tx", ptx.RawTransaction.Hash.Hex())
 
@@ -40,75 +82,169 @@ func (ptx *ParsedTransaction) ParseTransaction(rawABI string) error {
 	ptx.ParsedData = map[string]interface{}{}
 	// parse transaction data
 	if !ptx.RawTransaction.To.IsEmpty() {
-		ptx.Func4Bytes = HexData(hex.EncodeToString(data[:4]))
-		// check against all abi methods
-		for _, method := range internalAbi.Functions {
-			if method.Signature() == string(ptx.Func4Bytes) {
-				ptx.Sig = method.String()
-				result, err := method.Parse(data[4:])
+		//calldata that is too short to have a selector is a plain transfer or a call to the fallback function,
+		//so there is no function to decode it as
+		ptx.Func4Bytes = ""
+		if len(data) >= 4 {
+			ptx.Func4Bytes = HexData(hex.EncodeToString(data[:4]))
+		}
+		if method, ok := compiled.Function(string(ptx.Func4Bytes)); ok {
+			ptx.Sig = method.String()
+			result, err := method.DecodeWithOptions(data[4:], compiled.options)
+			if err != nil {
+				return err
+			}
+			ptx.ParsedData = result.ToMap()
+			ptx.DecodedData = result
+			ptx.Confidence = ConfidenceABI
+
+			// the output is only available if the transaction has been traced,
+			// and only holds the return values if the transaction succeeded
+			if len(ptx.RawTransaction.Output) > 0 && ptx.RawTransaction.Status {
+				//the input is still worth keeping if the output can't be decoded
+				output, err := method.DecodeOutputWithOptions(ptx.RawTransaction.Output.AsBytes(), compiled.options)
 				if err != nil {
-					return err
+					log.Debug("Could not decode the transaction output", "tx", ptx.RawTransaction.Hash.Hex(), "err", err)
+					ptx.OutputError = err.Error()
+				} else {
+					ptx.ParsedOutput = output.ToIndexedMap()
+					ptx.DecodedOutput = output
 				}
-				ptx.ParsedData = result
 			}
 		}
 	} else {
 		// contract deployment transaction
 		ptx.Sig = "constructor" + internalAbi.Constructor.String()
-		dataHex := hex.EncodeToString(data)
-		if index := strings.LastIndex(dataHex, "a165627a7a72305820"); index > 0 {
-			// search for pattern a165627a7a72305820 for solidity < 0.5.10
-			// <bytecode> + "a165627a7a72305820" + <256 bits whisperHash> + "0029"
-			index = (index + 18 + 64 + 4) / 2 // find hex position 18+64+4 after
-			result, err := internalAbi.Constructor.Parse(data[index:])
-			if err != nil {
-				return err
-			}
-			ptx.ParsedData = result
-		} else if index := strings.LastIndex(dataHex, "64736f6c6343"); index > 0 {
-			// search for pattern 64736f6c6343 for solidity >= 0.5.10,
-			// <bytecode> + "a265627a7a72305820" + <256 bits whisperHash> + "64736f6c6343" + compiler_version(e.g. 000608) + "0033"
-			index = (index + 12 + 6 + 4) / 2 // find hex position 12+6+4 after
-			result, err := internalAbi.Constructor.Parse(data[index:])
+		_, args, metadata, err := SplitDeploymentData(data, internalAbi.Constructor)
+		if err != nil {
+			log.Debug("Could not find the constructor arguments", "tx", ptx.RawTransaction.Hash.Hex(), "err", err)
+			ptx.ParsedData["error"] = "unable to parse params"
+		} else {
+			result, err := internalAbi.Constructor.DecodeWithOptions(args, compiled.options)
 			if err != nil {
 				return err
 			}
-			ptx.ParsedData = result
-		} else {
-			ptx.ParsedData["error"] = "unable to parse params"
+			ptx.ParsedData = result.ToMap()
+			ptx.DecodedData = result
+			ptx.Metadata = metadata
+			ptx.Confidence = ConfidenceABI
 		}
 	}
+
+	// the output of a failed transaction is the revert data
+	if len(ptx.RawTransaction.Output) > 0 && !ptx.RawTransaction.Status {
+		ptx.Revert = internalAbi.DecodeRevertWithOptions(ptx.RawTransaction.Output.AsBytes(), compiled.options)
+	}
 	return nil
 }
 
 type ParsedEvent struct {
-	Sig        string                 `json:"eventSig"`
-	ParsedData map[string]interface{} `json:"parsedData"`
-	RawEvent   *Event                 `json:"rawEvent"`
+	Sig         string                  `json:"eventSig"`
+	ParsedData  map[string]interface{}  `json:"parsedData"`
+	DecodedData DecodedValues           `json:"decodedData,omitempty"`
+	Confidence  Confidence              `json:"confidence,omitempty"`
+	Candidates  []*ParsedEventCandidate `json:"candidates,omitempty"`
+	RawEvent    *Event                  `json:"rawEvent"`
+}
+
+// ParsedEventCandidate is one of the events from the ABI that an event could have been parsed as
+type ParsedEventCandidate struct {
+	Sig         string                 `json:"eventSig"`
+	ParsedData  map[string]interface{} `json:"parsedData"`
+	DecodedData DecodedValues          `json:"decodedData,omitempty"`
 }
 
+/*
+ParseEvent finds the event in the ABI that matches the raw event, and parses it.
+
+An event matches if its signature is the first topic, it has an indexed argument for each of the other topics, and
+the data decodes as its remaining arguments. Anonymous events have no signature topic, so they are only tried by
+their shape if no other event matched.
+
+If more than one event matches, such as events from different standards with the same signature that index the same
+arguments, then Sig and ParsedData are left empty and every match is given in Candidates instead.
+*/
 func (pe *ParsedEvent) ParseEvent(rawABI string) error {
-	if pe.RawEvent == nil || len(pe.RawEvent.Topics) == 0 {
+	if pe.RawEvent == nil {
 		return errors.New("event is nil or invalid")
 	}
 
-	structure, err := NewABIStructureFromJSON(rawABI)
+	compiled, err := CompileABI(rawABI)
 	if err != nil {
 		log.Error("Could not unmarshal ABI", "abi", rawABI)
 		return errors.New("could not unmarshal ABI")
 	}
-	internalAbi := structure.ToInternalABI()
+	return pe.ParseEventWithABI(compiled)
+}
+
+// ParseEventWithABI parses the event in the same way as ParseEvent, using an ABI that has
+// already been compiled so that it can be shared between events, along with its options
+func (pe *ParsedEvent) ParseEventWithABI(compiled *CompiledABI) error {
+	if pe.RawEvent == nil {
+		return errors.New("event is nil or invalid")
+	}
+	var events []ContractABIEvent
+	if len(pe.RawEvent.Topics) > 0 {
+		events = compiled.EventsByTopic(pe.RawEvent.Topics[0])
+	}
+	return pe.parseEvent(events, compiled.anonymousEvents, compiled.options)
+}
 
-	log.Debug("Parse event", "event", pe.RawEvent.Topics[0].Hex())
-	for _, ev := range internalAbi.Events {
-		if "0x"+ev.Signature() == pe.RawEvent.Topics[0].String() {
-			pe.Sig = "event " + ev.String()
-			result, err := ev.Parse(pe.RawEvent.Data.AsBytes())
+//parseEvent parses the event as whichever of the given events match it, where the events with a signature
+//are expected to be the ones whose signature is the first topic
+func (pe *ParsedEvent) parseEvent(events []ContractABIEvent, anonymousEvents []ContractABIEvent, opts DecodeOptions) error {
+	topics, data := pe.RawEvent.Topics, pe.RawEvent.Data.AsBytes()
+	if len(topics) > 0 {
+		log.Debug("Parse event", "event", topics[0].Hex())
+	}
+
+	var candidates []*ParsedEventCandidate
+	var parseErr error
+	for _, anonymous := range []bool{false, true} {
+		toTry := events
+		if anonymous {
+			toTry = anonymousEvents
+		}
+		for _, ev := range toTry {
+			result, err := ev.parseMatching(topics, data, opts)
 			if err != nil {
-				return err
+				//keep the error for an event with the right signature, in case nothing else matches
+				if _, isDecodeErr := err.(*DecodeError); isDecodeErr && !anonymous {
+					parseErr = err
+				}
+				continue
 			}
-			pe.ParsedData = result
+			candidates = append(candidates, &ParsedEventCandidate{Sig: "event " + ev.String(), ParsedData: result.ToMap(), DecodedData: result})
+		}
+		if len(candidates) > 0 {
+			break
 		}
 	}
+
+	switch len(candidates) {
+	case 0:
+		return parseErr
+	case 1:
+		pe.Sig = candidates[0].Sig
+		pe.ParsedData = candidates[0].ParsedData
+		pe.DecodedData = candidates[0].DecodedData
+		pe.Confidence = ConfidenceABI
+	default:
+		log.Debug("Event matches multiple ABI events", "count", len(candidates))
+		pe.Candidates = candidates
+		pe.Confidence = ConfidenceAmbiguous
+	}
 	return nil
 }
+
+// ParseOutput decodes the output of the internal call, using the function from
+// the given ABI that matches the call input
+func (call *InternalCall) ParseOutput(abi *ContractABI) (map[string]interface{}, error) {
+	return abi.ParseCallOutput(call.Input.AsBytes(), call.Output.AsBytes())
+}
+
+// ParseOutput decodes the output of the traced call, using the function from
+// the given ABI that matches the call input
+func (call RawInnerCall) ParseOutput(abi *ContractABI) (map[string]interface{}, error) {
+	return abi.ParseCallOutput(call.Input.AsBytes(), call.Output.AsBytes())
+}
@@ -0,0 +1,248 @@
+package types
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const outputTestABI = `[
+	{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]},
+	{"type":"function","name":"status","inputs":[],"outputs":[{"name":"","type":"bool"},{"name":"","type":"string"}]}
+]`
+
+func outputTestContractABI(t *testing.T) *ContractABI {
+	structure, err := NewABIStructureFromJSON(outputTestABI)
+	assert.Nil(t, err)
+	return structure.ToInternalABI()
+}
+
+func TestContractABIFunction_ParseOutput_Unnamed(t *testing.T) {
+	abi := outputTestContractABI(t)
+
+	output := hexToBytes(word("1") + word("40") + word("2") + "6f6b" + word("")[4:])
+	result, err := abi.Functions[1].ParseOutput(output)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"0": true, "1": "ok"}, result)
+
+	var unpacked struct {
+		Ready   bool
+		Message string
+	}
+	err = abi.Functions[1].UnpackOutput(output, &unpacked)
+	assert.Nil(t, err)
+	assert.True(t, unpacked.Ready)
+	assert.Equal(t, "ok", unpacked.Message)
+}
+
+func TestContractABI_ParseCallOutput(t *testing.T) {
+	abi := outputTestContractABI(t)
+	input, _ := abi.Functions[0].Pack(NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"))
+
+	call := &InternalCall{Input: HexData(hex.EncodeToString(input)), Output: NewHexData(word("3e8"))}
+	result, err := call.ParseOutput(abi)
+	assert.Nil(t, err)
+	assert.EqualValues(t, big.NewInt(1000), result["balance"])
+
+	rawCall := RawInnerCall{Input: call.Input, Output: call.Output}
+	result, err = rawCall.ParseOutput(abi)
+	assert.Nil(t, err)
+	assert.EqualValues(t, big.NewInt(1000), result["balance"])
+
+	_, err = abi.ParseCallOutput(hexToBytes("12345678"), nil)
+	assert.EqualError(t, err, "no function found for call input")
+}
+
+func TestParsedTransaction_ParseTransaction_Output(t *testing.T) {
+	abi := outputTestContractABI(t)
+	input, _ := abi.Functions[0].Pack(NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"))
+
+	testMatrix := []struct {
+		status         bool
+		output         HexData
+		expectedOutput map[string]interface{}
+	}{
+		{true, NewHexData(word("3e8")), map[string]interface{}{"balance": big.NewInt(1000)}},
+		{true, "", nil},
+		{false, NewHexData(word("3e8")), nil},
+	}
+
+	for idx, test := range testMatrix {
+		ptx := &ParsedTransaction{
+			RawTransaction: &Transaction{
+				Status: test.status,
+				To:     NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"),
+				Data:   HexData(hex.EncodeToString(input)),
+				Output: test.output,
+			},
+		}
+
+		err := ptx.ParseTransaction(outputTestABI)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, "balanceOf(address owner)", ptx.Sig, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedOutput, ptx.ParsedOutput, "Test index %d failed", idx)
+	}
+}
+
+func TestParsedTransaction_ParseTransaction_InvalidOutput(t *testing.T) {
+	abi := outputTestContractABI(t)
+	input, _ := abi.Functions[0].Pack(NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"))
+	ptx := &ParsedTransaction{
+		RawTransaction: &Transaction{
+			Status: true,
+			To:     NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"),
+			Data:   HexData(hex.EncodeToString(input)),
+			Output: "03e8",
+		},
+	}
+
+	err := ptx.ParseTransaction(outputTestABI)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "balanceOf(address owner)", ptx.Sig)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", ptx.ParsedData["owner"])
+	assert.Nil(t, ptx.ParsedOutput)
+	assert.Equal(t, "abi: cannot decode balanceOf.balance at offset 0: need 32 bytes, have 2", ptx.OutputError)
+}
+
+func TestParsedTransaction_ParseTransaction_ShortCalldata(t *testing.T) {
+	for idx, data := range []HexData{"", "01", "123456"} {
+		ptx := &ParsedTransaction{
+			RawTransaction: &Transaction{
+				To:   NewAddress("0x1349f3e1b8d71effb47b840594ff27da7e603d17"),
+				Data: data,
+			},
+		}
+
+		err := ptx.ParseTransaction(outputTestABI)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, HexData(""), ptx.Func4Bytes, "Test index %d failed", idx)
+		assert.Equal(t, "", ptx.Sig, "Test index %d failed", idx)
+		assert.Empty(t, ptx.ParsedData, "Test index %d failed", idx)
+	}
+}
+
+func TestParsedEvent_ParseEvent_IndexedTopics(t *testing.T) {
+	rawABI := `[{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]}]`
+
+	pe := &ParsedEvent{
+		RawEvent: &Event{
+			Topics: []Hash{
+				NewHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+				NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+				NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab"),
+			},
+			Data: NewHexData(word("3e8")),
+		},
+	}
+
+	err := pe.ParseEvent(rawABI)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "event Transfer(address from,address to,uint256 value)", pe.Sig)
+	assert.Equal(t, "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", pe.ParsedData["from"])
+	assert.Equal(t, "0x9d13c6d3afe1721beef56b55d303b09e021e27ab", pe.ParsedData["to"])
+	assert.EqualValues(t, big.NewInt(1000), pe.ParsedData["value"])
+}
+
+const transferEventsABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"Log","anonymous":true,"inputs":[
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"message","type":"string","indexed":false}]}
+]`
+
+func TestParsedEvent_ParseEvent_TopicCount(t *testing.T) {
+	transferTopic := NewHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	from := NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34")
+	to := NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab")
+
+	testMatrix := []struct {
+		topics      []Hash
+		data        HexData
+		expectedSig string
+		expectedKey string
+	}{
+		{[]Hash{transferTopic, from, to}, NewHexData(word("3e8")), "event Transfer(address from,address to,uint256 value)", "value"},
+		{[]Hash{transferTopic, from, to, NewHash("3e8")}, "", "event Transfer(address from,address to,uint256 tokenId)", "tokenId"},
+		{[]Hash{from}, NewHexData(word("20") + word("2") + "6869" + word("")[4:]), "event Log(address sender,string message)", "message"},
+	}
+
+	for idx, test := range testMatrix {
+		pe := &ParsedEvent{RawEvent: &Event{Topics: test.topics, Data: test.data}}
+
+		err := pe.ParseEvent(transferEventsABI)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expectedSig, pe.Sig, "Test index %d failed", idx)
+		assert.Contains(t, pe.ParsedData, test.expectedKey, "Test index %d failed", idx)
+		assert.Nil(t, pe.Candidates, "Test index %d failed", idx)
+	}
+}
+
+func TestParsedEvent_ParseEvent_Ambiguous(t *testing.T) {
+	rawABI := `[
+		{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+			{"name":"from","type":"address","indexed":true},
+			{"name":"to","type":"address","indexed":true},
+			{"name":"value","type":"uint256","indexed":false}]},
+		{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+			{"name":"src","type":"address","indexed":true},
+			{"name":"dst","type":"address","indexed":true},
+			{"name":"wad","type":"uint256","indexed":false}]}
+	]`
+	pe := &ParsedEvent{
+		RawEvent: &Event{
+			Topics: []Hash{
+				NewHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+				NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+				NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab"),
+			},
+			Data: NewHexData(word("3e8")),
+		},
+	}
+
+	err := pe.ParseEvent(rawABI)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", pe.Sig)
+	assert.Nil(t, pe.ParsedData)
+	if assert.Len(t, pe.Candidates, 2) {
+		assert.Equal(t, "event Transfer(address from,address to,uint256 value)", pe.Candidates[0].Sig)
+		assert.Equal(t, "event Transfer(address src,address dst,uint256 wad)", pe.Candidates[1].Sig)
+		assert.EqualValues(t, big.NewInt(1000), pe.Candidates[1].ParsedData["wad"])
+	}
+}
+
+func TestParsedEvent_ParseEvent_InvalidData(t *testing.T) {
+	pe := &ParsedEvent{
+		RawEvent: &Event{
+			Topics: []Hash{
+				NewHash("ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+				NewHash("1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+				NewHash("9d13c6d3afe1721beef56b55d303b09e021e27ab"),
+			},
+			Data: NewHexData(word("3e8") + word("1")),
+		},
+	}
+
+	err := pe.ParseEvent(transferEventsABI)
+
+	assert.EqualError(t, err, "abi: cannot decode Transfer at offset 32: expected 32 bytes of data, got 64")
+	assert.Nil(t, pe.ParsedData)
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/ConsenSys/quorum-go-utils/rlp"
+)
+
+// EncodeRLP encodes the address as a 20 byte string. An empty address is encoded as an empty string, as it is for the
+// recipient of a transaction that creates a contract
+func (addr Address) EncodeRLP(w io.Writer) error {
+	if addr == "" {
+		return rlp.Encode(w, []byte{})
+	}
+	bytes, err := parseHexBytes(string(addr))
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %s", string(addr), err.Error())
+	}
+	if len(bytes) != 20 {
+		return fmt.Errorf("invalid address %q: expected 20 bytes but got %d", string(addr), len(bytes))
+	}
+	return rlp.Encode(w, bytes)
+}
+
+// DecodeRLP decodes a 20 byte string as an address, or an empty string as an empty address
+func (addr *Address) DecodeRLP(s *rlp.Stream) error {
+	bytes, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	if len(bytes) != 0 && len(bytes) != 20 {
+		return fmt.Errorf("invalid address 0x%x: expected 20 bytes but got %d", bytes, len(bytes))
+	}
+	*addr = Address(hex.EncodeToString(bytes))
+	return nil
+}
+
+// EncodeRLP encodes the hash as a 32 byte string
+func (hsh Hash) EncodeRLP(w io.Writer) error {
+	bytes, err := parseHexBytes(string(hsh))
+	if err != nil {
+		return fmt.Errorf("invalid hash %q: %s", string(hsh), err.Error())
+	}
+	if len(bytes) != 32 {
+		return fmt.Errorf("invalid hash %q: expected 32 bytes but got %d", string(hsh), len(bytes))
+	}
+	return rlp.Encode(w, bytes)
+}
+
+// DecodeRLP decodes a 32 byte string as a hash
+func (hsh *Hash) DecodeRLP(s *rlp.Stream) error {
+	bytes, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	if len(bytes) != 32 {
+		return fmt.Errorf("invalid hash 0x%x: expected 32 bytes but got %d", bytes, len(bytes))
+	}
+	*hsh = Hash(hex.EncodeToString(bytes))
+	return nil
+}
+
+// EncodeRLP encodes the data as a byte string
+func (data HexData) EncodeRLP(w io.Writer) error {
+	bytes, err := parseHexBytes(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid hex data %q: %s", string(data), err.Error())
+	}
+	return rlp.Encode(w, bytes)
+}
+
+// DecodeRLP decodes a byte string as data
+func (data *HexData) DecodeRLP(s *rlp.Stream) error {
+	bytes, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	*data = HexData(hex.EncodeToString(bytes))
+	return nil
+}
+
+// EncodeRLP encodes the integer in the same way as a big.Int, so negative integers can't be encoded
+func (num HexBig) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, num.ToInt())
+}
+
+// DecodeRLP decodes an integer of any size
+func (num *HexBig) DecodeRLP(s *rlp.Stream) error {
+	i, err := s.BigInt()
+	if err != nil {
+		return err
+	}
+	*num = NewHexBig(i)
+	return nil
+}
+
+// CreateAddress gives the address of the contract created by a transaction from the given account with the given
+// nonce, which is the last 20 bytes of the keccak256 hash of the RLP list [from, nonce]
+func CreateAddress(from Address, nonce uint64) (Address, error) {
+	encoded, err := rlp.EncodeToBytes([]interface{}{from, nonce})
+	if err != nil {
+		return "", err
+	}
+	return Address(hex.EncodeToString(hash(string(encoded))[12:])), nil
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ConsenSys/quorum-go-utils/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeRLP(t *testing.T) {
+	testMatrix := []struct {
+		val      interface{}
+		expected string
+	}{
+		{NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"), "941932c48b2bf8102ba33b4a6b545c32236e342f34"},
+		{Address(""), "80"},
+		{NewHash("0x01"), "a00000000000000000000000000000000000000000000000000000000000000001"},
+		{HexData("646f67"), "83646f67"},
+		{HexData(""), "80"},
+		{HexData("01"), "01"},
+		{NewHexBig(big.NewInt(1024)), "820400"},
+		{HexBig{}, "80"},
+		{HexNumber(15), "0f"},
+		{[]interface{}{Address(""), HexData("")}, "c28080"},
+	}
+
+	for idx, test := range testMatrix {
+		result, err := rlp.EncodeToBytes(test.val)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, hex.EncodeToString(result), "Test index %d failed", idx)
+	}
+}
+
+func TestEncodeRLP_Errors(t *testing.T) {
+	testMatrix := []struct {
+		val           interface{}
+		expectedError string
+	}{
+		{Address("1932"), `invalid address "1932": expected 20 bytes but got 2`},
+		{Address("zz"), `invalid address "zz": invalid hex digit 'z'`},
+		{Hash("01"), `invalid hash "01": expected 32 bytes but got 1`},
+		{HexData("123"), `invalid hex data "123": odd number of hex digits`},
+		{NewHexBig(big.NewInt(-1)), "rlp: cannot encode negative integer -1"},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := rlp.EncodeToBytes(test.val)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestDecodeRLP(t *testing.T) {
+	type transaction struct {
+		Nonce HexNumber
+		To    Address
+		Value HexBig
+		Data  HexData
+		Hash  Hash `rlp:"optional"`
+	}
+
+	input, _ := hex.DecodeString("dd0194" + "1932c48b2bf8102ba33b4a6b545c32236e342f34" + "8203e8" + "83646f67")
+	var tx transaction
+	err := rlp.DecodeBytes(input, &tx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, transaction{
+		Nonce: 1,
+		To:    NewAddress("1932c48b2bf8102ba33b4a6b545c32236e342f34"),
+		Value: NewHexBig(big.NewInt(1000)),
+		Data:  HexData("646f67"),
+	}, tx)
+
+	encoded, err := rlp.EncodeToBytes(tx)
+	assert.Nil(t, err)
+	assert.Equal(t, input, encoded)
+
+	//a contract creation has no recipient
+	input, _ = hex.DecodeString("c4018080" + "80")
+	err = rlp.DecodeBytes(input, &tx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, transaction{Nonce: 1}, tx)
+}
+
+func TestDecodeRLP_Errors(t *testing.T) {
+	var addr Address
+	var hsh Hash
+	var num HexBig
+
+	testMatrix := []struct {
+		input         string
+		ptr           interface{}
+		expectedError string
+	}{
+		{"821932", &addr, "invalid address 0x1932: expected 20 bytes but got 2, decoding into types.Address"},
+		{"c0", &addr, "rlp: expected string or byte, decoding into types.Address"},
+		{"80", &hsh, "invalid hash 0x: expected 32 bytes but got 0, decoding into types.Hash"},
+		{"820001", &num, "rlp: non-canonical integer (leading zero bytes), decoding into types.HexBig"},
+	}
+
+	for idx, test := range testMatrix {
+		input, _ := hex.DecodeString(test.input)
+		err := rlp.DecodeBytes(input, test.ptr)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+func TestCreateAddress(t *testing.T) {
+	from := NewAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+	testMatrix := []struct {
+		nonce    uint64
+		expected Address
+	}{
+		{0, NewAddress("0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d")},
+		{1, NewAddress("0x343c43a37d37dff08ae8c4a11544c718abb4fcf8")},
+		{2, NewAddress("0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91")},
+	}
+
+	for idx, test := range testMatrix {
+		result, err := CreateAddress(from, test.nonce)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, result, "Test index %d failed", idx)
+	}
+
+	_, err := CreateAddress(Address("1932"), 0)
+	assert.EqualError(t, err, `invalid address "1932": expected 20 bytes but got 2`)
+}
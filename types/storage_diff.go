@@ -0,0 +1,253 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// StorageChangeKind is how a state variable, or a part of one, changed
+type StorageChangeKind string
+
+const (
+	StorageAdded    StorageChangeKind = "added"
+	StorageRemoved  StorageChangeKind = "removed"
+	StorageModified StorageChangeKind = "modified"
+)
+
+// StorageChange is a change to a state variable, or to a struct member, array element or mapping entry within one,
+// which is given by its Path, e.g. "balances[0x1932c48b2bf8102ba33b4a6b545c32236e342f34].amount". Added values only
+// have a NewValue, and removed values only have an OldValue. Integers are marshalled as decimal strings, see MarshalJSON
+type StorageChange struct {
+	Kind     StorageChangeKind `json:"kind"`
+	Path     string            `json:"path"`
+	Type     string            `json:"type,omitempty"`
+	OldValue interface{}       `json:"oldValue,omitempty"`
+	NewValue interface{}       `json:"newValue,omitempty"`
+}
+
+// MarshalJSON marshals the change with its integer values as decimal strings, including those within structs, arrays
+// and mappings, so that uint256 values such as balances don't lose precision in JSON consumers such as JavaScript
+func (change StorageChange) MarshalJSON() ([]byte, error) {
+	type plain StorageChange
+	out := plain(change)
+	out.OldValue = jsonStorageValue(change.OldValue)
+	out.NewValue = jsonStorageValue(change.NewValue)
+	return json.Marshal(out)
+}
+
+//jsonStorageValue copies a decoded value with its integers, and the integer keys of mappings, as decimal strings
+func jsonStorageValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case []*StorageItem:
+		items := make([]*StorageItem, len(v))
+		for i, item := range v {
+			converted := *item
+			converted.Value = jsonStorageValue(item.Value)
+			items[i] = &converted
+		}
+		return items
+	case []interface{}:
+		elements := make([]interface{}, len(v))
+		for i, element := range v {
+			elements[i] = jsonStorageValue(element)
+		}
+		return elements
+	case []*StorageMappingEntry:
+		entries := make([]*StorageMappingEntry, len(v))
+		for i, entry := range v {
+			entries[i] = &StorageMappingEntry{Key: jsonStorageValue(entry.Key), Value: jsonStorageValue(entry.Value)}
+		}
+		return entries
+	}
+	return value
+}
+
+// StorageDiff is the set of changes to the storage of a contract made by a block
+type StorageDiff struct {
+	Address     Address          `json:"address"`
+	BlockNumber uint64           `json:"blockNumber"`
+	Changes     []*StorageChange `json:"changes"`
+}
+
+/*
+DiffStorage compares storage decoded by DecodeStorage or DecodeStorageWithKeys before and after a change, such as the
+storage at blocks N-1 and N.
+
+Structs, arrays and mappings are compared member by member, element by element and entry by entry, so that only the
+parts that changed are given. Elements past the end of the shorter array, and mapping entries that are only in one
+of the two, are added or removed. The changes are in the order of the variables, with the removed entries of a
+mapping before those that were added.
+*/
+func DiffStorage(before []*StorageItem, after []*StorageItem) []*StorageChange {
+	changes := []*StorageChange{}
+	diffItems(&changes, "", before, after)
+	return changes
+}
+
+//diffItems compares variables or struct members, matching them by name and slot
+func diffItems(changes *[]*StorageChange, prefix string, before []*StorageItem, after []*StorageItem) {
+	type itemKey struct {
+		name  string
+		index uint64
+	}
+	remaining := make(map[itemKey]*StorageItem, len(after))
+	for _, item := range after {
+		remaining[itemKey{item.VarName, item.VarIndex}] = item
+	}
+
+	for _, old := range before {
+		key := itemKey{old.VarName, old.VarIndex}
+		path := prefix + old.VarName
+		if prefix != "" {
+			path = prefix + "." + old.VarName
+		}
+
+		current, ok := remaining[key]
+		if !ok {
+			*changes = append(*changes, &StorageChange{Kind: StorageRemoved, Path: path, Type: old.VarType, OldValue: old.Value})
+			continue
+		}
+		delete(remaining, key)
+		diffValues(changes, path, old.VarType, old.Value, current.Value)
+	}
+
+	for _, item := range after {
+		if _, ok := remaining[itemKey{item.VarName, item.VarIndex}]; !ok {
+			continue
+		}
+		path := prefix + item.VarName
+		if prefix != "" {
+			path = prefix + "." + item.VarName
+		}
+		*changes = append(*changes, &StorageChange{Kind: StorageAdded, Path: path, Type: item.VarType, NewValue: item.Value})
+	}
+}
+
+//diffValues compares two values of the same variable, going into structs, arrays and mappings
+func diffValues(changes *[]*StorageChange, path string, typeLabel string, old interface{}, current interface{}) {
+	switch oldValue := old.(type) {
+	case []*StorageItem:
+		if currentValue, ok := current.([]*StorageItem); ok {
+			diffItems(changes, path, oldValue, currentValue)
+			return
+		}
+
+	case []interface{}:
+		if currentValue, ok := current.([]interface{}); ok {
+			elementType := arrayElementLabel(typeLabel)
+			for i := 0; i < len(oldValue) || i < len(currentValue); i++ {
+				elementPath := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(currentValue):
+					*changes = append(*changes, &StorageChange{Kind: StorageRemoved, Path: elementPath, Type: elementType, OldValue: oldValue[i]})
+				case i >= len(oldValue):
+					*changes = append(*changes, &StorageChange{Kind: StorageAdded, Path: elementPath, Type: elementType, NewValue: currentValue[i]})
+				default:
+					diffValues(changes, elementPath, elementType, oldValue[i], currentValue[i])
+				}
+			}
+			return
+		}
+
+	case []*StorageMappingEntry:
+		if currentValue, ok := current.([]*StorageMappingEntry); ok {
+			keyType, valueType := mappingLabels(typeLabel)
+			diffMappings(changes, path, keyType, valueType, oldValue, currentValue)
+			return
+		}
+	}
+
+	if !storageValuesEqual(old, current) {
+		*changes = append(*changes, &StorageChange{Kind: StorageModified, Path: path, Type: typeLabel, OldValue: old, NewValue: current})
+	}
+}
+
+//diffMappings compares the entries of a mapping that were found, matching them by key
+func diffMappings(changes *[]*StorageChange, path string, keyType string, valueType string, before []*StorageMappingEntry, after []*StorageMappingEntry) {
+	remaining := make(map[string]*StorageMappingEntry, len(after))
+	for _, entry := range after {
+		remaining[storageKeyString(keyType, entry.Key)] = entry
+	}
+
+	for _, old := range before {
+		key := storageKeyString(keyType, old.Key)
+		entryPath := path + "[" + key + "]"
+
+		current, ok := remaining[key]
+		if !ok {
+			*changes = append(*changes, &StorageChange{Kind: StorageRemoved, Path: entryPath, Type: valueType, OldValue: old.Value})
+			continue
+		}
+		delete(remaining, key)
+		diffValues(changes, entryPath, valueType, old.Value, current.Value)
+	}
+
+	for _, entry := range after {
+		key := storageKeyString(keyType, entry.Key)
+		if _, ok := remaining[key]; ok {
+			*changes = append(*changes, &StorageChange{Kind: StorageAdded, Path: path + "[" + key + "]", Type: valueType, NewValue: entry.Value})
+		}
+	}
+}
+
+//storageValuesEqual compares two decoded values that aren't a struct, array or mapping
+func storageValuesEqual(old interface{}, current interface{}) bool {
+	oldInt, oldIsInt := old.(*big.Int)
+	currentInt, currentIsInt := current.(*big.Int)
+	if oldIsInt || currentIsInt {
+		return oldIsInt && currentIsInt && oldInt.Cmp(currentInt) == 0
+	}
+	//a mapping that wasn't decoded, or a struct, array or mapping against something else
+	switch old.(type) {
+	case []*StorageItem, []interface{}, []*StorageMappingEntry:
+		return false
+	}
+	switch current.(type) {
+	case []*StorageItem, []interface{}, []*StorageMappingEntry:
+		return false
+	}
+	return old == current
+}
+
+//storageKeyString gives a mapping key as it is written in a path, see SolidityStorageDocument.Locate
+func storageKeyString(keyType string, key interface{}) string {
+	switch k := key.(type) {
+	case *big.Int:
+		return k.String()
+	case string:
+		if keyType == "string" {
+			return strconv.Quote(k)
+		}
+		return k
+	}
+	return fmt.Sprint(key)
+}
+
+//arrayElementLabel gives the type of the elements of an array, e.g. "uint256" for "uint256[3]"
+func arrayElementLabel(label string) string {
+	if index := strings.LastIndex(label, "["); index > 0 && strings.HasSuffix(label, "]") {
+		return label[:index]
+	}
+	return ""
+}
+
+//mappingLabels gives the types of the keys and values of a mapping, e.g. "address" and "uint256" for
+//"mapping(address => uint256)". The key is always an elementary type, so the first arrow separates it from the value
+func mappingLabels(label string) (string, string) {
+	if !strings.HasPrefix(label, "mapping(") || !strings.HasSuffix(label, ")") {
+		return "", ""
+	}
+	inner := label[len("mapping(") : len(label)-1]
+	if index := strings.Index(inner, " => "); index >= 0 {
+		return inner[:index], inner[index+len(" => "):]
+	}
+	return "", ""
+}
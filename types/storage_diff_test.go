@@ -0,0 +1,152 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const diffAccount = "0x1932c48b2bf8102ba33b4a6b545c32236e342f34"
+
+//diffStorage is storage decoded with the mappingLayout, with the given balance and accounts
+func diffStorage(balance int64, frozen bool, accounts ...int64) []*StorageItem {
+	account := func(amount int64, frozen bool) []*StorageItem {
+		return []*StorageItem{
+			{VarName: "amount", VarIndex: 0, VarType: "uint256", Value: big.NewInt(amount)},
+			{VarName: "frozen", VarIndex: 1, VarType: "bool", Value: frozen},
+		}
+	}
+	var balances []*StorageMappingEntry
+	if balance > 0 {
+		balances = []*StorageMappingEntry{{Key: diffAccount, Value: []*StorageMappingEntry{{Key: big.NewInt(7), Value: account(balance, frozen)}}}}
+	}
+	accountArray := []interface{}{}
+	for _, amount := range accounts {
+		accountArray = append(accountArray, account(amount, false))
+	}
+	return []*StorageItem{
+		{VarName: "balances", VarIndex: 0, VarType: "mapping(address => mapping(uint256 => struct Token.Account))", Value: balances},
+		{VarName: "names", VarIndex: 1, VarType: "mapping(string => uint256)"},
+		{VarName: "accounts", VarIndex: 3, VarType: "struct Token.Account[]", Value: accountArray},
+	}
+}
+
+func TestDiffStorage(t *testing.T) {
+	testMatrix := []struct {
+		before          []*StorageItem
+		after           []*StorageItem
+		expectedChanges []*StorageChange
+	}{
+		{diffStorage(5, false, 1), diffStorage(5, false, 1), []*StorageChange{}},
+		{
+			diffStorage(5, false, 1), diffStorage(6, true, 1),
+			[]*StorageChange{
+				{Kind: StorageModified, Path: "balances[" + diffAccount + "][7].amount", Type: "uint256", OldValue: big.NewInt(5), NewValue: big.NewInt(6)},
+				{Kind: StorageModified, Path: "balances[" + diffAccount + "][7].frozen", Type: "bool", OldValue: false, NewValue: true},
+			},
+		},
+		{
+			diffStorage(0, false, 1), diffStorage(5, false, 1),
+			[]*StorageChange{
+				{Kind: StorageAdded, Path: "balances[" + diffAccount + "]", Type: "mapping(uint256 => struct Token.Account)", NewValue: diffStorage(5, false)[0].Value.([]*StorageMappingEntry)[0].Value},
+			},
+		},
+		{
+			diffStorage(5, false, 1), diffStorage(0, false, 1),
+			[]*StorageChange{
+				{Kind: StorageRemoved, Path: "balances[" + diffAccount + "]", Type: "mapping(uint256 => struct Token.Account)", OldValue: diffStorage(5, false)[0].Value.([]*StorageMappingEntry)[0].Value},
+			},
+		},
+		{
+			diffStorage(0, false, 1, 2), diffStorage(0, false, 3, 2, 4),
+			[]*StorageChange{
+				{Kind: StorageModified, Path: "accounts[0].amount", Type: "uint256", OldValue: big.NewInt(1), NewValue: big.NewInt(3)},
+				{Kind: StorageAdded, Path: "accounts[2]", Type: "struct Token.Account", NewValue: diffStorage(0, false, 4)[2].Value.([]interface{})[0]},
+			},
+		},
+		{
+			diffStorage(0, false, 1, 2), diffStorage(0, false, 1),
+			[]*StorageChange{
+				{Kind: StorageRemoved, Path: "accounts[1]", Type: "struct Token.Account", OldValue: diffStorage(0, false, 2)[2].Value.([]interface{})[0]},
+			},
+		},
+		//variables are matched by name and slot, e.g. after an upgrade
+		{
+			diffStorage(0, false)[2:], diffStorage(0, false)[1:2],
+			[]*StorageChange{
+				{Kind: StorageRemoved, Path: "accounts", Type: "struct Token.Account[]", OldValue: []interface{}{}},
+				{Kind: StorageAdded, Path: "names", Type: "mapping(string => uint256)"},
+			},
+		},
+	}
+
+	for idx, test := range testMatrix {
+		changes := DiffStorage(test.before, test.after)
+
+		assert.Equal(t, test.expectedChanges, changes, "Test index %d failed", idx)
+	}
+}
+
+func TestDiffStorage_Decoded(t *testing.T) {
+	document := testMappingLayout(t)
+	keys := NewMappingKeys()
+	keys.Add("0x01ffc9a7")
+	interfaceSlot := keccakSlot("01ffc9a7"+strings.Repeat("0", 56)+word("2"), 0)
+
+	before, err := document.DecodeStorageWithKeys(map[Hash]string{interfaceSlot: "01", NewHash("4"): "0201"}, keys)
+	assert.Nil(t, err)
+	after, err := document.DecodeStorageWithKeys(map[Hash]string{NewHash("4"): "0203"}, keys)
+	assert.Nil(t, err)
+
+	changes := DiffStorage(before, after)
+
+	assert.Equal(t, []*StorageChange{
+		{Kind: StorageRemoved, Path: "interfaces[0x01ffc9a7]", Type: "bool", OldValue: true},
+		{Kind: StorageModified, Path: "small[0]", Type: "uint8", OldValue: big.NewInt(1), NewValue: big.NewInt(3)},
+	}, changes)
+}
+
+func TestStorageDiff_JSON(t *testing.T) {
+	diff := StorageDiff{
+		Address:     NewAddress(diffAccount),
+		BlockNumber: 10,
+		Changes:     DiffStorage(diffStorage(5, false, 1), diffStorage(6, false)),
+	}
+
+	data, err := json.Marshal(diff)
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"address": "0x1932c48b2bf8102ba33b4a6b545c32236e342f34",
+		"blockNumber": 10,
+		"changes": [
+			{"kind": "modified", "path": "balances[0x1932c48b2bf8102ba33b4a6b545c32236e342f34][7].amount", "type": "uint256", "oldValue": "5", "newValue": "6"},
+			{"kind": "removed", "path": "accounts[0]", "type": "struct Token.Account", "oldValue": [
+				{"name": "amount", "index": 0, "type": "uint256", "value": "1"},
+				{"name": "frozen", "index": 1, "type": "bool", "value": false}
+			]}
+		]
+	}`, string(data))
+}
+
+func TestStorageChange_MarshalJSON_LargeIntegers(t *testing.T) {
+	large, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	change := &StorageChange{
+		Kind:     StorageModified,
+		Path:     "balances",
+		Type:     "mapping(uint256 => uint256[])",
+		OldValue: []*StorageMappingEntry{{Key: large, Value: []interface{}{big.NewInt(-1), large}}},
+		NewValue: []*StorageMappingEntry{},
+	}
+
+	data, err := json.Marshal(change)
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"kind": "modified", "path": "balances", "type": "mapping(uint256 => uint256[])",
+		"oldValue": [{"key": "`+large.String()+`", "value": ["-1", "`+large.String()+`"]}], "newValue": []}`, string(data))
+	//the change itself is left as it is
+	assert.Equal(t, large, change.OldValue.([]*StorageMappingEntry)[0].Key)
+}
@@ -0,0 +1,281 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	//maxStorageArrayLength is the longest array that is decoded, as a corrupt length of a dynamic array, or the length
+	//of a fixed size array in a corrupt layout, would never finish
+	maxStorageArrayLength = 1 << 16
+	//maxStorageElements is the most array elements that are decoded in total, as the length of each level of nested
+	//arrays is limited separately
+	maxStorageElements = 1 << 20
+	//maxStorageBytesLength is the longest string or bytes that is decoded
+	maxStorageBytesLength = 1 << 20
+)
+
+var (
+	fixedArrayLength = regexp.MustCompile(`\)(\d+)_storage$`)
+	slotModulus      = new(big.Int).Lsh(big.NewInt(1), 256)
+)
+
+/*
+DecodeStorage decodes the raw storage of a contract, as given by client.DumpAddress, into its state variables using
+the storage layout output by solc. The items are in the same order as the variables of the layout.
+
+Values are decoded as follows:
+  - integers and enums are a *big.Int
+  - bools are a bool
+  - addresses and contracts are a "0x" prefixed hex string
+  - fixed size bytes, functions and user defined value types are a "0x" prefixed hex string
+  - strings are a string, and bytes are a "0x" prefixed hex string
+  - structs are a []*StorageItem, one per member, where the index of a member is its slot within the struct
+  - fixed size and dynamic arrays are a []interface{} of the values of their elements
+  - mappings have no value, as the keys that are set can't be found from the storage alone, see DecodeStorageWithKeys
+
+Slots that aren't in the storage are zero, as they are left out of the dump.
+*/
+func (document *SolidityStorageDocument) DecodeStorage(storage map[Hash]string) ([]*StorageItem, error) {
+	return document.DecodeStorageWithKeys(storage, nil)
+}
+
+// DecodeStorageWithKeys decodes the storage in the same way as DecodeStorage, but also lists the entries of
+// mappings whose keys are one of the candidate keys, as a []*StorageMappingEntry ordered by key. An entry is
+// listed if any of the slots of its value are set
+func (document *SolidityStorageDocument) DecodeStorageWithKeys(storage map[Hash]string, keys *MappingKeys) ([]*StorageItem, error) {
+	reader, err := newStorageReader(document, storage, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*StorageItem, 0, len(document.Storage))
+	for _, entry := range document.Storage {
+		item, err := reader.decodeEntry(entry, new(big.Int))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+//storageReader reads the values of variables from the raw storage of a contract
+type storageReader struct {
+	document *SolidityStorageDocument
+	slots    map[Hash][]byte
+	//keys are the candidate keys of mappings, which are only decoded if there are some
+	keys []Hash
+	//elements is the number of array elements that can still be decoded
+	elements uint64
+}
+
+func newStorageReader(document *SolidityStorageDocument, storage map[Hash]string, keys *MappingKeys) (*storageReader, error) {
+	slots := make(map[Hash][]byte, len(storage))
+	for key, value := range storage {
+		//values are given without their leading zeros, which may leave an odd number of digits
+		value = strings.TrimPrefix(value, "0x")
+		if len(value)%2 == 1 {
+			value = "0" + value
+		}
+		decoded, err := hex.DecodeString(value)
+		if err == nil && len(decoded) > 32 {
+			err = errors.New("value is longer than 32 bytes")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of storage slot %s: %s", key.String(), err.Error())
+		}
+
+		word := make([]byte, 32)
+		copy(word[32-len(decoded):], decoded)
+		slots[NewHash(strings.ToLower(string(key)))] = word
+	}
+	reader := &storageReader{document: document, slots: slots, elements: maxStorageElements}
+	if keys != nil {
+		reader.keys = keys.sorted()
+	}
+	return reader, nil
+}
+
+//decodeEntry decodes a variable, or struct member, whose slot is relative to the given base slot
+func (reader *storageReader) decodeEntry(entry SolidityStorageEntry, base *big.Int) (*StorageItem, error) {
+	typ, err := reader.storageType(entry.Type)
+	if err != nil {
+		return nil, err
+	}
+	value, err := reader.decode(entry.Type, addSlot(base, new(big.Int).SetUint64(entry.Slot)), entry.Offset)
+	if err != nil {
+		return nil, errors.New(entry.Label + ": " + err.Error())
+	}
+	return &StorageItem{VarName: entry.Label, VarIndex: entry.Slot, VarType: typ.Label, Value: value}, nil
+}
+
+func (reader *storageReader) storageType(typeID string) (SolidityTypeEntry, error) {
+	typ, ok := reader.document.Types[typeID]
+	if !ok {
+		return SolidityTypeEntry{}, errors.New("unknown storage type: " + typeID)
+	}
+	return typ, nil
+}
+
+//decode decodes the value of the given type that starts at the offset in the slot
+func (reader *storageReader) decode(typeID string, slot *big.Int, offset uint64) (interface{}, error) {
+	typ, err := reader.storageType(typeID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ.Encoding {
+	case "mapping":
+		if reader.keys == nil {
+			return nil, nil
+		}
+		return reader.decodeMapping(typ, slot)
+	case "bytes":
+		return reader.decodeBytes(typeID, slot)
+	case "dynamic_array":
+		length := new(big.Int).SetBytes(reader.word(slot))
+		if !length.IsUint64() || length.Uint64() > maxStorageArrayLength {
+			return nil, fmt.Errorf("array length %s is too long to decode", length.String())
+		}
+		return reader.decodeArray(typ.Base, dataSlot(slot), length.Uint64())
+	case "inplace":
+		if len(typ.Members) > 0 {
+			members := make([]*StorageItem, 0, len(typ.Members))
+			for _, member := range typ.Members {
+				item, err := reader.decodeEntry(member, slot)
+				if err != nil {
+					return nil, err
+				}
+				members = append(members, item)
+			}
+			return members, nil
+		}
+		if typ.Base != "" {
+			length, err := fixedLength(typeID, typ)
+			if err != nil {
+				return nil, err
+			}
+			if length > maxStorageArrayLength {
+				return nil, fmt.Errorf("array length %d is too long to decode", length)
+			}
+			return reader.decodeArray(typ.Base, slot, length)
+		}
+		if offset+typ.NumberOfBytes > 32 {
+			return nil, fmt.Errorf("%d bytes at offset %d don't fit in a slot", typ.NumberOfBytes, offset)
+		}
+		word := reader.word(slot)
+		return storageValue(typeID, word[32-offset-typ.NumberOfBytes:32-offset]), nil
+	}
+	return nil, errors.New("unknown storage encoding: " + typ.Encoding)
+}
+
+//decodeArray decodes the elements of an array that start at the given slot
+func (reader *storageReader) decodeArray(baseTypeID string, slot *big.Int, length uint64) ([]interface{}, error) {
+	base, err := reader.storageType(baseTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if base.NumberOfBytes == 0 {
+		return nil, errors.New("array element " + baseTypeID + " has no size")
+	}
+	if length > reader.elements {
+		return nil, fmt.Errorf("more than %d array elements to decode", maxStorageElements)
+	}
+	reader.elements -= length
+
+	elements := make([]interface{}, length)
+	for i := uint64(0); i < length; i++ {
+		elementSlot, offset := elementLocation(base, slot, i)
+		element, err := reader.decode(baseTypeID, elementSlot, offset)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %s", i, err.Error())
+		}
+		elements[i] = element
+	}
+	return elements, nil
+}
+
+//decodeBytes decodes a string or bytes. If it is shorter than 32 bytes, then it is stored in the slot with twice
+//its length in the last byte, otherwise the slot holds twice its length plus one, and it is stored from keccak(slot)
+func (reader *storageReader) decodeBytes(typeID string, slot *big.Int) (interface{}, error) {
+	word := reader.word(slot)
+
+	var content []byte
+	if word[31]&1 == 0 {
+		length := int(word[31] / 2)
+		if length > 31 {
+			return nil, fmt.Errorf("short length %d is more than 31 bytes", length)
+		}
+		content = word[:length]
+	} else {
+		length := new(big.Int).Rsh(new(big.Int).SetBytes(word), 1)
+		if !length.IsUint64() || length.Uint64() > maxStorageBytesLength {
+			return nil, fmt.Errorf("length %s is too long to decode", length.String())
+		}
+		content = make([]byte, 0, length.Uint64()+31)
+		start := dataSlot(slot)
+		for i := uint64(0); uint64(len(content)) < length.Uint64(); i++ {
+			content = append(content, reader.word(addSlot(start, new(big.Int).SetUint64(i)))...)
+		}
+		content = content[:length.Uint64()]
+	}
+
+	if strings.HasPrefix(typeID, "t_string") {
+		return string(content), nil
+	}
+	return "0x" + hex.EncodeToString(content), nil
+}
+
+//word gives the 32 byte value of the slot, which is zero if it isn't in the storage
+func (reader *storageReader) word(slot *big.Int) []byte {
+	if word, ok := reader.slots[slotHash(slot)]; ok {
+		return word
+	}
+	return make([]byte, 32)
+}
+
+//storageValue decodes a value type from the bytes it takes up in its slot
+func storageValue(typeID string, raw []byte) interface{} {
+	switch {
+	case typeID == "t_bool":
+		return raw[len(raw)-1] != 0
+	case strings.HasPrefix(typeID, "t_address"), strings.HasPrefix(typeID, "t_contract"):
+		return "0x" + hex.EncodeToString(raw)
+	case strings.HasPrefix(typeID, "t_uint"), strings.HasPrefix(typeID, "t_enum"):
+		return ParseUint(raw)
+	case strings.HasPrefix(typeID, "t_int"):
+		return ParseInt(raw)
+	}
+	return "0x" + hex.EncodeToString(raw)
+}
+
+//fixedLength gives the number of elements of a fixed size array, e.g. 3 for t_array(t_uint256)3_storage
+func fixedLength(typeID string, typ SolidityTypeEntry) (uint64, error) {
+	if match := fixedArrayLength.FindStringSubmatch(typeID); match != nil {
+		return strconv.ParseUint(match[1], 10, 64)
+	}
+	return 0, errors.New("unable to find the length of array " + typ.Label)
+}
+
+//slotHash gives the storage key of the slot
+func slotHash(slot *big.Int) Hash {
+	return Hash(fmt.Sprintf("%064x", slot))
+}
+
+//dataSlot gives the slot that the contents of a dynamic array, string or bytes start at, which is keccak(slot)
+func dataSlot(slot *big.Int) *big.Int {
+	key, _ := hex.DecodeString(string(slotHash(slot)))
+	return new(big.Int).SetBytes(hash(string(key)))
+}
+
+//addSlot adds to a slot, wrapping around at 2^256 like the EVM
+func addSlot(slot *big.Int, n *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Add(slot, n), slotModulus)
+}
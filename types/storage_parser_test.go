@@ -0,0 +1,198 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+The storage layout output by solc for:
+
+	contract Storage {
+		struct Point { uint128 x; uint128 y; address who; }
+
+		uint8 small;
+		bool flag;
+		address owner;
+		int16 negative;
+		uint256 total;
+		string name;
+		string description;
+		bytes data;
+		Point point;
+		uint16[3] fixedArray;
+		uint256[] dynamicArray;
+		Point[] points;
+		mapping(address => uint256) balances;
+	}
+*/
+const storageLayout = `{
+	"storage": [
+		{"label": "small", "offset": 0, "slot": "0", "type": "t_uint8"},
+		{"label": "flag", "offset": 1, "slot": "0", "type": "t_bool"},
+		{"label": "owner", "offset": 2, "slot": "0", "type": "t_address"},
+		{"label": "negative", "offset": 22, "slot": "0", "type": "t_int16"},
+		{"label": "total", "offset": 0, "slot": "1", "type": "t_uint256"},
+		{"label": "name", "offset": 0, "slot": "2", "type": "t_string_storage"},
+		{"label": "description", "offset": 0, "slot": "3", "type": "t_string_storage"},
+		{"label": "data", "offset": 0, "slot": "4", "type": "t_bytes_storage"},
+		{"label": "point", "offset": 0, "slot": "5", "type": "t_struct(Point)8_storage"},
+		{"label": "fixedArray", "offset": 0, "slot": "7", "type": "t_array(t_uint16)3_storage"},
+		{"label": "dynamicArray", "offset": 0, "slot": "8", "type": "t_array(t_uint256)dyn_storage"},
+		{"label": "points", "offset": 0, "slot": "9", "type": "t_array(t_struct(Point)8_storage)dyn_storage"},
+		{"label": "balances", "offset": 0, "slot": "10", "type": "t_mapping(t_address,t_uint256)"}
+	],
+	"types": {
+		"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+		"t_array(t_struct(Point)8_storage)dyn_storage": {"base": "t_struct(Point)8_storage", "encoding": "dynamic_array", "label": "struct Storage.Point[]", "numberOfBytes": "32"},
+		"t_array(t_uint16)3_storage": {"base": "t_uint16", "encoding": "inplace", "label": "uint16[3]", "numberOfBytes": "32"},
+		"t_array(t_uint256)dyn_storage": {"base": "t_uint256", "encoding": "dynamic_array", "label": "uint256[]", "numberOfBytes": "32"},
+		"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+		"t_bytes_storage": {"encoding": "bytes", "label": "bytes", "numberOfBytes": "32"},
+		"t_int16": {"encoding": "inplace", "label": "int16", "numberOfBytes": "2"},
+		"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+		"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+		"t_struct(Point)8_storage": {"encoding": "inplace", "label": "struct Storage.Point", "numberOfBytes": "64", "members": [
+			{"label": "x", "offset": 0, "slot": "0", "type": "t_uint128"},
+			{"label": "y", "offset": 16, "slot": "0", "type": "t_uint128"},
+			{"label": "who", "offset": 0, "slot": "1", "type": "t_address"}
+		]},
+		"t_uint128": {"encoding": "inplace", "label": "uint128", "numberOfBytes": "16"},
+		"t_uint16": {"encoding": "inplace", "label": "uint16", "numberOfBytes": "2"},
+		"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+		"t_uint8": {"encoding": "inplace", "label": "uint8", "numberOfBytes": "1"}
+	}
+}`
+
+const (
+	storageOwner = "1932c48b2bf8102ba33b4a6b545c32236e342f34"
+	storageWho   = "9d13c6d3afe1721beef56b55d303b09e021e27ab"
+)
+
+func testStorageLayout(t *testing.T) *SolidityStorageDocument {
+	var document SolidityStorageDocument
+	assert.Nil(t, json.Unmarshal([]byte(storageLayout), &document))
+	return &document
+}
+
+//slotAt gives the storage key of keccak(slot) + index
+func slotAt(slot int64, index int64) Hash {
+	return slotHash(addSlot(dataSlot(big.NewInt(slot)), big.NewInt(index)))
+}
+
+//testStorage is the storage of the contract in storageLayout, as dumped by debug_dumpAddress
+func testStorage() map[Hash]string {
+	description := strings.Repeat("a long description ", 3)
+	descriptionHex := hex.EncodeToString([]byte(description)) + strings.Repeat("0", 2*(96-len(description)))
+
+	return map[Hash]string{
+		//negative = -2, owner, flag = true, small = 7
+		NewHash("0"): "fffe" + storageOwner + "01" + "07",
+		NewHash("1"): "3e8",
+		NewHash("2"): hex.EncodeToString([]byte("token")) + strings.Repeat("0", 52) + "0a",
+		NewHash("3"): fmt.Sprintf("%x", 2*len(description)+1),
+		slotAt(3, 0): descriptionHex[:64],
+		slotAt(3, 1): descriptionHex[64:128],
+		NewHash("4"): "0102" + strings.Repeat("0", 58) + "04",
+		NewHash("5"): word("2"),
+		NewHash("6"): storageWho,
+		NewHash("7"): "0003" + "0002" + "0001",
+		NewHash("8"): "2",
+		slotAt(8, 0): "0x64",
+		slotAt(8, 1): "c8",
+		NewHash("9"): "1",
+		slotAt(9, 0): fmt.Sprintf("%032x%032x", 6, 5),
+		slotAt(9, 1): storageOwner,
+		NewHash("a"): "",
+	}
+}
+
+func TestSolidityStorageDocument_DecodeStorage(t *testing.T) {
+	document := testStorageLayout(t)
+
+	items, err := document.DecodeStorage(testStorage())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*StorageItem{
+		{VarName: "small", VarIndex: 0, VarType: "uint8", Value: big.NewInt(7)},
+		{VarName: "flag", VarIndex: 0, VarType: "bool", Value: true},
+		{VarName: "owner", VarIndex: 0, VarType: "address", Value: "0x" + storageOwner},
+		{VarName: "negative", VarIndex: 0, VarType: "int16", Value: big.NewInt(-2)},
+		{VarName: "total", VarIndex: 1, VarType: "uint256", Value: big.NewInt(1000)},
+		{VarName: "name", VarIndex: 2, VarType: "string", Value: "token"},
+		{VarName: "description", VarIndex: 3, VarType: "string", Value: strings.Repeat("a long description ", 3)},
+		{VarName: "data", VarIndex: 4, VarType: "bytes", Value: "0x0102"},
+		{VarName: "point", VarIndex: 5, VarType: "struct Storage.Point", Value: []*StorageItem{
+			{VarName: "x", VarIndex: 0, VarType: "uint128", Value: big.NewInt(2)},
+			{VarName: "y", VarIndex: 0, VarType: "uint128", Value: new(big.Int).SetBytes(make([]byte, 16))},
+			{VarName: "who", VarIndex: 1, VarType: "address", Value: "0x" + storageWho},
+		}},
+		{VarName: "fixedArray", VarIndex: 7, VarType: "uint16[3]", Value: []interface{}{big.NewInt(1), big.NewInt(2), big.NewInt(3)}},
+		{VarName: "dynamicArray", VarIndex: 8, VarType: "uint256[]", Value: []interface{}{big.NewInt(100), big.NewInt(200)}},
+		{VarName: "points", VarIndex: 9, VarType: "struct Storage.Point[]", Value: []interface{}{
+			[]*StorageItem{
+				{VarName: "x", VarIndex: 0, VarType: "uint128", Value: big.NewInt(5)},
+				{VarName: "y", VarIndex: 0, VarType: "uint128", Value: big.NewInt(6)},
+				{VarName: "who", VarIndex: 1, VarType: "address", Value: "0x" + storageOwner},
+			},
+		}},
+		{VarName: "balances", VarIndex: 10, VarType: "mapping(address => uint256)"},
+	}, items)
+}
+
+func TestSolidityStorageDocument_DecodeStorageEmpty(t *testing.T) {
+	document := testStorageLayout(t)
+
+	items, err := document.DecodeStorage(nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, items, len(document.Storage))
+	assert.Equal(t, "0x0000000000000000000000000000000000000000", items[2].Value)
+	assert.Equal(t, "", items[5].Value)
+	assert.Equal(t, []interface{}{}, items[10].Value)
+}
+
+func TestSolidityStorageDocument_DecodeStorageErrors(t *testing.T) {
+	testMatrix := []struct {
+		storage       map[Hash]string
+		expectedError string
+	}{
+		{map[Hash]string{NewHash("1"): "xyz"}, "invalid value of storage slot 0x0000000000000000000000000000000000000000000000000000000000000001: encoding/hex: invalid byte: U+0078 'x'"},
+		{map[Hash]string{NewHash("1"): word("1") + "00"}, "invalid value of storage slot 0x0000000000000000000000000000000000000000000000000000000000000001: value is longer than 32 bytes"},
+		{map[Hash]string{NewHash("2"): "40"}, "name: short length 32 is more than 31 bytes"},
+		{map[Hash]string{NewHash("3"): "ffffffffff"}, "description: length 549755813887 is too long to decode"},
+		{map[Hash]string{NewHash("8"): "ffffffff"}, "dynamicArray: array length 4294967295 is too long to decode"},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := testStorageLayout(t).DecodeStorage(test.storage)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+
+	document := testStorageLayout(t)
+	document.Storage = append(document.Storage, SolidityStorageEntry{Label: "unknown", Slot: 11, Type: "t_unknown"})
+	_, err := document.DecodeStorage(nil)
+	assert.EqualError(t, err, "unknown storage type: t_unknown")
+
+	//the length of a fixed size array comes from the layout, so is limited in the same way
+	document = testStorageLayout(t)
+	document.Storage = append(document.Storage, SolidityStorageEntry{Label: "huge", Slot: 11, Type: "t_array(t_uint256)1099511627776_storage"})
+	document.Types["t_array(t_uint256)1099511627776_storage"] = SolidityTypeEntry{Base: "t_uint256", Encoding: "inplace", Label: "uint256[1099511627776]", NumberOfBytes: 1 << 45}
+	_, err = document.DecodeStorage(nil)
+	assert.EqualError(t, err, "huge: array length 1099511627776 is too long to decode")
+
+	//nested arrays share a limit on the total number of elements
+	document = testStorageLayout(t)
+	document.Storage = append(document.Storage, SolidityStorageEntry{Label: "nested", Slot: 11, Type: "t_array(t_array(t_uint256)65536_storage)65536_storage"})
+	document.Types["t_array(t_uint256)65536_storage"] = SolidityTypeEntry{Base: "t_uint256", Encoding: "inplace", Label: "uint256[65536]", NumberOfBytes: 1 << 21}
+	document.Types["t_array(t_array(t_uint256)65536_storage)65536_storage"] = SolidityTypeEntry{Base: "t_array(t_uint256)65536_storage", Encoding: "inplace", Label: "uint256[65536][65536]", NumberOfBytes: 1 << 37}
+	_, err = document.DecodeStorage(nil)
+	assert.EqualError(t, err, "nested: [14]: more than 1048576 array elements to decode")
+}
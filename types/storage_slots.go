@@ -0,0 +1,504 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	storagePathIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`)
+	fixedBytesType        = regexp.MustCompile(`^t_bytes\d+$`)
+)
+
+// StorageLocation is where a state variable, or a part of one such as a mapping entry, array element or struct
+// member, is stored. Values that take up less than a slot start at the Offset, in bytes from the right of the slot
+type StorageLocation struct {
+	Slot   Hash   `json:"slot"`
+	Offset uint64 `json:"offset"`
+	// Type is the identifier of the type in the storage layout, e.g. "t_uint256"
+	Type string `json:"type"`
+}
+
+// StorageMappingEntry is an entry of a mapping, whose key was found from the candidate keys it was decoded with
+type StorageMappingEntry struct {
+	Key   interface{} `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+/*
+Locate gives where the value at the path is stored, such as "balances[0x1932c48b2bf8102ba33b4a6b545c32236e342f34]",
+"allowances[0xabc][0xdef]", "points[3].x" or "names[\"alice\"]".
+
+Mapping keys are given in the same way as the type of the key: addresses and fixed size bytes as hex, integers in
+decimal or hex, bools as true or false, strings quoted, and bytes as hex. The path of a string or bytes, or a dynamic
+array, locates the slot holding its length.
+*/
+func (document *SolidityStorageDocument) Locate(path string) (*StorageLocation, error) {
+	slot, offset, typeID, err := document.locate(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage path %q: %s", path, err.Error())
+	}
+	return &StorageLocation{Slot: slotHash(slot), Offset: offset, Type: typeID}, nil
+}
+
+// DecodeStoragePath decodes the value at the path, see Locate for the format of the path and DecodeStorage
+// for the format of the value
+func (document *SolidityStorageDocument) DecodeStoragePath(storage map[Hash]string, path string) (interface{}, error) {
+	slot, offset, typeID, err := document.locate(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage path %q: %s", path, err.Error())
+	}
+	reader, err := newStorageReader(document, storage, nil)
+	if err != nil {
+		return nil, err
+	}
+	return reader.decode(typeID, slot, offset)
+}
+
+//locate walks the path from the variable through each index and member
+func (document *SolidityStorageDocument) locate(path string) (*big.Int, uint64, string, error) {
+	name := storagePathIdentifier.FindString(path)
+	if name == "" {
+		return nil, 0, "", errors.New("expected a variable name")
+	}
+	var variable *SolidityStorageEntry
+	for i := range document.Storage {
+		if document.Storage[i].Label == name {
+			variable = &document.Storage[i]
+			break
+		}
+	}
+	if variable == nil {
+		return nil, 0, "", errors.New("unknown variable " + name)
+	}
+
+	slot, offset, typeID := new(big.Int).SetUint64(variable.Slot), variable.Offset, variable.Type
+	rest := path[len(name):]
+	for rest != "" {
+		typ, ok := document.Types[typeID]
+		if !ok {
+			return nil, 0, "", errors.New("unknown storage type: " + typeID)
+		}
+
+		switch rest[0] {
+		case '.':
+			member := storagePathIdentifier.FindString(rest[1:])
+			if member == "" {
+				return nil, 0, "", errors.New("expected a member name after .")
+			}
+			rest = rest[1+len(member):]
+
+			found := false
+			for _, entry := range typ.Members {
+				if entry.Label == member {
+					slot, offset, typeID = addSlot(slot, new(big.Int).SetUint64(entry.Slot)), entry.Offset, entry.Type
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, 0, "", fmt.Errorf("%s has no member %s", typ.Label, member)
+			}
+
+		case '[':
+			index, remaining, err := splitStorageIndex(rest)
+			if err != nil {
+				return nil, 0, "", err
+			}
+			rest = remaining
+
+			switch {
+			case typ.Encoding == "mapping":
+				key, err := document.encodeMappingKey(typ.Key, index)
+				if err != nil {
+					return nil, 0, "", fmt.Errorf("invalid key %s: %s", index, err.Error())
+				}
+				slot, offset, typeID = mappingSlot(key, slot), 0, typ.Value
+
+			case typ.Encoding == "dynamic_array" || typ.Base != "":
+				parsed, ok := parseIntegerString(index)
+				if !ok || !parsed.IsUint64() {
+					return nil, 0, "", fmt.Errorf("invalid index %s of %s", index, typ.Label)
+				}
+				i := parsed.Uint64()
+				if typ.Encoding == "dynamic_array" {
+					slot = dataSlot(slot)
+				} else if length, err := fixedLength(typeID, typ); err != nil || i >= length {
+					return nil, 0, "", fmt.Errorf("index %d is out of range for %s", i, typ.Label)
+				}
+				base, ok := document.Types[typ.Base]
+				if !ok {
+					return nil, 0, "", errors.New("unknown storage type: " + typ.Base)
+				}
+				slot, offset = elementLocation(base, slot, i)
+				typeID = typ.Base
+
+			default:
+				return nil, 0, "", errors.New(typ.Label + " can't be indexed")
+			}
+
+		default:
+			return nil, 0, "", fmt.Errorf("unexpected %q", rest)
+		}
+	}
+	return slot, offset, typeID, nil
+}
+
+//splitStorageIndex splits "[index]rest" into the index and the rest, where the index may be a quoted string
+func splitStorageIndex(path string) (string, string, error) {
+	if strings.HasPrefix(path, `["`) {
+		for end := 2; end < len(path); end++ {
+			if path[end] == '\\' {
+				end++
+			} else if path[end] == '"' {
+				if end+1 < len(path) && path[end+1] == ']' {
+					return path[1 : end+1], path[end+2:], nil
+				}
+				break
+			}
+		}
+		return "", "", errors.New("unterminated string key")
+	}
+
+	end := strings.IndexByte(path, ']')
+	if end < 0 {
+		return "", "", errors.New("missing ]")
+	}
+	if end == 1 {
+		return "", "", errors.New("missing index")
+	}
+	return path[1:end], path[end+1:], nil
+}
+
+//encodeMappingKey encodes the key of a mapping the way it is hashed with the slot of the mapping, which
+//is as a word for value types, and as it is for strings and bytes
+func (document *SolidityStorageDocument) encodeMappingKey(keyTypeID string, key string) ([]byte, error) {
+	keyType, ok := document.Types[keyTypeID]
+	if !ok {
+		return nil, errors.New("unknown storage type: " + keyTypeID)
+	}
+
+	switch {
+	case strings.HasPrefix(keyTypeID, "t_string"):
+		unquoted, err := strconv.Unquote(key)
+		if err != nil {
+			return nil, errors.New("expected a quoted string")
+		}
+		return []byte(unquoted), nil
+	case keyType.Encoding == "bytes":
+		return fromHex(key)
+	case keyTypeID == "t_bool":
+		value, err := strconv.ParseBool(key)
+		if err != nil {
+			return nil, errors.New("expected true or false")
+		}
+		if value {
+			return leftPad([]byte{1}), nil
+		}
+		return leftPad(nil), nil
+	case strings.HasPrefix(keyTypeID, "t_uint"), strings.HasPrefix(keyTypeID, "t_int"), strings.HasPrefix(keyTypeID, "t_enum"):
+		i, ok := parseIntegerString(key)
+		if !ok {
+			return nil, errors.New("expected an integer")
+		}
+		if !fitsInteger(i, strings.HasPrefix(keyTypeID, "t_int"), uint(keyType.NumberOfBytes*8)) {
+			return nil, errors.New("out of range for " + keyType.Label)
+		}
+		return encodeInt(i), nil
+	}
+
+	//addresses, contracts, fixed size bytes and user defined value types are given as their bytes, where
+	//a short address such as 0xabc may have an odd number of digits
+	digits := strings.TrimPrefix(key, "0x")
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	value, err := hex.DecodeString(digits)
+	if err != nil || uint64(len(value)) > keyType.NumberOfBytes {
+		return nil, fmt.Errorf("expected up to %d bytes of hex", keyType.NumberOfBytes)
+	}
+	if isFixedBytes(keyTypeID) {
+		padded := make([]byte, 32)
+		copy(padded, value)
+		return padded, nil
+	}
+	return leftPad(value), nil
+}
+
+//mappingSlot gives the slot of the entry of a mapping, which is keccak(key . slot)
+func mappingSlot(key []byte, slot *big.Int) *big.Int {
+	position, _ := hex.DecodeString(string(slotHash(slot)))
+	return new(big.Int).SetBytes(hash(string(append(append([]byte{}, key...), position...))))
+}
+
+//elementLocation gives where an element of an array is stored, given the slot the elements start at. Value types
+//are packed as many to a slot as fit, whilst other elements start a new slot each
+func elementLocation(base SolidityTypeEntry, slot *big.Int, i uint64) (*big.Int, uint64) {
+	if base.Encoding == "inplace" && len(base.Members) == 0 && base.Base == "" && base.NumberOfBytes <= 32 {
+		perSlot := 32 / base.NumberOfBytes
+		return addSlot(slot, new(big.Int).SetUint64(i/perSlot)), (i % perSlot) * base.NumberOfBytes
+	}
+	slotsPerElement := (base.NumberOfBytes + 31) / 32
+	return addSlot(slot, new(big.Int).Mul(new(big.Int).SetUint64(i), new(big.Int).SetUint64(slotsPerElement))), 0
+}
+
+func isFixedBytes(typeID string) bool {
+	return fixedBytesType.MatchString(typeID)
+}
+
+// MappingKeys is a set of candidate keys of mappings, such as the addresses and token ids passed to a contract.
+// The storage of a mapping only holds the hashes of its keys, so its entries can only be listed by decoding the
+// storage with the keys that might have been used, see DecodeStorageWithKeys
+type MappingKeys struct {
+	words map[Hash]bool
+}
+
+// NewMappingKeys creates an empty set of candidate keys
+func NewMappingKeys() *MappingKeys {
+	return &MappingKeys{words: make(map[Hash]bool)}
+}
+
+// Len gives the number of candidate keys
+func (keys *MappingKeys) Len() int {
+	return len(keys.words)
+}
+
+/*
+Add adds a candidate key, which can be an Address, a Hash, a *big.Int or other integer, a decimal string, or a
+[]byte or "0x" prefixed hex string of up to 32 bytes. Bytes shorter than a word are added as both an address or integer, which
+are padded on the left, and as fixed size bytes, which are padded on the right.
+
+Keys of type string or bytes are not hashed as a word, so can't be found.
+*/
+func (keys *MappingKeys) Add(value interface{}) {
+	switch v := value.(type) {
+	case Address:
+		keys.addWord(leftPad(fromHexOrNil(string(v))))
+		return
+	case Hash:
+		keys.addWord(leftPad(fromHexOrNil(string(v))))
+		return
+	case []byte:
+		keys.addBytes(v)
+		return
+	case string:
+		if strings.HasPrefix(v, "0x") {
+			keys.addBytes(fromHexOrNil(v))
+			return
+		}
+	}
+	if i, err := asBigInt(value); err == nil && fitsInteger(i, i.Sign() < 0, 256) {
+		keys.addWord(encodeInt(i))
+	}
+}
+
+// AddTransaction adds the sender and recipient of a transaction, and the addresses, integers and fixed size bytes
+// in its decoded input, as candidate keys
+func (keys *MappingKeys) AddTransaction(ptx *ParsedTransaction) {
+	if ptx.RawTransaction != nil {
+		for _, address := range []Address{ptx.RawTransaction.From, ptx.RawTransaction.To, ptx.RawTransaction.CreatedContract} {
+			if !address.IsEmpty() {
+				keys.Add(address)
+			}
+		}
+	}
+	keys.addValues(ptx.DecodedData)
+	for _, candidate := range ptx.Candidates {
+		keys.addValues(candidate.DecodedData)
+	}
+	for _, event := range ptx.ParsedEvents {
+		keys.AddEvent(event)
+	}
+}
+
+// AddEvent adds the addresses, integers and fixed size bytes in the decoded parameters of an event as candidate keys
+func (keys *MappingKeys) AddEvent(pe *ParsedEvent) {
+	keys.addValues(pe.DecodedData)
+	for _, candidate := range pe.Candidates {
+		keys.addValues(candidate.DecodedData)
+	}
+}
+
+func (keys *MappingKeys) addValues(values DecodedValues) {
+	for _, value := range values {
+		if len(value.Children) > 0 {
+			keys.addValues(value.Children)
+			continue
+		}
+
+		switch {
+		case value.Type == "address":
+			if address, ok := value.Value.(string); ok {
+				keys.Add(NewAddress(address))
+			}
+		case strings.HasPrefix(value.Type, "uint"), strings.HasPrefix(value.Type, "int"):
+			keys.Add(value.Value)
+		case strings.HasPrefix(value.Type, "bytes") && value.Type != "bytes":
+			var b []byte
+			switch v := value.Value.(type) {
+			case []byte:
+				b = v
+			case string:
+				b = fromHexOrNil(v)
+			}
+			if len(b) > 0 && len(b) <= 32 {
+				keys.addWord(rightPad(b))
+			}
+		}
+	}
+}
+
+func (keys *MappingKeys) addBytes(b []byte) {
+	if len(b) == 0 || len(b) > 32 {
+		return
+	}
+	keys.addWord(leftPad(b))
+	if len(b) < 32 {
+		keys.addWord(rightPad(b))
+	}
+}
+
+func (keys *MappingKeys) addWord(word []byte) {
+	keys.words[Hash(hex.EncodeToString(word))] = true
+}
+
+//sorted gives the keys in order, so that the entries of mappings are always listed in the same order
+func (keys *MappingKeys) sorted() []Hash {
+	sorted := make([]Hash, 0, len(keys.words))
+	for word := range keys.words {
+		sorted = append(sorted, word)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+func fromHexOrNil(hexString string) []byte {
+	b, err := fromHex(hexString)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+//decodeMapping decodes the entries of the mapping whose keys are one of the candidate keys
+func (reader *storageReader) decodeMapping(typ SolidityTypeEntry, slot *big.Int) ([]*StorageMappingEntry, error) {
+	keyType, err := reader.storageType(typ.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*StorageMappingEntry{}
+	for _, word := range reader.keys {
+		key, ok := mappingKeyValue(typ.Key, keyType, word)
+		if !ok {
+			continue
+		}
+		keyBytes, _ := hex.DecodeString(string(word))
+		entrySlot := mappingSlot(keyBytes, slot)
+		set, nested, err := reader.isSet(typ.Value, entrySlot)
+		if err != nil {
+			return nil, fmt.Errorf("[%v]: %s", key, err.Error())
+		}
+		if !set {
+			continue
+		}
+
+		//a nested mapping has already been decoded to see whether it is set
+		var value interface{} = nested
+		if nested == nil {
+			value, err = reader.decode(typ.Value, entrySlot, 0)
+			if err != nil {
+				return nil, fmt.Errorf("[%v]: %s", key, err.Error())
+			}
+		}
+		entries = append(entries, &StorageMappingEntry{Key: key, Value: value})
+	}
+	return entries, nil
+}
+
+//mappingKeyValue decodes a candidate key as the key type of a mapping, if it is a valid value of that type
+func mappingKeyValue(keyTypeID string, keyType SolidityTypeEntry, word Hash) (interface{}, bool) {
+	if keyType.Encoding != "inplace" || keyType.NumberOfBytes == 0 || keyType.NumberOfBytes > 32 {
+		return nil, false
+	}
+	b, _ := hex.DecodeString(string(word))
+	size := int(keyType.NumberOfBytes)
+
+	if isFixedBytes(keyTypeID) {
+		for _, padding := range b[size:] {
+			if padding != 0 {
+				return nil, false
+			}
+		}
+		return storageValue(keyTypeID, b[:size]), true
+	}
+
+	value := b[32-size:]
+	padding := byte(0)
+	if strings.HasPrefix(keyTypeID, "t_int") && value[0] >= 128 {
+		padding = 0xff
+	}
+	for _, p := range b[:32-size] {
+		if p != padding {
+			return nil, false
+		}
+	}
+	if keyTypeID == "t_bool" && value[0] > 1 {
+		return nil, false
+	}
+	return storageValue(keyTypeID, value), true
+}
+
+//isSet reports whether any of the slots of a value are set, so that only the entries of a mapping that
+//have been written to are listed. A nested mapping is set if any of its entries are, so its decoded entries are
+//also given, so that they don't need to be decoded again
+func (reader *storageReader) isSet(typeID string, slot *big.Int) (bool, []*StorageMappingEntry, error) {
+	typ, err := reader.storageType(typeID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if typ.Encoding == "mapping" {
+		entries, err := reader.decodeMapping(typ, slot)
+		return len(entries) > 0, entries, err
+	}
+
+	slots := uint64(1)
+	if typ.Encoding == "inplace" {
+		slots = (typ.NumberOfBytes + 31) / 32
+	}
+	if slots > uint64(len(reader.slots)) {
+		//a large fixed size array, so it is quicker to look through what is stored
+		end := addSlot(slot, new(big.Int).SetUint64(slots))
+		for key, word := range reader.slots {
+			position := new(big.Int).SetBytes(fromHexOrNil(string(key)))
+			if position.Cmp(slot) >= 0 && position.Cmp(end) < 0 && !isZero(word) {
+				return true, nil, nil
+			}
+		}
+		return false, nil, nil
+	}
+	for i := uint64(0); i < slots; i++ {
+		if !isZero(reader.word(addSlot(slot, new(big.Int).SetUint64(i)))) {
+			return true, nil, nil
+		}
+	}
+	return false, nil, nil
+}
+
+func isZero(word []byte) bool {
+	for _, b := range word {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
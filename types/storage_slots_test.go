@@ -0,0 +1,280 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+The storage layout output by solc for:
+
+	contract Token {
+		struct Account { uint256 amount; bool frozen; }
+
+		mapping(address => mapping(uint256 => Account)) balances;
+		mapping(string => uint256) names;
+		mapping(bytes4 => bool) interfaces;
+		Account[] accounts;
+		uint8[40] small;
+		mapping(int16 => address) byNumber;
+	}
+*/
+const mappingLayout = `{
+	"storage": [
+		{"label": "balances", "offset": 0, "slot": "0", "type": "t_mapping(t_address,t_mapping(t_uint256,t_struct(Account)5_storage))"},
+		{"label": "names", "offset": 0, "slot": "1", "type": "t_mapping(t_string_memory_ptr,t_uint256)"},
+		{"label": "interfaces", "offset": 0, "slot": "2", "type": "t_mapping(t_bytes4,t_bool)"},
+		{"label": "accounts", "offset": 0, "slot": "3", "type": "t_array(t_struct(Account)5_storage)dyn_storage"},
+		{"label": "small", "offset": 0, "slot": "4", "type": "t_array(t_uint8)40_storage"},
+		{"label": "byNumber", "offset": 0, "slot": "6", "type": "t_mapping(t_int16,t_address)"}
+	],
+	"types": {
+		"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+		"t_array(t_struct(Account)5_storage)dyn_storage": {"base": "t_struct(Account)5_storage", "encoding": "dynamic_array", "label": "struct Token.Account[]", "numberOfBytes": "32"},
+		"t_array(t_uint8)40_storage": {"base": "t_uint8", "encoding": "inplace", "label": "uint8[40]", "numberOfBytes": "64"},
+		"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+		"t_bytes4": {"encoding": "inplace", "label": "bytes4", "numberOfBytes": "4"},
+		"t_int16": {"encoding": "inplace", "label": "int16", "numberOfBytes": "2"},
+		"t_mapping(t_address,t_mapping(t_uint256,t_struct(Account)5_storage))": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => mapping(uint256 => struct Token.Account))", "numberOfBytes": "32", "value": "t_mapping(t_uint256,t_struct(Account)5_storage)"},
+		"t_mapping(t_bytes4,t_bool)": {"encoding": "mapping", "key": "t_bytes4", "label": "mapping(bytes4 => bool)", "numberOfBytes": "32", "value": "t_bool"},
+		"t_mapping(t_int16,t_address)": {"encoding": "mapping", "key": "t_int16", "label": "mapping(int16 => address)", "numberOfBytes": "32", "value": "t_address"},
+		"t_mapping(t_string_memory_ptr,t_uint256)": {"encoding": "mapping", "key": "t_string_memory_ptr", "label": "mapping(string => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+		"t_mapping(t_uint256,t_struct(Account)5_storage)": {"encoding": "mapping", "key": "t_uint256", "label": "mapping(uint256 => struct Token.Account)", "numberOfBytes": "32", "value": "t_struct(Account)5_storage"},
+		"t_string_memory_ptr": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+		"t_struct(Account)5_storage": {"encoding": "inplace", "label": "struct Token.Account", "numberOfBytes": "64", "members": [
+			{"label": "amount", "offset": 0, "slot": "0", "type": "t_uint256"},
+			{"label": "frozen", "offset": 0, "slot": "1", "type": "t_bool"}
+		]},
+		"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+		"t_uint8": {"encoding": "inplace", "label": "uint8", "numberOfBytes": "1"}
+	}
+}`
+
+func testMappingLayout(t *testing.T) *SolidityStorageDocument {
+	var document SolidityStorageDocument
+	assert.Nil(t, json.Unmarshal([]byte(mappingLayout), &document))
+	return &document
+}
+
+//keccakSlot hashes the hex, plus the number of slots to add to the hash
+func keccakSlot(hexString string, plus int64) Hash {
+	hashed := new(big.Int).SetBytes(hash(string(hexToBytes(hexString))))
+	return Hash(fmt.Sprintf("%064x", hashed.Add(hashed, big.NewInt(plus))))
+}
+
+func TestSolidityStorageDocument_Locate(t *testing.T) {
+	document := testMappingLayout(t)
+	abcBalances := string(keccakSlot(word("abc")+word("0"), 0))
+
+	testMatrix := []struct {
+		path     string
+		expected StorageLocation
+	}{
+		//keccak of 64 zero bytes
+		{"balances[0x0]", StorageLocation{Slot: "ad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5", Type: "t_mapping(t_uint256,t_struct(Account)5_storage)"}},
+		{"balances[0xabc][3].amount", StorageLocation{Slot: keccakSlot(word("3")+abcBalances, 0), Type: "t_uint256"}},
+		{"balances[0x0000000000000000000000000000000000000abc][0x3].frozen", StorageLocation{Slot: keccakSlot(word("3")+abcBalances, 1), Type: "t_bool"}},
+		{`names["alice"]`, StorageLocation{Slot: keccakSlot(hex.EncodeToString([]byte("alice"))+word("1"), 0), Type: "t_uint256"}},
+		{`names["a]\"b"]`, StorageLocation{Slot: keccakSlot(hex.EncodeToString([]byte(`a]"b`))+word("1"), 0), Type: "t_uint256"}},
+		{"interfaces[0x01ffc9a7]", StorageLocation{Slot: keccakSlot("01ffc9a7"+strings.Repeat("0", 56)+word("2"), 0), Type: "t_bool"}},
+		{"byNumber[-1]", StorageLocation{Slot: keccakSlot(strings.Repeat("f", 64)+word("6"), 0), Type: "t_address"}},
+		{"accounts", StorageLocation{Slot: NewHash("3"), Type: "t_array(t_struct(Account)5_storage)dyn_storage"}},
+		//each account takes 2 slots
+		{"accounts[2].frozen", StorageLocation{Slot: keccakSlot(word("3"), 5), Type: "t_bool"}},
+		{"small[33]", StorageLocation{Slot: NewHash("5"), Offset: 1, Type: "t_uint8"}},
+		//a leading zero is still decimal, not octal
+		{"small[010]", StorageLocation{Slot: NewHash("4"), Offset: 10, Type: "t_uint8"}},
+		{"byNumber[010]", StorageLocation{Slot: keccakSlot(word("a")+word("6"), 0), Type: "t_address"}},
+	}
+
+	for idx, test := range testMatrix {
+		location, err := document.Locate(test.path)
+
+		if assert.Nil(t, err, "Test index %d failed", idx) {
+			assert.Equal(t, test.expected, *location, "Test index %d failed", idx)
+		}
+	}
+}
+
+func TestSolidityStorageDocument_LocateErrors(t *testing.T) {
+	document := testMappingLayout(t)
+
+	testMatrix := []struct {
+		path          string
+		expectedError string
+	}{
+		{"", `invalid storage path "": expected a variable name`},
+		{"missing", `invalid storage path "missing": unknown variable missing`},
+		{"small[40]", `invalid storage path "small[40]": index 40 is out of range for uint8[40]`},
+		{"small[1][2]", `invalid storage path "small[1][2]": uint8 can't be indexed`},
+		{"small[1", `invalid storage path "small[1": missing ]`},
+		{"small[]", `invalid storage path "small[]": missing index`},
+		{"accounts[x]", `invalid storage path "accounts[x]": invalid index x of struct Token.Account[]`},
+		{"accounts[-1]", `invalid storage path "accounts[-1]": invalid index -1 of struct Token.Account[]`},
+		//integers are only decimal or "0x" prefixed hex
+		{"small[0b1]", `invalid storage path "small[0b1]": invalid index 0b1 of uint8[40]`},
+		{"small[0o7]", `invalid storage path "small[0o7]": invalid index 0o7 of uint8[40]`},
+		{"small[1_0]", `invalid storage path "small[1_0]": invalid index 1_0 of uint8[40]`},
+		{"byNumber[0b1]", `invalid storage path "byNumber[0b1]": invalid key 0b1: expected an integer`},
+		{"byNumber[1_0]", `invalid storage path "byNumber[1_0]": invalid key 1_0: expected an integer`},
+		{"accounts[0]x", `invalid storage path "accounts[0]x": unexpected "x"`},
+		{"accounts[0].owner", `invalid storage path "accounts[0].owner": struct Token.Account has no member owner`},
+		{"balances[0xzz]", `invalid storage path "balances[0xzz]": invalid key 0xzz: expected up to 20 bytes of hex`},
+		{"balances[0x1][-1]", `invalid storage path "balances[0x1][-1]": invalid key -1: out of range for uint256`},
+		{"byNumber[40000]", `invalid storage path "byNumber[40000]": invalid key 40000: out of range for int16`},
+		{"names[alice]", `invalid storage path "names[alice]": invalid key alice: expected a quoted string`},
+		{`names["alice]`, `invalid storage path "names[\"alice]": unterminated string key`},
+		{"interfaces[yes]", `invalid storage path "interfaces[yes]": invalid key yes: expected up to 4 bytes of hex`},
+	}
+
+	for idx, test := range testMatrix {
+		_, err := document.Locate(test.path)
+
+		assert.EqualError(t, err, test.expectedError, "Test index %d failed", idx)
+	}
+}
+
+//mappingStorage is the storage of the contract in mappingLayout
+func mappingStorage() map[Hash]string {
+	abcBalances := string(keccakSlot(word("abc")+word("0"), 0))
+	senderBalances := string(keccakSlot(word("1932c48b2bf8102ba33b4a6b545c32236e342f34")+word("0"), 0))
+
+	return map[Hash]string{
+		keccakSlot(word("3")+abcBalances, 0):                         "3e8",
+		keccakSlot(word("3")+abcBalances, 1):                         "1",
+		keccakSlot(word("7")+senderBalances, 0):                      "5",
+		keccakSlot("01ffc9a7"+strings.Repeat("0", 56)+word("2"), 0):  "1",
+		keccakSlot(strings.Repeat("f", 64)+word("6"), 0):             "9d13c6d3afe1721beef56b55d303b09e021e27ab",
+		keccakSlot(hex.EncodeToString([]byte("alice"))+word("1"), 0): "2a",
+		NewHash("5"): "0500",
+	}
+}
+
+func TestSolidityStorageDocument_DecodeStoragePath(t *testing.T) {
+	document := testMappingLayout(t)
+
+	testMatrix := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"balances[0xabc][3].amount", big.NewInt(1000)},
+		{"balances[0xabc][3].frozen", true},
+		{"balances[0xabc][3]", []*StorageItem{
+			{VarName: "amount", VarIndex: 0, VarType: "uint256", Value: big.NewInt(1000)},
+			{VarName: "frozen", VarIndex: 1, VarType: "bool", Value: true},
+		}},
+		{`names["alice"]`, big.NewInt(42)},
+		{"small[33]", big.NewInt(5)},
+		{"byNumber[-1]", "0x9d13c6d3afe1721beef56b55d303b09e021e27ab"},
+	}
+
+	for idx, test := range testMatrix {
+		value, err := document.DecodeStoragePath(mappingStorage(), test.path)
+
+		assert.Nil(t, err, "Test index %d failed", idx)
+		assert.Equal(t, test.expected, value, "Test index %d failed", idx)
+	}
+}
+
+func TestSolidityStorageDocument_DecodeStorageWithKeys(t *testing.T) {
+	document := testMappingLayout(t)
+
+	keys := NewMappingKeys()
+	keys.AddTransaction(&ParsedTransaction{
+		RawTransaction: &Transaction{From: NewAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34"), To: NewAddress("0x9d13c6d3afe1721beef56b55d303b09e021e27ab")},
+		DecodedData: DecodedValues{
+			{Name: "holder", Type: "address", Value: "0x0000000000000000000000000000000000000abc"},
+			{Name: "ids", Type: "uint256[]", Children: DecodedValues{{Type: "uint256", Value: big.NewInt(3)}, {Type: "uint256", Value: big.NewInt(7)}}},
+		},
+		ParsedEvents: []*ParsedEvent{{DecodedData: DecodedValues{
+			{Name: "number", Type: "int16", Value: big.NewInt(-1)},
+			{Name: "interfaceId", Type: "bytes4", Value: "0x01ffc9a7"},
+		}}},
+	})
+	assert.Equal(t, 7, keys.Len())
+
+	items, err := document.DecodeStorageWithKeys(mappingStorage(), keys)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*StorageMappingEntry{
+		{Key: "0x0000000000000000000000000000000000000abc", Value: []*StorageMappingEntry{
+			{Key: big.NewInt(3), Value: []*StorageItem{
+				{VarName: "amount", VarIndex: 0, VarType: "uint256", Value: big.NewInt(1000)},
+				{VarName: "frozen", VarIndex: 1, VarType: "bool", Value: true},
+			}},
+		}},
+		{Key: "0x1932c48b2bf8102ba33b4a6b545c32236e342f34", Value: []*StorageMappingEntry{
+			{Key: big.NewInt(7), Value: []*StorageItem{
+				{VarName: "amount", VarIndex: 0, VarType: "uint256", Value: big.NewInt(5)},
+				{VarName: "frozen", VarIndex: 1, VarType: "bool", Value: false},
+			}},
+		}},
+	}, items[0].Value)
+	//string keys aren't hashed as a word, so they can't be found
+	assert.Equal(t, []*StorageMappingEntry{}, items[1].Value)
+	assert.Equal(t, []*StorageMappingEntry{{Key: "0x01ffc9a7", Value: true}}, items[2].Value)
+	assert.Equal(t, []*StorageMappingEntry{{Key: big.NewInt(-1), Value: "0x9d13c6d3afe1721beef56b55d303b09e021e27ab"}}, items[5].Value)
+
+	//without keys the mappings aren't decoded
+	items, err = document.DecodeStorage(mappingStorage())
+	assert.Nil(t, err)
+	assert.Nil(t, items[0].Value)
+}
+
+func TestSolidityStorageDocument_DecodeStorageWithKeys_DeeplyNested(t *testing.T) {
+	//each level of nesting is only decoded once, so deeply nested mappings don't take exponentially long
+	const depth = 24
+	document := &SolidityStorageDocument{Types: map[string]SolidityTypeEntry{
+		"t_uint256": {Encoding: "inplace", Label: "uint256", NumberOfBytes: 32},
+	}}
+	typeID, slot := "t_uint256", new(big.Int)
+	for i := 0; i < depth; i++ {
+		valueID := typeID
+		typeID = "t_mapping(t_uint256," + valueID + ")"
+		document.Types[typeID] = SolidityTypeEntry{Encoding: "mapping", Key: "t_uint256", Value: valueID, NumberOfBytes: 32}
+		slot = mappingSlot(hexToBytes(word("1")), slot)
+	}
+	document.Storage = []SolidityStorageEntry{{Label: "nested", Type: typeID}}
+
+	keys := NewMappingKeys()
+	keys.Add(big.NewInt(1))
+	items, err := document.DecodeStorageWithKeys(map[Hash]string{slotHash(slot): "2a"}, keys)
+
+	assert.Nil(t, err)
+	value := items[0].Value
+	for i := 0; i < depth; i++ {
+		entries, ok := value.([]*StorageMappingEntry)
+		if !assert.True(t, ok, "level %d", i) || !assert.Len(t, entries, 1, "level %d", i) {
+			return
+		}
+		assert.Equal(t, big.NewInt(1), entries[0].Key, "level %d", i)
+		value = entries[0].Value
+	}
+	assert.Equal(t, big.NewInt(42), value)
+}
+
+func TestMappingKeys_Add(t *testing.T) {
+	keys := NewMappingKeys()
+
+	keys.Add(NewAddress("0xabc"))
+	keys.Add("0x0abc")
+	keys.Add(big.NewInt(2748))
+	keys.Add(uint64(2748))
+	keys.Add("2748")
+	keys.Add(NewHash("abc"))
+	keys.Add(big.NewInt(-1))
+	keys.Add(true)
+	keys.Add([]byte{})
+
+	assert.Equal(t, []Hash{
+		NewHash("abc"),
+		Hash("0abc" + strings.Repeat("0", 60)),
+		Hash(strings.Repeat("f", 64)),
+	}, keys.sorted())
+}
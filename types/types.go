@@ -31,15 +31,19 @@ type RawInnerCall struct {
 	To      Address
 	Input   HexData
 	From    Address
-	Value   HexNumber
+	Value   HexBig
 	Gas     HexNumber
 	GasUsed HexNumber
 	Output  HexData
 	Calls   []RawInnerCall
 }
 
+// received from debug_traceTransaction using the callTracer, the top level
+// call is the transaction itself
 type RawOuterCall struct {
-	Calls []RawInnerCall
+	Input  HexData
+	Output HexData
+	Calls  []RawInnerCall
 }
 
 type Block struct {
@@ -65,9 +69,9 @@ type Transaction struct {
 	Nonce             uint64          `json:"nonce"`
 	From              Address         `json:"from"`
 	To                Address         `json:"to"`
-	Value             uint64          `json:"value"`
+	Value             HexBig          `json:"value"`
 	Gas               uint64          `json:"gas"`
-	GasPrice          uint64          `json:"gasPrice"`
+	GasPrice          HexBig          `json:"gasPrice"`
 	GasUsed           uint64          `json:"gasUsed"`
 	CumulativeGasUsed uint64          `json:"cumulativeGasUsed"`
 	CreatedContract   Address         `json:"createdContract"`
@@ -77,6 +81,7 @@ type Transaction struct {
 	Timestamp         uint64          `json:"timestamp"`
 	Events            []*Event        `json:"events"`
 	InternalCalls     []*InternalCall `json:"internalCalls"`
+	Output            HexData         `json:"output,omitempty"` // return or revert data of the transaction, if it has been traced, see client.TraceTransactionOutput
 }
 
 type InternalCall struct {
@@ -84,7 +89,7 @@ type InternalCall struct {
 	To      Address `json:"to"`
 	Gas     uint64  `json:"gas"`
 	GasUsed uint64  `json:"gasUsed"`
-	Value   uint64  `json:"value"`
+	Value   HexBig  `json:"value"`
 	Input   HexData `json:"input"`
 	Output  HexData `json:"output"`
 	Type    string  `json:"type"`